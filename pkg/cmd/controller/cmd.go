@@ -42,7 +42,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	hcloudbackupbucket "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/backupbucket"
+	hcloudbackupentry "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/backupentry"
+	hcloudbastion "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/bastion"
 	hcloudcontrolplane "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/controlplane"
+	hclouddnsrecord "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/dnsrecord"
 	hcloudhealthcheck "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/healthcheck"
 	hcloudinfrastructure "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/infrastructure"
 	hcloudworker "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/worker"
@@ -73,6 +77,26 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 	}
 	reconcileOpts := &cmd.ReconcilerOptions{}
 
+	// options for the backup bucket controller
+	backupBucketCtrlOpts := &cmd.ControllerOptions{
+		MaxConcurrentReconciles: 5,
+	}
+
+	// options for the backup entry controller
+	backupEntryCtrlOpts := &cmd.ControllerOptions{
+		MaxConcurrentReconciles: 5,
+	}
+
+	// options for the bastion controller
+	bastionCtrlOpts := &cmd.ControllerOptions{
+		MaxConcurrentReconciles: 5,
+	}
+
+	// options for the DNS record controller
+	dnsRecordCtrlOpts := &cmd.ControllerOptions{
+		MaxConcurrentReconciles: 5,
+	}
+
 	// options for the health care controller
 	healthCareCtrlOpts := &cmd.ControllerOptions{
 		MaxConcurrentReconciles: 5,
@@ -114,7 +138,11 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 		generalOpts,
 		restOpts,
 		mgrOpts,
+		cmd.PrefixOption("backupbucket-", backupBucketCtrlOpts),
+		cmd.PrefixOption("backupentry-", backupEntryCtrlOpts),
+		cmd.PrefixOption("bastion-", bastionCtrlOpts),
 		cmd.PrefixOption("controlplane-", controlPlaneCtrlOpts),
+		cmd.PrefixOption("dnsrecord-", dnsRecordCtrlOpts),
 		cmd.PrefixOption("infrastructure-", infraCtrlOpts),
 		cmd.PrefixOption("worker-", workerCtrlOpts),
 		cmd.PrefixOption("healthcheck-", healthCareCtrlOpts),
@@ -182,13 +210,27 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 			}
 			log.Info("Adding controllers to manager")
 
+			configFileOpts.Completed().ApplyGardenId(&hcloudbastion.DefaultAddOptions.GardenId)
 			configFileOpts.Completed().ApplyGardenId(&hcloudcontrolplane.DefaultAddOptions.GardenId)
+			configFileOpts.Completed().ApplyGardenId(&hclouddnsrecord.DefaultAddOptions.GardenId)
 			configFileOpts.Completed().ApplyGardenId(&hcloudinfrastructure.DefaultAddOptions.GardenId)
+			configFileOpts.Completed().ApplyGardenId(&hcloudworker.DefaultAddOptions.GardenId)
+			configFileOpts.Completed().ApplyExtendedMachineClassFields(&hcloud.ExtendedMachineClassFields)
 			configFileOpts.Completed().ApplyHealthCheckConfig(&hcloudhealthcheck.DefaultAddOptions.HealthCheckConfig)
+			configFileOpts.Completed().ApplyInfrastructureDriftCheckConfig(&hcloudhealthcheck.InfrastructureDriftCheckConfig)
+			configFileOpts.Completed().ApplyRepairInfrastructureDrift(&hcloudhealthcheck.RepairInfrastructureDrift)
+			backupBucketCtrlOpts.Completed().Apply(&hcloudbackupbucket.DefaultAddOptions.Controller)
+			backupEntryCtrlOpts.Completed().Apply(&hcloudbackupentry.DefaultAddOptions.Controller)
+			bastionCtrlOpts.Completed().Apply(&hcloudbastion.DefaultAddOptions.Controller)
+			dnsRecordCtrlOpts.Completed().Apply(&hclouddnsrecord.DefaultAddOptions.Controller)
 			healthCareCtrlOpts.Completed().Apply(&hcloudhealthcheck.DefaultAddOptions.Controller)
 			heartbeatCtrlOpts.Completed().Apply(&heartbeat.DefaultAddOptions)
 			controlPlaneCtrlOpts.Completed().Apply(&hcloudcontrolplane.DefaultAddOptions.Controller)
 			infraCtrlOpts.Completed().Apply(&hcloudinfrastructure.DefaultAddOptions.Controller)
+			reconcileOpts.Completed().Apply(&hcloudbackupbucket.DefaultAddOptions.IgnoreOperationAnnotation, &hcloudbackupbucket.DefaultAddOptions.ExtensionClass)
+			reconcileOpts.Completed().Apply(&hcloudbackupentry.DefaultAddOptions.IgnoreOperationAnnotation, &hcloudbackupentry.DefaultAddOptions.ExtensionClass)
+			reconcileOpts.Completed().Apply(&hcloudbastion.DefaultAddOptions.IgnoreOperationAnnotation, &hcloudbastion.DefaultAddOptions.ExtensionClass)
+			reconcileOpts.Completed().Apply(&hclouddnsrecord.DefaultAddOptions.IgnoreOperationAnnotation, &hclouddnsrecord.DefaultAddOptions.ExtensionClass)
 			reconcileOpts.Completed().Apply(&hcloudinfrastructure.DefaultAddOptions.IgnoreOperationAnnotation, &hcloudinfrastructure.DefaultAddOptions.ExtensionClass)
 			reconcileOpts.Completed().Apply(&hcloudcontrolplane.DefaultAddOptions.IgnoreOperationAnnotation, &hcloudcontrolplane.DefaultAddOptions.ExtensionClass)
 			reconcileOpts.Completed().Apply(&hcloudworker.DefaultAddOptions.IgnoreOperationAnnotation, &hcloudworker.DefaultAddOptions.ExtensionClass)
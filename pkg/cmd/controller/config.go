@@ -97,6 +97,14 @@ func (c *Config) ApplyGardenId(gardenId *string) {
 	*gardenId = c.Config.GardenId
 }
 
+// ApplyExtendedMachineClassFields sets the extendedMachineClassFields switch.
+//
+// PARAMETERS
+// extendedMachineClassFields *bool Pointer to the extendedMachineClassFields switch to set
+func (c *Config) ApplyExtendedMachineClassFields(extendedMachineClassFields *bool) {
+	*extendedMachineClassFields = c.Config.ExtendedMachineClassFields
+}
+
 // ApplyHealthProbeBindAddress sets the healthProbeBindAddress.
 //
 // PARAMETERS
@@ -113,6 +121,14 @@ func (c *Config) ApplyMetricsBindAddress(metricsBindAddress *string) {
 	*metricsBindAddress = c.Config.MetricsBindAddress
 }
 
+// ApplyRepairInfrastructureDrift sets the repairInfrastructureDrift switch.
+//
+// PARAMETERS
+// repairInfrastructureDrift *bool Pointer to the repairInfrastructureDrift switch to set
+func (c *Config) ApplyRepairInfrastructureDrift(repairInfrastructureDrift *bool) {
+	*repairInfrastructureDrift = c.Config.RepairInfrastructureDrift
+}
+
 // Options initializes empty config.ControllerConfiguration, applies the set values and returns it.
 func (c *Config) Options() config.ControllerConfiguration {
 	var cfg config.ControllerConfiguration
@@ -129,3 +145,13 @@ func (c *Config) ApplyHealthCheckConfig(config *extensionconfig.HealthCheckConfi
 		*config = *c.Config.HealthCheckConfig
 	}
 }
+
+// ApplyInfrastructureDriftCheckConfig applies the InfrastructureDriftCheckConfig to the config
+//
+// PARAMETERS
+// config *healthcheckconfig.HealthCheckConfig Pointer to the HealthCheckConfig to set
+func (c *Config) ApplyInfrastructureDriftCheckConfig(config *extensionconfig.HealthCheckConfig) {
+	if c.Config.InfrastructureDriftCheckConfig != nil {
+		*config = *c.Config.InfrastructureDriftCheckConfig
+	}
+}
@@ -18,14 +18,22 @@ limitations under the License.
 package controller
 
 import (
+	"github.com/gardener/gardener/extensions/pkg/controller/backupbucket"
+	"github.com/gardener/gardener/extensions/pkg/controller/backupentry"
+	"github.com/gardener/gardener/extensions/pkg/controller/bastion"
 	"github.com/gardener/gardener/extensions/pkg/controller/cmd"
 	"github.com/gardener/gardener/extensions/pkg/controller/controlplane"
+	"github.com/gardener/gardener/extensions/pkg/controller/dnsrecord"
 	"github.com/gardener/gardener/extensions/pkg/controller/healthcheck"
 	extensionsheartbeatcontroller "github.com/gardener/gardener/extensions/pkg/controller/heartbeat"
 	"github.com/gardener/gardener/extensions/pkg/controller/infrastructure"
 	"github.com/gardener/gardener/extensions/pkg/controller/worker"
 
+	hcloudbackupbucket "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/backupbucket"
+	hcloudbackupentry "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/backupentry"
+	hcloudbastion "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/bastion"
 	hcloudcontrolplane "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/controlplane"
+	hclouddnsrecord "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/dnsrecord"
 	hcloudhealthcheck "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/healthcheck"
 	hcloudinfrastructure "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/infrastructure"
 	hcloudworker "github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/worker"
@@ -34,7 +42,11 @@ import (
 // controllerSwitchOptions are the cmd.SwitchOptions for the provider controllers.
 func controllerSwitchOptions() *cmd.SwitchOptions {
 	return cmd.NewSwitchOptions(
+		cmd.Switch(backupbucket.ControllerName, hcloudbackupbucket.AddToManager),
+		cmd.Switch(backupentry.ControllerName, hcloudbackupentry.AddToManager),
+		cmd.Switch(bastion.ControllerName, hcloudbastion.AddToManager),
 		cmd.Switch(controlplane.ControllerName, hcloudcontrolplane.AddToManager),
+		cmd.Switch(dnsrecord.ControllerName, hclouddnsrecord.AddToManager),
 		cmd.Switch(infrastructure.ControllerName, hcloudinfrastructure.AddToManager),
 		cmd.Switch(worker.ControllerName, hcloudworker.AddToManager),
 		cmd.Switch(healthcheck.ControllerName, hcloudhealthcheck.AddToManager),
@@ -22,11 +22,13 @@ import (
 	webhook "github.com/gardener/gardener/extensions/pkg/webhook/controlplane"
 
 	hcloudwebhook "github.com/23technologies/gardener-extension-provider-hcloud/pkg/webhook/controlplane"
+	hcloudbackupwebhook "github.com/23technologies/gardener-extension-provider-hcloud/pkg/webhook/controlplanebackup"
 )
 
 // webhookSwitchOptions are the webhookcmd.SwitchOptions for the provider webhooks.
 func webhookSwitchOptions() *webhookcmd.SwitchOptions {
 	return webhookcmd.NewSwitchOptions(
 		webhookcmd.Switch(webhook.WebhookName, hcloudwebhook.AddToManager),
+		webhookcmd.Switch(webhook.BackupWebhookName, hcloudbackupwebhook.AddToManager),
 	)
 }
@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backupbucket contains functions used at the backup bucket controller
+package backupbucket
+
+import (
+	"context"
+	"fmt"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/backupbucket"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/controllerutils"
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/objectstorage"
+)
+
+type actuator struct {
+	client client.Client
+}
+
+// NewActuator creates a new Actuator that manages the HCloud Object Storage buckets of the handled BackupBucket
+// resources.
+func NewActuator(mgr manager.Manager) backupbucket.Actuator {
+	return &actuator{
+		client: mgr.GetClient(),
+	}
+}
+
+// getCredentials returns the Object Storage credentials referenced by the given backup bucket resource.
+//
+// PARAMETERS
+// ctx context.Context                  Execution context
+// bb  *extensionsv1alpha1.BackupBucket BackupBucket struct
+func (a *actuator) getCredentials(ctx context.Context, bb *extensionsv1alpha1.BackupBucket) (*objectstorage.Credentials, error) {
+	secret, err := extensionscontroller.GetSecretByReference(ctx, a.client, &bb.Spec.SecretRef)
+	if nil != err {
+		return nil, err
+	}
+
+	return objectstorage.ExtractCredentials(secret, bb.Spec.Region)
+}
+
+// Reconcile reconciles the backup bucket and the generated secret used by backup entries.
+//
+// PARAMETERS
+// ctx context.Context                  Execution context
+// log logr.Logger                      Logger
+// bb  *extensionsv1alpha1.BackupBucket BackupBucket struct
+func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, bb *extensionsv1alpha1.BackupBucket) error {
+	credentials, err := a.getCredentials(ctx, bb)
+	if nil != err {
+		return err
+	}
+
+	s3Client, err := objectstorage.NewClient(credentials)
+	if nil != err {
+		return err
+	}
+
+	err = objectstorage.EnsureBucket(ctx, s3Client, bb.Name, credentials.Region)
+	if nil != err {
+		return fmt.Errorf("Failed to ensure Object Storage bucket %q: %w", bb.Name, err)
+	}
+
+	err = objectstorage.EnsureExpiredEntryRulesDeleted(ctx, s3Client, bb.Name)
+	if nil != err {
+		return fmt.Errorf("Failed to clean up lifecycle rules of Object Storage bucket %q: %w", bb.Name, err)
+	}
+
+	log.Info("Object Storage bucket is ready", "bucket", bb.Name)
+
+	return a.ensureGeneratedSecret(ctx, bb, credentials)
+}
+
+// Delete deletes the backup bucket including all remaining objects and the generated secret.
+//
+// PARAMETERS
+// ctx context.Context                  Execution context
+// log logr.Logger                      Logger
+// bb  *extensionsv1alpha1.BackupBucket BackupBucket struct
+func (a *actuator) Delete(ctx context.Context, log logr.Logger, bb *extensionsv1alpha1.BackupBucket) error {
+	credentials, err := a.getCredentials(ctx, bb)
+	if nil != err {
+		return err
+	}
+
+	s3Client, err := objectstorage.NewClient(credentials)
+	if nil != err {
+		return err
+	}
+
+	err = objectstorage.EnsureBucketDeleted(ctx, s3Client, bb.Name)
+	if nil != err && minio.ToErrorResponse(err).Code != "NoSuchBucket" {
+		return fmt.Errorf("Failed to delete Object Storage bucket %q: %w", bb.Name, err)
+	}
+
+	log.Info("Object Storage bucket has been deleted", "bucket", bb.Name)
+
+	return client.IgnoreNotFound(a.client.Delete(ctx, newGeneratedSecret(bb)))
+}
+
+// ensureGeneratedSecret creates or updates the secret referenced by backup entries of the given backup bucket.
+//
+// PARAMETERS
+// ctx         context.Context                  Execution context
+// bb          *extensionsv1alpha1.BackupBucket BackupBucket struct
+// credentials *objectstorage.Credentials       Object Storage credentials
+func (a *actuator) ensureGeneratedSecret(ctx context.Context, bb *extensionsv1alpha1.BackupBucket, credentials *objectstorage.Credentials) error {
+	secret := newGeneratedSecret(bb)
+
+	_, err := controllerutils.GetAndCreateOrMergePatch(ctx, a.client, secret, func() error {
+		secret.Data = credentials.SecretData()
+		return nil
+	})
+	if nil != err {
+		return err
+	}
+
+	secretRef := &corev1.SecretReference{Name: secret.Name, Namespace: secret.Namespace}
+
+	if nil != bb.Status.GeneratedSecretRef && *bb.Status.GeneratedSecretRef == *secretRef {
+		return nil
+	}
+
+	patch := client.MergeFrom(bb.DeepCopy())
+	bb.Status.GeneratedSecretRef = secretRef
+
+	return a.client.Status().Patch(ctx, bb, patch)
+}
+
+// newGeneratedSecret returns the secret object generated for the given backup bucket.
+//
+// PARAMETERS
+// bb *extensionsv1alpha1.BackupBucket BackupBucket struct
+func newGeneratedSecret(bb *extensionsv1alpha1.BackupBucket) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      v1beta1constants.SecretPrefixGeneratedBackupBucket + bb.Name,
+			Namespace: v1beta1constants.GardenNamespace,
+		},
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backupentry contains functions used at the backup entry controller
+package backupentry
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/backupentry/genericactuator"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/objectstorage"
+)
+
+type actuator struct {
+	client client.Client
+}
+
+// NewActuator creates a new backup entry delegate expiring the HCloud Object Storage objects of deleted BackupEntry
+// resources.
+func NewActuator(mgr manager.Manager) genericactuator.BackupEntryDelegate {
+	return &actuator{
+		client: mgr.GetClient(),
+	}
+}
+
+// GetETCDSecretData returns the etcd-backup-restore secret data including the Object Storage endpoint and region.
+//
+// PARAMETERS
+// ctx        context.Context                 Execution context
+// log        logr.Logger                     Logger
+// be         *extensionsv1alpha1.BackupEntry BackupEntry struct
+// backupData map[string][]byte               Backup secret data
+func (a *actuator) GetETCDSecretData(_ context.Context, _ logr.Logger, be *extensionsv1alpha1.BackupEntry, backupData map[string][]byte) (map[string][]byte, error) {
+	return getETCDSecretData(be, backupData)
+}
+
+// Delete ensures that the Object Storage objects of the given backup entry expire.
+//
+// PARAMETERS
+// ctx context.Context                 Execution context
+// log logr.Logger                     Logger
+// be  *extensionsv1alpha1.BackupEntry BackupEntry struct
+func (a *actuator) Delete(ctx context.Context, log logr.Logger, be *extensionsv1alpha1.BackupEntry) error {
+	secret, err := extensionscontroller.GetSecretByReference(ctx, a.client, &be.Spec.SecretRef)
+	if nil != err {
+		return err
+	}
+
+	credentials, err := objectstorage.ExtractCredentials(secret, be.Spec.Region)
+	if nil != err {
+		return err
+	}
+
+	s3Client, err := objectstorage.NewClient(credentials)
+	if nil != err {
+		return err
+	}
+
+	err = objectstorage.EnsureEntryExpiration(ctx, s3Client, be.Spec.BucketName, be.Name)
+	if nil != err {
+		return fmt.Errorf("Failed to ensure expiration of backup entry %q: %w", be.Name, err)
+	}
+
+	log.Info("Object Storage objects of backup entry expire", "bucket", be.Spec.BucketName)
+
+	return nil
+}
+
+// getETCDSecretData returns the given backup secret data completed with Object Storage defaults.
+//
+// PARAMETERS
+// be         *extensionsv1alpha1.BackupEntry BackupEntry struct
+// backupData map[string][]byte               Backup secret data
+func getETCDSecretData(be *extensionsv1alpha1.BackupEntry, backupData map[string][]byte) (map[string][]byte, error) {
+	credentials, err := objectstorage.ExtractCredentials(&corev1.Secret{Data: backupData}, be.Spec.Region)
+	if nil != err {
+		return nil, err
+	}
+
+	etcdData := maps.Clone(backupData)
+	maps.Copy(etcdData, credentials.SecretData())
+
+	return etcdData, nil
+}
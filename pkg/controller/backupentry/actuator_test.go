@@ -0,0 +1,56 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package backupentry contains functions used at the backup entry controller
+package backupentry
+
+import (
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Actuator", func() {
+	Describe("#getETCDSecretData", func() {
+		be := &extensionsv1alpha1.BackupEntry{
+			Spec: extensionsv1alpha1.BackupEntrySpec{
+				BucketName: "bucket",
+				Region:     "fsn1",
+			},
+		}
+
+		It("should add Object Storage defaults", func() {
+			data, err := getETCDSecretData(be, map[string][]byte{
+				"accessKeyID":     []byte("id"),
+				"secretAccessKey": []byte("secret"),
+				"bucketName":      []byte("bucket"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal(map[string][]byte{
+				"accessKeyID":      []byte("id"),
+				"secretAccessKey":  []byte("secret"),
+				"bucketName":       []byte("bucket"),
+				"region":           []byte("fsn1"),
+				"endpoint":         []byte("https://fsn1.your-objectstorage.com"),
+				"s3ForcePathStyle": []byte("true"),
+			}))
+		})
+
+		It("should fail for incomplete backup secrets", func() {
+			_, err := getETCDSecretData(be, map[string][]byte{"bucketName": []byte("bucket")})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bastion contains functions used at the bastion controller
+package bastion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/bastion"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	hcloudclient "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
+)
+
+// maxBastionNameLength is the maximum length of HCloud resource names used for bastions.
+const maxBastionNameLength = 63
+
+type actuator struct {
+	client   client.Client
+	recorder record.EventRecorder
+	gardenID string
+}
+
+// NewActuator creates a new Actuator that manages the HCloud resources of the handled Bastion resources.
+func NewActuator(mgr manager.Manager, gardenID string) bastion.Actuator {
+	return &actuator{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(hcloud.Name + "-bastion-controller"),
+		gardenID: gardenID,
+	}
+}
+
+// getResourceOwner returns the owner of the HCloud resources of the given bastion adding the labels of the
+// InfrastructureConfig.
+//
+// PARAMETERS
+// bastion *extensionsv1alpha1.Bastion   Bastion struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) getResourceOwner(bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) *controller.ResourceOwner {
+	owner := controller.NewResourceOwner(string(cluster.Shoot.GetUID()), a.gardenID, a.recorder, bastion)
+
+	infraConfig, err := transcoder.DecodeInfrastructureConfigFromCluster(cluster)
+	if nil == err {
+		owner = owner.WithLabels(infraConfig.Labels)
+	}
+
+	return owner
+}
+
+// getClient returns the HCloud client for the cloud provider credentials of the bastion namespace.
+//
+// PARAMETERS
+// ctx     context.Context            Execution context
+// bastion *extensionsv1alpha1.Bastion Bastion struct
+func (a *actuator) getClient(ctx context.Context, bastion *extensionsv1alpha1.Bastion) (*hcloudclient.Client, error) {
+	secret, err := extensionscontroller.GetSecretByReference(ctx, a.client, &corev1.SecretReference{
+		Name:      v1beta1constants.SecretNameCloudProvider,
+		Namespace: bastion.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := hcloud.ExtractCredentials(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return apis.GetClientForToken(string(credentials.CCM().Token)), nil
+}
+
+// ForceDelete implements bastion.Actuator.ForceDelete
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// log     logr.Logger                   Logger
+// bastion *extensionsv1alpha1.Bastion   Bastion struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) ForceDelete(ctx context.Context, log logr.Logger, bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) error {
+	return a.Delete(ctx, log, bastion, cluster)
+}
+
+// Delete implements bastion.Actuator.Delete
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// bastion *extensionsv1alpha1.Bastion   Bastion struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) Delete(ctx context.Context, _ logr.Logger, bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) error {
+	return a.delete(ctx, bastion, cluster)
+}
+
+// Reconcile implements bastion.Actuator.Reconcile
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// bastion *extensionsv1alpha1.Bastion   Bastion struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) Reconcile(ctx context.Context, _ logr.Logger, bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) error {
+	return a.reconcile(ctx, bastion, cluster)
+}
+
+// getBastionName returns the name of the HCloud resources created for the given bastion. Names exceeding the
+// HCloud limit are shortened and suffixed with a hash to stay unique.
+//
+// PARAMETERS
+// bastion *extensionsv1alpha1.Bastion Bastion struct
+func getBastionName(bastion *extensionsv1alpha1.Bastion) string {
+	name := fmt.Sprintf("%s-%s-bastion", bastion.Namespace, bastion.Name)
+
+	if len(name) <= maxBastionNameLength {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(hash[:])[:8]
+
+	return fmt.Sprintf("%s-%s", name[:maxBastionNameLength-len(suffix)-1], suffix)
+}
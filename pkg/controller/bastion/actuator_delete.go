@@ -0,0 +1,42 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bastion contains functions used at the bastion controller
+package bastion
+
+import (
+	"context"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/bastion/ensurer"
+)
+
+// delete removes the HCloud resources of the given bastion.
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// bastion *extensionsv1alpha1.Bastion   Bastion struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) delete(ctx context.Context, bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) error {
+	client, err := a.getClient(ctx, bastion)
+	if err != nil {
+		return err
+	}
+
+	return ensurer.EnsureBastionDeleted(ctx, client, a.getResourceOwner(bastion, cluster), getBastionName(bastion))
+}
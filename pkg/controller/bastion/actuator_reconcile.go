@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bastion contains functions used at the bastion controller
+package bastion
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	extensionsbastion "github.com/gardener/gardener/extensions/pkg/bastion"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/worker"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	hcloudclient "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/bastion/ensurer"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
+)
+
+// reconcile creates or updates the HCloud server and firewall of the given bastion.
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// bastion *extensionsv1alpha1.Bastion   Bastion struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) reconcile(ctx context.Context, bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) error {
+	hcloudClient, err := a.getClient(ctx, bastion)
+	if err != nil {
+		return err
+	}
+
+	machineSpec, err := extensionsbastion.GetMachineSpecFromCloudProfile(cluster.CloudProfile)
+	if err != nil {
+		return err
+	}
+
+	imageName, err := getBastionImageName(ctx, hcloudClient, cluster, machineSpec.ImageBaseName, machineSpec.ImageVersion)
+	if err != nil {
+		return err
+	}
+
+	owner := a.getResourceOwner(bastion, cluster)
+	name := getBastionName(bastion)
+
+	firewall, err := ensurer.EnsureBastionFirewall(ctx, hcloudClient, owner, name, bastion.Spec.Ingress)
+	if err != nil {
+		return err
+	}
+
+	location := cluster.Shoot.Spec.Region
+
+	// Bastions are created in the location of the control plane zone if the region spans multiple locations.
+	if apis.IsNetworkZoneRegion(location) {
+		cpConfig, err := transcoder.DecodeControlPlaneConfigFromControllerCluster(cluster)
+		if err != nil {
+			return err
+		}
+
+		location = apis.GetLocation(location, cpConfig.Zone)
+	}
+
+	network, err := a.getWorkersNetwork(ctx, hcloudClient, bastion, cluster)
+	if err != nil {
+		return err
+	}
+
+	server, err := ensurer.EnsureBastionServer(ctx, hcloudClient, owner, name, location, machineSpec.MachineTypeName, imageName, bastion.Spec.UserData, firewall, network)
+	if err != nil {
+		return err
+	}
+
+	if server.PublicNet.IPv4.IsUnspecified() {
+		return fmt.Errorf("Bastion server %q (%d) has no public IPv4 address", server.Name, server.ID)
+	}
+
+	return a.updateStatus(ctx, bastion, server.PublicNet.IPv4.IP.String())
+}
+
+// getWorkersNetwork returns the workers network of the shoot given or nil if the infrastructure has not been
+// reconciled yet.
+//
+// PARAMETERS
+// ctx          context.Context               Execution context
+// hcloudClient *hcloudclient.Client          HCloud client
+// bastion      *extensionsv1alpha1.Bastion   Bastion struct
+// cluster      *extensionscontroller.Cluster Cluster struct
+func (a *actuator) getWorkersNetwork(ctx context.Context, hcloudClient *hcloudclient.Client, bastion *extensionsv1alpha1.Bastion, cluster *extensionscontroller.Cluster) (*hcloudclient.Network, error) {
+	infra := &extensionsv1alpha1.Infrastructure{}
+
+	err := a.client.Get(ctx, client.ObjectKey{Namespace: bastion.Namespace, Name: cluster.Shoot.Name}, infra)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	infraStatus, err := transcoder.DecodeInfrastructureStatusFromInfrastructure(infra)
+	if err != nil {
+		return nil, err
+	}
+
+	if nil == infraStatus.NetworkIDs || "" == infraStatus.NetworkIDs.Workers {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(infraStatus.NetworkIDs.Workers, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	network, _, err := hcloudClient.Network.GetByID(ctx, id)
+
+	return network, err
+}
+
+// updateStatus publishes the public IP of the bastion server in the bastion status.
+//
+// PARAMETERS
+// ctx     context.Context            Execution context
+// bastion *extensionsv1alpha1.Bastion Bastion struct
+// ip      string                     Public IP of the bastion server
+func (a *actuator) updateStatus(ctx context.Context, bastion *extensionsv1alpha1.Bastion, ip string) error {
+	if nil != bastion.Status.Ingress && bastion.Status.Ingress.IP == ip {
+		return nil
+	}
+
+	patch := client.MergeFrom(bastion.DeepCopy())
+
+	bastion.Status.Ingress = &corev1.LoadBalancerIngress{
+		IP: ip,
+	}
+
+	return a.client.Status().Patch(ctx, bastion, patch)
+}
+
+// getBastionImageName returns the HCloud image name for the given machine image name and version.
+//
+// PARAMETERS
+// ctx          context.Context               Execution context
+// hcloudClient *hcloudclient.Client          HCloud client
+// cluster      *extensionscontroller.Cluster Cluster struct
+// name         string                        Machine image name
+// version      string                        Machine image version
+func getBastionImageName(ctx context.Context, hcloudClient *hcloudclient.Client, cluster *extensionscontroller.Cluster, name, version string) (string, error) {
+	cloudProfileConfig, err := transcoder.DecodeCloudProfileConfigFromControllerCluster(cluster)
+	if err == nil {
+		imageName, err := transcoder.DecodeMachineImageNameFromCloudProfile(cloudProfileConfig, name, version)
+		if err == nil {
+			return imageName, nil
+		}
+	}
+
+	opts := hcloudclient.ImageListOpts{
+		Type:   []hcloudclient.ImageType{"system"},
+		Status: []hcloudclient.ImageStatus{"available"},
+	}
+
+	images, _, err := hcloudClient.Image.List(ctx, opts)
+	if nil != err {
+		return "", err
+	}
+
+	for _, image := range images {
+		if image.OSFlavor != name || image.OSVersion != version {
+			continue
+		}
+
+		return image.Name, nil
+	}
+
+	return "", worker.ErrorMachineImageNotFound(name, version)
+}
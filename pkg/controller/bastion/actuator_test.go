@@ -0,0 +1,53 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bastion contains functions used at the bastion controller
+package bastion
+
+import (
+	"strings"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Actuator", func() {
+	Describe("#getBastionName", func() {
+		It("should return the name of the bastion resources", func() {
+			bastion := &extensionsv1alpha1.Bastion{
+				ObjectMeta: metav1.ObjectMeta{Name: "cli-abcdef", Namespace: "shoot--foobar--hcloud"},
+			}
+
+			Expect(getBastionName(bastion)).To(Equal("shoot--foobar--hcloud-cli-abcdef-bastion"))
+		})
+
+		It("should shorten names exceeding the HCloud limit", func() {
+			bastion := &extensionsv1alpha1.Bastion{
+				ObjectMeta: metav1.ObjectMeta{Name: "cli-" + strings.Repeat("a", 40), Namespace: "shoot--foobar--hcloud"},
+			}
+
+			name := getBastionName(bastion)
+
+			Expect(name).To(HaveLen(maxBastionNameLength))
+			Expect(name).To(HavePrefix("shoot--foobar--hcloud-cli-"))
+			Expect(name).NotTo(Equal(getBastionName(&extensionsv1alpha1.Bastion{
+				ObjectMeta: metav1.ObjectMeta{Name: "cli-" + strings.Repeat("a", 41), Namespace: "shoot--foobar--hcloud"},
+			})))
+		})
+	})
+})
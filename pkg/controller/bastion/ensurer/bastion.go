@@ -0,0 +1,267 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure bastion changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+const (
+	// bastionFirewallRole is the role label value of bastion firewalls created.
+	bastionFirewallRole = "bastion-firewall-v1"
+	// bastionServerRole is the role label value of bastion servers created.
+	bastionServerRole = "bastion-server-v1"
+	// sshPort is the port allowed to be accessed by the bastion ingress CIDRs.
+	sshPort = "22"
+)
+
+// EnsureBastionFirewall verifies that the firewall only allowing SSH access from the ingress CIDRs is available.
+//
+// PARAMETERS
+// ctx     context.Context                            Execution context
+// client  *hcloud.Client                             HCloud client
+// owner   *controller.ResourceOwner                  Resource owner
+// name    string                                     Bastion resource name
+// ingress []extensionsv1alpha1.BastionIngressPolicy Bastion ingress policies
+func EnsureBastionFirewall(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, name string, ingress []extensionsv1alpha1.BastionIngressPolicy) (*hcloud.Firewall, error) {
+	rules, err := getBastionFirewallRules(ingress)
+	if nil != err {
+		return nil, err
+	}
+
+	firewall, _, err := client.Firewall.GetByName(ctx, name)
+	if nil != err {
+		return nil, err
+	} else if firewall != nil && !owner.IsOwnerOf(firewall.Labels, bastionFirewallRole) {
+		return nil, fmt.Errorf("Firewall %q (%d) is not owned by this shoot", firewall.Name, firewall.ID)
+	}
+
+	if firewall == nil {
+		opts := hcloud.FirewallCreateOpts{
+			Name:   name,
+			Labels: owner.Labels(bastionFirewallRole),
+			Rules:  rules,
+		}
+
+		result, _, err := client.Firewall.Create(ctx, opts)
+		if nil != err {
+			return nil, err
+		}
+
+		return result.Firewall, nil
+	}
+
+	actions, _, err := client.Firewall.SetRules(ctx, firewall, hcloud.FirewallSetRulesOpts{Rules: rules})
+	if nil != err {
+		return nil, err
+	}
+
+	err = client.Action.WaitFor(ctx, actions...)
+	if nil != err {
+		return nil, err
+	}
+
+	return firewall, nil
+}
+
+// EnsureBastionServer verifies that the bastion server is available, protected by the given firewall and attached to
+// the workers network if given. HCloud firewalls do not filter private networks, the bastion reaches all worker nodes
+// by their private IPs.
+//
+// PARAMETERS
+// ctx        context.Context           Execution context
+// client     *hcloud.Client            HCloud client
+// owner      *controller.ResourceOwner Resource owner
+// name       string                    Bastion resource name
+// location   string                    HCloud location name
+// serverType string                    HCloud server type name
+// imageName  string                    HCloud image name
+// userData   []byte                    Bastion user data
+// firewall   *hcloud.Firewall          Bastion firewall
+// network    *hcloud.Network           Workers network of the shoot if known
+func EnsureBastionServer(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, name, location, serverType, imageName string, userData []byte, firewall *hcloud.Firewall, network *hcloud.Network) (*hcloud.Server, error) {
+	server, _, err := client.Server.GetByName(ctx, name)
+	if nil != err {
+		return nil, err
+	} else if server != nil && !owner.IsOwnerOf(server.Labels, bastionServerRole) {
+		return nil, fmt.Errorf("Server %q (%d) is not owned by this shoot", server.Name, server.ID)
+	}
+
+	if server == nil {
+		opts := hcloud.ServerCreateOpts{
+			Name:       name,
+			ServerType: &hcloud.ServerType{Name: serverType},
+			Image:      &hcloud.Image{Name: imageName},
+			Location:   &hcloud.Location{Name: location},
+			UserData:   string(userData),
+			Labels:     owner.Labels(bastionServerRole),
+			Firewalls:  []*hcloud.ServerCreateFirewall{{Firewall: *firewall}},
+			PublicNet: &hcloud.ServerCreatePublicNet{
+				EnableIPv4: true,
+				EnableIPv6: true,
+			},
+		}
+
+		if nil != network {
+			opts.Networks = []*hcloud.Network{network}
+		}
+
+		result, _, err := client.Server.Create(ctx, opts)
+		if nil != err {
+			return nil, err
+		}
+
+		err = client.Action.WaitFor(ctx, append([]*hcloud.Action{result.Action}, result.NextActions...)...)
+		if nil != err {
+			return nil, err
+		}
+
+		server, _, err = client.Server.GetByID(ctx, result.Server.ID)
+		if nil != err {
+			return nil, err
+		} else if server == nil {
+			return nil, fmt.Errorf("Failed to find bastion server with ID %d", result.Server.ID)
+		}
+
+		return server, nil
+	}
+
+	if nil != network && nil == server.PrivateNetFor(network) {
+		action, _, err := client.Server.AttachToNetwork(ctx, server, hcloud.ServerAttachToNetworkOpts{Network: network})
+		if nil != err {
+			return nil, err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	for _, serverFirewall := range server.PublicNet.Firewalls {
+		if nil != serverFirewall && serverFirewall.Firewall.ID == firewall.ID {
+			return server, nil
+		}
+	}
+
+	resource := hcloud.FirewallResource{
+		Type:   hcloud.FirewallResourceTypeServer,
+		Server: &hcloud.FirewallResourceServer{ID: server.ID},
+	}
+
+	actions, _, err := client.Firewall.ApplyResources(ctx, firewall, []hcloud.FirewallResource{resource})
+	if nil != err {
+		return nil, err
+	}
+
+	err = client.Action.WaitFor(ctx, actions...)
+	if nil != err {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// EnsureBastionDeleted removes the bastion server and firewall identified by the given name.
+//
+// PARAMETERS
+// ctx    context.Context           Execution context
+// client *hcloud.Client            HCloud client
+// owner  *controller.ResourceOwner Resource owner
+// name   string                    Bastion resource name
+func EnsureBastionDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, name string) error {
+	server, _, err := client.Server.GetByName(ctx, name)
+	if nil != err {
+		return err
+	} else if server != nil {
+		if !owner.IsOwnerOf(server.Labels, bastionServerRole) {
+			owner.RefuseDeletion("server", server.Name, server.ID)
+		} else {
+			result, _, err := client.Server.DeleteWithResult(ctx, server)
+			if nil != err {
+				return err
+			}
+
+			err = client.Action.WaitFor(ctx, result.Action)
+			if nil != err {
+				return err
+			}
+		}
+	}
+
+	firewall, _, err := client.Firewall.GetByName(ctx, name)
+	if nil != err {
+		return err
+	} else if firewall != nil {
+		if !owner.IsOwnerOf(firewall.Labels, bastionFirewallRole) {
+			owner.RefuseDeletion("firewall", firewall.Name, firewall.ID)
+			return nil
+		}
+
+		_, err = client.Firewall.Delete(ctx, firewall)
+		if nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getBastionFirewallRules returns the firewall rules allowing SSH access from the given ingress CIDRs.
+//
+// PARAMETERS
+// ingress []extensionsv1alpha1.BastionIngressPolicy Bastion ingress policies
+func getBastionFirewallRules(ingress []extensionsv1alpha1.BastionIngressPolicy) ([]hcloud.FirewallRule, error) {
+	var sourceIPs []net.IPNet
+	known := map[string]bool{}
+
+	for _, policy := range ingress {
+		_, ipNet, err := net.ParseCIDR(policy.IPBlock.CIDR)
+		if nil != err {
+			return nil, err
+		}
+
+		if !known[ipNet.String()] {
+			known[ipNet.String()] = true
+			sourceIPs = append(sourceIPs, *ipNet)
+		}
+	}
+
+	if len(sourceIPs) == 0 {
+		return []hcloud.FirewallRule{}, nil
+	}
+
+	rules := []hcloud.FirewallRule{
+		{
+			Direction:   hcloud.FirewallRuleDirectionIn,
+			Protocol:    hcloud.FirewallRuleProtocolTCP,
+			SourceIPs:   sourceIPs,
+			Port:        hcloud.Ptr(sshPort),
+			Description: hcloud.Ptr("gardener: SSH bastion ingress"),
+		},
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure bastion changes to be applied
+package ensurer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+const testActionResponse = `{"action": {"id": 1, "command": "test", "status": "success", "progress": 100, "started": "2016-01-30T23:50:00+00:00", "resources": []}}`
+
+func getIngressPolicy(cidr string) extensionsv1alpha1.BastionIngressPolicy {
+	return extensionsv1alpha1.BastionIngressPolicy{IPBlock: networkingv1.IPBlock{CIDR: cidr}}
+}
+
+var _ = Describe("Bastion", func() {
+	Describe("#getBastionFirewallRules", func() {
+		It("should only allow SSH from the ingress CIDRs", func() {
+			rules, err := getBastionFirewallRules([]extensionsv1alpha1.BastionIngressPolicy{
+				getIngressPolicy("1.2.3.4/32"),
+				getIngressPolicy("2001:db8::/64"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(HaveLen(1))
+
+			rule := rules[0]
+			Expect(*rule.Description).To(Equal("gardener: SSH bastion ingress"))
+			Expect(rule.Direction).To(Equal(hcloud.FirewallRuleDirectionIn))
+			Expect(rule.Protocol).To(Equal(hcloud.FirewallRuleProtocolTCP))
+			Expect(*rule.Port).To(Equal(sshPort))
+			Expect(rule.SourceIPs).To(HaveLen(2))
+			Expect(rule.SourceIPs[0].String()).To(Equal("1.2.3.4/32"))
+			Expect(rule.SourceIPs[1].String()).To(Equal("2001:db8::/64"))
+		})
+
+		It("should deduplicate equal ingress CIDRs", func() {
+			rules, err := getBastionFirewallRules([]extensionsv1alpha1.BastionIngressPolicy{
+				getIngressPolicy("1.2.3.4/32"),
+				getIngressPolicy("10.0.0.1/8"),
+				getIngressPolicy("10.0.0.0/8"),
+				getIngressPolicy("1.2.3.4/32"),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(HaveLen(1))
+			Expect(rules[0].SourceIPs).To(HaveLen(2))
+			Expect(rules[0].SourceIPs[1].String()).To(Equal("10.0.0.0/8"))
+		})
+
+		It("should not allow any access without ingress CIDRs", func() {
+			rules, err := getBastionFirewallRules(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).NotTo(BeNil())
+			Expect(rules).To(BeEmpty())
+		})
+
+		It("should fail for an invalid ingress CIDR", func() {
+			_, err := getBastionFirewallRules([]extensionsv1alpha1.BastionIngressPolicy{getIngressPolicy("1.2.3.4")})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#EnsureBastionServer", func() {
+		var (
+			mockTestEnv mock.MockTestEnv
+			owner       *controller.ResourceOwner
+			firewall    *hcloud.Firewall
+			network     *hcloud.Network
+		)
+
+		BeforeEach(func() {
+			mockTestEnv = mock.NewMockTestEnv()
+			owner = controller.NewResourceOwner("shoot-uid", "", nil, nil)
+			firewall = &hcloud.Firewall{ID: 2}
+			network = &hcloud.Network{ID: 42}
+
+			mockTestEnv.Mux.HandleFunc("/actions", func(res http.ResponseWriter, req *http.Request) {
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+
+				_, _ = res.Write([]byte(`{"actions": []}`))
+			})
+		})
+
+		AfterEach(func() {
+			mockTestEnv.Teardown()
+		})
+
+		writeServerResponse := func(res http.ResponseWriter, format string, privateNet string) {
+			labels, err := json.Marshal(owner.Labels(bastionServerRole))
+			Expect(err).NotTo(HaveOccurred())
+
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusOK)
+
+			_, _ = fmt.Fprintf(res, format, fmt.Sprintf(`{"id": 1, "name": "test-bastion", "status": "running", "public_net": {"firewalls": [{"id": 2, "status": "applied"}]}, "private_net": [%s], "labels": %s, "created": "2016-01-30T23:50:00+00:00"}`, privateNet, labels))
+		}
+
+		It("should create the bastion server attached to the workers network", func() {
+			var createRequest map[string]interface{}
+
+			mockTestEnv.Mux.HandleFunc("/servers", func(res http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodGet {
+					res.Header().Add("Content-Type", "application/json; charset=utf-8")
+					res.WriteHeader(http.StatusOK)
+
+					_, _ = res.Write([]byte(`{"servers": []}`))
+
+					return
+				}
+
+				body, err := io.ReadAll(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(json.Unmarshal(body, &createRequest)).To(Succeed())
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+
+				_, _ = fmt.Fprintf(res, `{"server": {"id": 1, "name": "test-bastion", "created": "2016-01-30T23:50:00+00:00"}, %s, "next_actions": []}`, testActionResponse[1:len(testActionResponse)-1])
+			})
+
+			mockTestEnv.Mux.HandleFunc("/servers/1", func(res http.ResponseWriter, req *http.Request) {
+				writeServerResponse(res, `{"server": %s}`, `{"network": 42, "ip": "10.250.0.2"}`)
+			})
+
+			server, err := EnsureBastionServer(context.TODO(), mockTestEnv.HcloudClient, owner, "test-bastion", "hel1", "cx22", "ubuntu-22.04", nil, firewall, network)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.PrivateNetFor(network)).NotTo(BeNil())
+			Expect(createRequest["networks"]).To(Equal([]interface{}{float64(42)}))
+		})
+
+		It("should attach an existing bastion server to the workers network", func() {
+			attached := false
+
+			mockTestEnv.Mux.HandleFunc("/servers", func(res http.ResponseWriter, req *http.Request) {
+				writeServerResponse(res, `{"servers": [%s]}`, "")
+			})
+
+			mockTestEnv.Mux.HandleFunc("/servers/1/actions/attach_to_network", func(res http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(body)).To(ContainSubstring(`"network":42`))
+
+				attached = true
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+
+				_, _ = res.Write([]byte(testActionResponse))
+			})
+
+			_, err := EnsureBastionServer(context.TODO(), mockTestEnv.HcloudClient, owner, "test-bastion", "hel1", "cx22", "ubuntu-22.04", nil, firewall, network)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attached).To(BeTrue())
+		})
+
+		It("should keep an existing bastion server already attached to the workers network", func() {
+			mockTestEnv.Mux.HandleFunc("/servers", func(res http.ResponseWriter, req *http.Request) {
+				writeServerResponse(res, `{"servers": [%s]}`, `{"network": 42, "ip": "10.250.0.2"}`)
+			})
+
+			mockTestEnv.Mux.HandleFunc("/servers/1/actions/attach_to_network", func(res http.ResponseWriter, req *http.Request) {
+				Fail("Server must not be attached twice")
+			})
+
+			_, err := EnsureBastionServer(context.TODO(), mockTestEnv.HcloudClient, owner, "test-bastion", "hel1", "cx22", "ubuntu-22.04", nil, firewall, network)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -21,13 +21,17 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"maps"
 	"net"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/controlplane/genericactuator"
 	extensionssecretsmanager "github.com/gardener/gardener/extensions/pkg/util/secret/manager"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/gardener/gardener/pkg/utils/chart"
@@ -49,6 +53,7 @@ import (
 	"github.com/23technologies/gardener-extension-provider-hcloud/charts"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 )
 
@@ -318,6 +323,11 @@ func (vp *valuesProvider) GetStorageClassesChartValues(
 
 	volumeBindingMode := "Immediate"
 
+	// Volumes must be created in the location of the node consuming them if the region spans multiple locations.
+	if apis.IsNetworkZoneRegion(cluster.Shoot.Spec.Region) {
+		volumeBindingMode = "WaitForFirstConsumer"
+	}
+
 	return map[string]interface{}{
 		"fsType":               cloudProfileConfig.DefaultStorageFsType,
 		"volumeBindingMode":    volumeBindingMode,
@@ -339,11 +349,7 @@ func (vp *valuesProvider) getConfigChartValues(
 	credentials *hcloud.Credentials,
 ) (map[string]interface{}, error) {
 	zone := cpConfig.Zone
-
-	region := apis.GetRegionFromZone(zone)
-	if "" == region {
-		region = cp.Spec.Region
-	}
+	region := apis.GetLocation(cp.Spec.Region, zone)
 
 	// Collect config chart values
 	values := map[string]interface{}{
@@ -376,22 +382,24 @@ func (vp *valuesProvider) getControlPlaneChartValues(
 	checksums map[string]string,
 	scaledDown bool,
 ) (map[string]interface{}, error) {
-	region := apis.GetRegionFromZone(cpConfig.Zone)
-	if "" == region {
-		region = cp.Spec.Region
-	}
+	region := apis.GetLocation(cp.Spec.Region, cpConfig.Zone)
 
 	ccmValues, err := vp.getCCMChartValues(cpConfig, infraStatus, cp, cluster, secretsReader, checksums, scaledDown, region)
 	if err != nil {
 		return nil, err
 	}
 
+	csiValues, err := vp.getCSIControllerChartValues(cp, cluster, credentials, checksums, scaledDown, region)
+	if err != nil {
+		return nil, err
+	}
+
 	values := map[string]interface{}{
 		"global": map[string]interface{}{
 			"genericTokenKubeconfigSecretName": extensionscontroller.GenericTokenKubeconfigSecretNameFromCluster(cluster),
 		},
 		hcloud.CloudControllerManagerName: ccmValues,
-		hcloud.CSIControllerName:          vp.getCSIControllerChartValues(cp, cluster, credentials, checksums, scaledDown, region),
+		hcloud.CSIControllerName:          csiValues,
 	}
 
 	return values, nil
@@ -440,14 +448,46 @@ func (vp *valuesProvider) getCCMChartValues(
 		"serverSecretName": ccmSecret.Name,
 	}
 
-	podNetworks := extensionscontroller.GetPodNetwork(cluster)
+	// Load balancers may be placed in any location of the network zone if the region spans multiple locations.
+	if apis.IsNetworkZoneRegion(cp.Spec.Region) {
+		values["podNetworkZone"] = cp.Spec.Region
+	}
+
+	ipv4Enabled := apis.IsIPFamilyEnabled(cluster.Shoot, gardencorev1beta1.IPFamilyIPv4)
+	ipv6Enabled := apis.IsIPFamilyEnabled(cluster.Shoot, gardencorev1beta1.IPFamilyIPv6)
+
+	switch {
+	case ipv4Enabled && ipv6Enabled:
+		values["instancesAddressFamily"] = "dualstack"
+	case ipv6Enabled:
+		values["instancesAddressFamily"] = "ipv6"
+	default:
+		values["instancesAddressFamily"] = "ipv4"
+	}
+
+	values["loadBalancer"] = map[string]interface{}{
+		"disableIPv6":           !ipv6Enabled,
+		"disablePrivateIngress": true,
+	}
+
+	// HCloud networks only route IPv4, IPv6 pod addresses are routed natively by the public node addresses. Without a
+	// pod network the cloud-controller-manager does not configure any routes.
+	var podNetworks []string
+
+	for _, podNetwork := range extensionscontroller.GetPodNetwork(cluster) {
+		ipAddr, _, err := net.ParseCIDR(podNetwork)
+		if err == nil && nil != ipAddr.To4() {
+			podNetworks = append(podNetworks, podNetwork)
+		}
+	}
+
 	if len(podNetworks) > 1 {
 		return nil, fmt.Errorf("multiple pod networks unsupported: %v", podNetworks)
 	} else if len(podNetworks) == 1 {
 		podNetwork := podNetworks[0]
 
 		ipAddr, _, err := net.ParseCIDR(podNetwork)
-		if err == nil && ipAddr.IsPrivate() {
+		if err == nil && ipAddr.IsPrivate() && apis.IsNetworkRoutingEnabled(cpConfig) {
 			values["podNetwork"] = podNetwork
 		}
 	}
@@ -481,15 +521,21 @@ func (vp *valuesProvider) getCSIControllerChartValues(
 	checksums map[string]string,
 	scaledDown bool,
 	region string,
-) map[string]interface{} {
+) (map[string]interface{}, error) {
 	csiClusterID := vp.calcCsiClusterID(cp)
 
+	volumeLabels, err := getVolumeLabels(cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
 		"replicas":          extensionscontroller.GetControlPlaneReplicas(cluster, scaledDown, 1),
 		"kubernetesVersion": cluster.Shoot.Spec.Kubernetes.Version,
 		"clusterID":         csiClusterID,
 		"token":             credentials.CSI().Token,
 		"csiRegion":         region,
+		"volumeLabels":      volumeLabels,
 		// "resizerEnabled":    csiResizerEnabled,
 		"podAnnotations": map[string]interface{}{
 			"checksum/secret-" + hcloud.CSIProvisionerName:                checksums[hcloud.CSIProvisionerName],
@@ -498,7 +544,38 @@ func (vp *valuesProvider) getCSIControllerChartValues(
 			"checksum/secret-" + hcloud.CSIControllerName:                 checksums[hcloud.CSIControllerName],
 			"checksum/secret-" + v1beta1constants.SecretNameCloudProvider: checksums[v1beta1constants.SecretNameCloudProvider],
 		},
+	}, nil
+}
+
+// getVolumeLabels returns the labels added to each volume created by the CSI driver in the "key=value" list format
+// of its HCLOUD_VOLUME_EXTRA_LABELS option. Volumes are labeled with the user-defined labels and the shoot UID to be
+// found by the force deletion of the infrastructure.
+//
+// PARAMETERS
+// cluster *extensionscontroller.Cluster Cluster struct
+func getVolumeLabels(cluster *extensionscontroller.Cluster) (string, error) {
+	labels := map[string]string{}
+
+	if nil != cluster.Shoot.Spec.Provider.InfrastructureConfig {
+		infraConfig, err := transcoder.DecodeInfrastructureConfigFromCluster(cluster)
+		if err != nil {
+			return "", err
+		}
+
+		maps.Copy(labels, infraConfig.Labels)
+	}
+
+	if uid := string(cluster.Shoot.GetUID()); "" != uid {
+		labels[controller.LabelClusterID] = uid
 	}
+
+	var volumeLabels []string
+
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		volumeLabels = append(volumeLabels, key+"="+labels[key])
+	}
+
+	return strings.Join(volumeLabels, ","), nil
 }
 
 // getControlPlaneShootChartValues collects and returns the control plane shoot chart values.
@@ -21,8 +21,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/controlplane/genericactuator"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	"github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	secretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager"
 	fakesecretsmanager "github.com/gardener/gardener/pkg/utils/secrets/manager/fake"
@@ -76,6 +79,39 @@ var _ = AfterSuite(func() {
 	mockTestEnv.Teardown()
 })
 
+// newDualStackCluster creates a new cluster of a dual-stack shoot.
+func newDualStackCluster() *v1alpha1.Cluster {
+	cluster := mock.NewCluster()
+	cluster.Spec.Shoot.Raw = []byte(strings.Replace(mock.TestClusterShoot, `"region": "hel1",`, `"region": "hel1", "networking": {"ipFamilies": ["IPv4", "IPv6"], "pods": "10.96.0.0/11"},`, 1))
+
+	return cluster
+}
+
+// newNativeRoutingCluster creates a new cluster of a shoot routing pod traffic without HCloud network routes.
+func newNativeRoutingCluster() *v1alpha1.Cluster {
+	cluster := mock.NewCluster()
+	shoot := strings.Replace(mock.TestClusterShoot, `"region": "hel1",`, `"region": "hel1", "networking": {"pods": "10.96.0.0/11"},`, 1)
+	cluster.Spec.Shoot.Raw = []byte(strings.Replace(shoot, `"zone": "hel1-dc2"`, `"zone": "hel1-dc2", "cloudControllerManager": {"nativeRouting": true}`, 1))
+
+	return cluster
+}
+
+// newNetworkZoneControlPlane creates a new control plane of a shoot in a region spanning the locations of a network zone.
+func newNetworkZoneControlPlane() *v1alpha1.ControlPlane {
+	cp := mock.NewControlPlane()
+	cp.Spec.Region = "eu-central"
+
+	return cp
+}
+
+// newNetworkZoneCluster creates a new cluster of a shoot in a region spanning the locations of a network zone.
+func newNetworkZoneCluster() *v1alpha1.Cluster {
+	cluster := mock.NewCluster()
+	cluster.Spec.Shoot.Raw = []byte(strings.Replace(mock.TestClusterShoot, `"region": "hel1",`, `"region": "eu-central",`, 1))
+
+	return cluster
+}
+
 var _ = Describe("ValuesProvider", func() {
 	Describe("#GetControlPlaneChartValues", func() {
 		type setup struct {
@@ -165,10 +201,126 @@ var _ = Describe("ValuesProvider", func() {
 							return fmt.Errorf("%q is invalid for hcloud-csi-controller.csiRegion", value)
 						}
 
+						return nil
+					},
+				},
+			}),
+			Entry("should return dual-stack cloud-controller-manager chart values", &data{
+				setup: setup{},
+				action: action{
+					mock.NewControlPlane(),
+					newDualStackCluster(),
+					false,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					comparator: func(mapValues map[string]interface{}) error {
+						mapValue, ok := mapValues["cloud-controller-manager"].(map[string]interface{})
+						if !ok {
+							return errors.New("cloud-controller-manager is missing")
+						}
+
+						value, ok := mapValue["instancesAddressFamily"]
+						if !ok || value != "dualstack" {
+							return fmt.Errorf("%q is invalid for cloud-controller-manager.instancesAddressFamily", value)
+						}
+
+						value, ok = mapValue["podNetwork"]
+						if !ok || value != "10.96.0.0/11" {
+							return fmt.Errorf("%q is invalid for cloud-controller-manager.podNetwork", value)
+						}
+
+						loadBalancer, ok := mapValue["loadBalancer"].(map[string]interface{})
+						if !ok || loadBalancer["disableIPv6"] != false {
+							return fmt.Errorf("%v is invalid for cloud-controller-manager.loadBalancer", loadBalancer)
+						}
+
+						return nil
+					},
+				},
+			}),
+			Entry("should omit the pod network of cloud-controller-manager chart values for native routing", &data{
+				setup: setup{},
+				action: action{
+					mock.NewControlPlane(),
+					newNativeRoutingCluster(),
+					false,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					comparator: func(mapValues map[string]interface{}) error {
+						mapValue, ok := mapValues["cloud-controller-manager"].(map[string]interface{})
+						if !ok {
+							return errors.New("cloud-controller-manager is missing")
+						}
+
+						if value, ok := mapValue["podNetwork"]; ok {
+							return fmt.Errorf("%q is invalid for cloud-controller-manager.podNetwork", value)
+						}
+
+						return nil
+					},
+				},
+			}),
+			Entry("should return cloud-controller-manager chart values for a network zone region", &data{
+				setup: setup{},
+				action: action{
+					newNetworkZoneControlPlane(),
+					newNetworkZoneCluster(),
+					false,
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					comparator: func(mapValues map[string]interface{}) error {
+						mapValue, ok := mapValues["cloud-controller-manager"].(map[string]interface{})
+						if !ok {
+							return errors.New("cloud-controller-manager is missing")
+						}
+
+						value, ok := mapValue["podNetworkZone"]
+						if !ok || value != "eu-central" {
+							return fmt.Errorf("%q is invalid for cloud-controller-manager.podNetworkZone", value)
+						}
+
+						mapValue, ok = mapValues["hcloud-csi-controller"].(map[string]interface{})
+						if !ok {
+							return errors.New("hcloud-csi-controller is missing")
+						}
+
+						value, ok = mapValue["csiRegion"]
+						if !ok || value != mock.TestRegion {
+							return fmt.Errorf("%q is invalid for hcloud-csi-controller.csiRegion", value)
+						}
+
 						return nil
 					},
 				},
 			}),
 		)
 	})
+
+	Describe("#getVolumeLabels", func() {
+		It("should label volumes with the user-defined labels and the shoot UID", func() {
+			cluster := &extensionscontroller.Cluster{
+				Shoot: &gardencorev1beta1.Shoot{
+					ObjectMeta: metav1.ObjectMeta{UID: "shoot-uid"},
+					Spec: gardencorev1beta1.ShootSpec{
+						Provider: gardencorev1beta1.Provider{
+							InfrastructureConfig: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureConfig","labels":{"team":"a","env":"dev"}}`)},
+						},
+					},
+				},
+			}
+
+			volumeLabels, err := getVolumeLabels(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeLabels).To(Equal("cluster.gardener.cloud/id=shoot-uid,env=dev,team=a"))
+		})
+
+		It("should return no labels for shoots without UID and infrastructure config", func() {
+			volumeLabels, err := getVolumeLabels(&extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumeLabels).To(BeEmpty())
+		})
+	})
 })
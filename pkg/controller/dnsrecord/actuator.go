@@ -0,0 +1,287 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dnsrecord contains functions used at the DNS record controller
+package dnsrecord
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/dnsrecord"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	hcloudclient "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/dnsrecord/ensurer"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+type actuator struct {
+	client   client.Client
+	recorder record.EventRecorder
+	gardenID string
+}
+
+// NewActuator creates a new Actuator that manages the HCloud DNS records of the handled DNSRecord resources.
+func NewActuator(mgr manager.Manager, gardenID string) dnsrecord.Actuator {
+	return &actuator{
+		client:   mgr.GetClient(),
+		recorder: mgr.GetEventRecorderFor(hcloud.Name + "-dnsrecord-controller"),
+		gardenID: gardenID,
+	}
+}
+
+// getResourceOwner returns the owner of the HCloud DNS records of the given DNS record resource.
+//
+// PARAMETERS
+// dns     *extensionsv1alpha1.DNSRecord DNSRecord struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) getResourceOwner(dns *extensionsv1alpha1.DNSRecord, cluster *extensionscontroller.Cluster) *controller.ResourceOwner {
+	clusterID := ""
+
+	if nil != cluster && nil != cluster.Shoot {
+		clusterID = string(cluster.Shoot.GetUID())
+	}
+
+	return controller.NewResourceOwner(clusterID, a.gardenID, a.recorder, dns)
+}
+
+// getClient returns the HCloud client for the credentials referenced by the given DNS record resource.
+//
+// PARAMETERS
+// ctx context.Context               Execution context
+// dns *extensionsv1alpha1.DNSRecord DNSRecord struct
+func (a *actuator) getClient(ctx context.Context, dns *extensionsv1alpha1.DNSRecord) (*hcloudclient.Client, error) {
+	secret, err := extensionscontroller.GetSecretByReference(ctx, a.client, &dns.Spec.SecretRef)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := hcloud.ExtractCredentials(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return apis.GetClientForToken(string(credentials.CCM().Token)), nil
+}
+
+// getZone returns the HCloud DNS zone of the given DNS record resource. The zone is looked up by the longest zone
+// name matching the record name if it is neither given in the spec nor known from a previous reconciliation.
+//
+// PARAMETERS
+// ctx          context.Context               Execution context
+// hcloudClient *hcloudclient.Client          HCloud client
+// dns          *extensionsv1alpha1.DNSRecord DNSRecord struct
+func getZone(ctx context.Context, hcloudClient *hcloudclient.Client, dns *extensionsv1alpha1.DNSRecord) (*hcloudclient.Zone, error) {
+	var zoneRef *string
+
+	if nil != dns.Spec.Zone {
+		zoneRef = dns.Spec.Zone
+	} else if nil != dns.Status.Zone {
+		zoneRef = dns.Status.Zone
+	}
+
+	if nil != zoneRef {
+		zone, _, err := hcloudClient.Zone.Get(ctx, *zoneRef)
+		if nil != err {
+			return nil, err
+		}
+
+		return zone, nil
+	}
+
+	zones, err := hcloudClient.Zone.All(ctx)
+	if nil != err {
+		return nil, err
+	}
+
+	return findZoneByName(zones, dns.Spec.Name), nil
+}
+
+// ForceDelete implements dnsrecord.Actuator.ForceDelete
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// log     logr.Logger                   Logger
+// dns     *extensionsv1alpha1.DNSRecord DNSRecord struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) ForceDelete(ctx context.Context, log logr.Logger, dns *extensionsv1alpha1.DNSRecord, cluster *extensionscontroller.Cluster) error {
+	return a.Delete(ctx, log, dns, cluster)
+}
+
+// Delete implements dnsrecord.Actuator.Delete
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// dns     *extensionsv1alpha1.DNSRecord DNSRecord struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) Delete(ctx context.Context, _ logr.Logger, dns *extensionsv1alpha1.DNSRecord, cluster *extensionscontroller.Cluster) error {
+	hcloudClient, err := a.getClient(ctx, dns)
+	if err != nil {
+		return err
+	}
+
+	zone, err := getZone(ctx, hcloudClient, dns)
+	if err != nil {
+		return err
+	} else if zone == nil {
+		return nil
+	}
+
+	return ensurer.EnsureDNSRecordDeleted(ctx, hcloudClient, a.getResourceOwner(dns, cluster), zone, getRelativeRecordName(dns.Spec.Name, zone.Name), hcloudclient.ZoneRRSetType(dns.Spec.RecordType))
+}
+
+// Migrate implements dnsrecord.Actuator.Migrate
+func (a *actuator) Migrate(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.DNSRecord, _ *extensionscontroller.Cluster) error {
+	return nil
+}
+
+// Reconcile implements dnsrecord.Actuator.Reconcile
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// dns     *extensionsv1alpha1.DNSRecord DNSRecord struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) Reconcile(ctx context.Context, _ logr.Logger, dns *extensionsv1alpha1.DNSRecord, cluster *extensionscontroller.Cluster) error {
+	hcloudClient, err := a.getClient(ctx, dns)
+	if err != nil {
+		return err
+	}
+
+	zone, err := getZone(ctx, hcloudClient, dns)
+	if err != nil {
+		return err
+	} else if zone == nil {
+		return fmt.Errorf("Failed to find a DNS zone for %q", dns.Spec.Name)
+	}
+
+	var ttl *int
+
+	if nil != dns.Spec.TTL {
+		ttl = hcloudclient.Ptr(int(*dns.Spec.TTL))
+	}
+
+	err = ensurer.EnsureDNSRecord(ctx, hcloudClient, a.getResourceOwner(dns, cluster), zone, getRelativeRecordName(dns.Spec.Name, zone.Name), hcloudclient.ZoneRRSetType(dns.Spec.RecordType), getRecordValues(dns.Spec.RecordType, dns.Spec.Values), ttl)
+	if err != nil {
+		return err
+	}
+
+	return a.updateStatus(ctx, dns, strconv.FormatInt(zone.ID, 10))
+}
+
+// Restore implements dnsrecord.Actuator.Restore
+//
+// PARAMETERS
+// ctx     context.Context               Execution context
+// log     logr.Logger                   Logger
+// dns     *extensionsv1alpha1.DNSRecord DNSRecord struct
+// cluster *extensionscontroller.Cluster Cluster struct
+func (a *actuator) Restore(ctx context.Context, log logr.Logger, dns *extensionsv1alpha1.DNSRecord, cluster *extensionscontroller.Cluster) error {
+	return a.Reconcile(ctx, log, dns, cluster)
+}
+
+// updateStatus persists the zone of the given DNS record resource.
+//
+// PARAMETERS
+// ctx    context.Context               Execution context
+// dns    *extensionsv1alpha1.DNSRecord DNSRecord struct
+// zoneID string                        HCloud DNS zone ID
+func (a *actuator) updateStatus(ctx context.Context, dns *extensionsv1alpha1.DNSRecord, zoneID string) error {
+	if nil != dns.Status.Zone && *dns.Status.Zone == zoneID {
+		return nil
+	}
+
+	patch := client.MergeFrom(dns.DeepCopy())
+	dns.Status.Zone = &zoneID
+
+	return a.client.Status().Patch(ctx, dns, patch)
+}
+
+// findZoneByName returns the zone with the longest name the given record name is part of.
+//
+// PARAMETERS
+// zones []*hcloudclient.Zone HCloud DNS zones
+// name  string               Fully qualified record name
+func findZoneByName(zones []*hcloudclient.Zone, name string) *hcloudclient.Zone {
+	var result *hcloudclient.Zone
+
+	name = strings.TrimSuffix(name, ".")
+
+	for _, zone := range zones {
+		zoneName := strings.TrimSuffix(zone.Name, ".")
+
+		if name != zoneName && !strings.HasSuffix(name, "."+zoneName) {
+			continue
+		}
+
+		if nil == result || len(zoneName) > len(strings.TrimSuffix(result.Name, ".")) {
+			result = zone
+		}
+	}
+
+	return result
+}
+
+// getRelativeRecordName returns the record name relative to the zone given. "@" is returned for the zone apex.
+//
+// PARAMETERS
+// name     string Fully qualified record name
+// zoneName string DNS zone name
+func getRelativeRecordName(name, zoneName string) string {
+	name = strings.TrimSuffix(name, ".")
+	zoneName = strings.TrimSuffix(zoneName, ".")
+
+	if name == zoneName {
+		return "@"
+	}
+
+	return strings.TrimSuffix(name, "."+zoneName)
+}
+
+// getRecordValues returns the record values in the presentation format expected by HCloud DNS.
+//
+// PARAMETERS
+// recordType extensionsv1alpha1.DNSRecordType Record type
+// values     []string                         Record values
+func getRecordValues(recordType extensionsv1alpha1.DNSRecordType, values []string) []string {
+	result := make([]string, 0, len(values))
+
+	for _, value := range values {
+		switch recordType {
+		case extensionsv1alpha1.DNSRecordTypeCNAME:
+			if !strings.HasSuffix(value, ".") {
+				value += "."
+			}
+		case extensionsv1alpha1.DNSRecordTypeTXT:
+			if !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) || len(value) < 2 {
+				value = strconv.Quote(value)
+			}
+		}
+
+		result = append(result, value)
+	}
+
+	return result
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dnsrecord contains functions used at the DNS record controller
+package dnsrecord
+
+import (
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	hcloudclient "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Actuator", func() {
+	Describe("#findZoneByName", func() {
+		zones := []*hcloudclient.Zone{
+			{ID: 1, Name: "example.com"},
+			{ID: 2, Name: "shoot.example.com"},
+			{ID: 3, Name: "ample.com"},
+		}
+
+		DescribeTable("##table",
+			func(name string, expectedID int64) {
+				zone := findZoneByName(zones, name)
+
+				if 0 == expectedID {
+					Expect(zone).To(BeNil())
+				} else {
+					Expect(zone).NotTo(BeNil())
+					Expect(zone.ID).To(Equal(expectedID))
+				}
+			},
+
+			Entry("zone apex", "example.com", int64(1)),
+			Entry("record in zone", "api.example.com", int64(1)),
+			Entry("record in longest matching zone", "api.shoot.example.com", int64(2)),
+			Entry("fully qualified record name", "api.shoot.example.com.", int64(2)),
+			Entry("zone name suffix without label boundary", "api.sample.com", int64(0)),
+			Entry("unknown zone", "api.example.org", int64(0)),
+		)
+	})
+
+	Describe("#getRelativeRecordName", func() {
+		DescribeTable("##table",
+			func(name, zoneName, expected string) {
+				Expect(getRelativeRecordName(name, zoneName)).To(Equal(expected))
+			},
+
+			Entry("zone apex", "example.com", "example.com", "@"),
+			Entry("record in zone", "api.shoot.example.com", "example.com", "api.shoot"),
+			Entry("wildcard record", "*.ingress.example.com.", "example.com", "*.ingress"),
+		)
+	})
+
+	Describe("#getRecordValues", func() {
+		DescribeTable("##table",
+			func(recordType extensionsv1alpha1.DNSRecordType, values, expected []string) {
+				Expect(getRecordValues(recordType, values)).To(Equal(expected))
+			},
+
+			Entry("A record", extensionsv1alpha1.DNSRecordTypeA, []string{"1.2.3.4"}, []string{"1.2.3.4"}),
+			Entry("CNAME record", extensionsv1alpha1.DNSRecordTypeCNAME, []string{"lb.example.com"}, []string{"lb.example.com."}),
+			Entry("TXT record", extensionsv1alpha1.DNSRecordTypeTXT, []string{"owner=shoot"}, []string{`"owner=shoot"`}),
+			Entry("quoted TXT record", extensionsv1alpha1.DNSRecordTypeTXT, []string{`"owner=shoot"`}, []string{`"owner=shoot"`}),
+		)
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure DNS record changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+// dnsRecordRole is the role label value of DNS record sets created.
+const dnsRecordRole = "dnsrecord-v1"
+
+// EnsureDNSRecord verifies that the DNS record set exists in the zone given with the expected values and TTL.
+//
+// PARAMETERS
+// ctx        context.Context           Execution context
+// client     *hcloud.Client            HCloud client
+// owner      *controller.ResourceOwner Resource owner
+// zone       *hcloud.Zone              HCloud DNS zone
+// name       string                    Record name relative to the zone
+// recordType hcloud.ZoneRRSetType      Record type
+// values     []string                  Record values
+// ttl        *int                      Record TTL or nil to use the zone default
+func EnsureDNSRecord(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, zone *hcloud.Zone, name string, recordType hcloud.ZoneRRSetType, values []string, ttl *int) error {
+	records := make([]hcloud.ZoneRRSetRecord, 0, len(values))
+
+	for _, value := range values {
+		records = append(records, hcloud.ZoneRRSetRecord{Value: value})
+	}
+
+	rrset, _, err := client.Zone.GetRRSetByNameAndType(ctx, zone, name, recordType)
+	if nil != err {
+		return err
+	} else if rrset == nil {
+		opts := hcloud.ZoneRRSetCreateOpts{
+			Name:    name,
+			Type:    recordType,
+			TTL:     ttl,
+			Labels:  owner.Labels(dnsRecordRole),
+			Records: records,
+		}
+
+		result, _, err := client.Zone.CreateRRSet(ctx, zone, opts)
+		if nil != err {
+			return err
+		}
+
+		return client.Action.WaitFor(ctx, result.Action)
+	}
+
+	if !owner.IsOwnerOf(rrset.Labels, "") {
+		return fmt.Errorf("DNS record %q of type %s in zone %q is not owned by this shoot", name, recordType, zone.Name)
+	}
+
+	if (nil == ttl) != (nil == rrset.TTL) || (nil != ttl && *ttl != *rrset.TTL) {
+		action, _, err := client.Zone.ChangeRRSetTTL(ctx, rrset, hcloud.ZoneRRSetChangeTTLOpts{TTL: ttl})
+		if nil != err {
+			return err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return err
+		}
+	}
+
+	if !isEqualRecordSet(rrset.Records, values) {
+		action, _, err := client.Zone.SetRRSetRecords(ctx, rrset, hcloud.ZoneRRSetSetRecordsOpts{Records: records})
+		if nil != err {
+			return err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EnsureDNSRecordDeleted removes the DNS record set identified by the given name and type.
+//
+// PARAMETERS
+// ctx        context.Context           Execution context
+// client     *hcloud.Client            HCloud client
+// owner      *controller.ResourceOwner Resource owner
+// zone       *hcloud.Zone              HCloud DNS zone
+// name       string                    Record name relative to the zone
+// recordType hcloud.ZoneRRSetType      Record type
+func EnsureDNSRecordDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, zone *hcloud.Zone, name string, recordType hcloud.ZoneRRSetType) error {
+	rrset, _, err := client.Zone.GetRRSetByNameAndType(ctx, zone, name, recordType)
+	if nil != err {
+		return err
+	} else if rrset != nil {
+		if !owner.IsOwnerOf(rrset.Labels, "") {
+			owner.RefuseDeletion("DNS record", fmt.Sprintf("%s/%s", rrset.Name, rrset.Type), zone.ID)
+			return nil
+		}
+
+		result, _, err := client.Zone.DeleteRRSet(ctx, rrset)
+		if nil != err {
+			if hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		return client.Action.WaitFor(ctx, result.Action)
+	}
+
+	return nil
+}
+
+// isEqualRecordSet returns true if the given records contain exactly the values given.
+//
+// PARAMETERS
+// records []hcloud.ZoneRRSetRecord Records of the record set
+// values  []string                 Values expected
+func isEqualRecordSet(records []hcloud.ZoneRRSetRecord, values []string) bool {
+	if len(records) != len(values) {
+		return false
+	}
+
+	for _, record := range records {
+		if !slices.Contains(values, record.Value) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dnsrecord contains functions used at the DNS record controller
+package dnsrecord
+
+import (
+	"context"
+
+	"github.com/gardener/gardener/extensions/pkg/controller/dnsrecord"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+)
+
+var (
+	// DefaultAddOptions are the default AddOptions for AddToManager.
+	DefaultAddOptions = AddOptions{}
+)
+
+// AddOptions are options to apply when adding the HCloud DNS record controller to the manager.
+type AddOptions struct {
+	// Controller are the controller.Options.
+	Controller controller.Options
+	// IgnoreOperationAnnotation specifies whether to ignore the operation annotation or not.
+	IgnoreOperationAnnotation bool
+	// GardenId is the Gardener garden identity
+	GardenId       string
+	ExtensionClass extensionsv1alpha1.ExtensionClass
+}
+
+// AddToManagerWithOptions adds a controller with the given Options to the given manager.
+// The opts.Reconciler is being set with a newly instantiated actuator.
+//
+// PARAMETERS
+// mgr  manager.Manager DNS record controller manager instance
+// opts AddOptions      Options to add
+func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts AddOptions) error {
+	return dnsrecord.Add(ctx, mgr, dnsrecord.AddArgs{
+		Actuator:                  NewActuator(mgr, opts.GardenId),
+		ControllerOptions:         opts.Controller,
+		Predicates:                dnsrecord.DefaultPredicates(ctx, mgr, opts.IgnoreOperationAnnotation),
+		Type:                      hcloud.Type,
+		IgnoreOperationAnnotation: opts.IgnoreOperationAnnotation,
+		ExtensionClass:            opts.ExtensionClass,
+	})
+}
+
+// AddToManager adds a controller with the default Options.
+//
+// PARAMETERS
+// mgr manager.Manager DNS record controller manager instance
+func AddToManager(ctx context.Context, mgr manager.Manager) error {
+	return AddToManagerWithOptions(ctx, mgr, DefaultAddOptions)
+}
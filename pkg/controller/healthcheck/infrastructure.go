@@ -0,0 +1,289 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package healthcheck contains functions used for cluster validation
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/healthcheck"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	hcloudclient "github.com/hetznercloud/hcloud-go/v2/hcloud"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
+)
+
+// InfrastructureDriftChecker compares the HCloud resources recorded in the infrastructure status with the ones found.
+type InfrastructureDriftChecker struct {
+	logger     logr.Logger
+	seedClient client.Client
+	repair     bool
+}
+
+// NewInfrastructureDriftChecker returns a health check detecting HCloud resources deleted or changed outside of
+// Gardener.
+//
+// PARAMETERS
+// repair bool Trigger a reconciliation of drifted infrastructures if true
+func NewInfrastructureDriftChecker(repair bool) healthcheck.HealthCheck {
+	return &InfrastructureDriftChecker{
+		repair: repair,
+	}
+}
+
+// InjectSeedClient injects the seed client
+//
+// PARAMETERS
+// seedClient client.Client Seed client
+func (checker *InfrastructureDriftChecker) InjectSeedClient(seedClient client.Client) {
+	checker.seedClient = seedClient
+}
+
+// SetLoggerSuffix injects the logger
+//
+// PARAMETERS
+// provider  string Provider name
+// extension string Extension name
+func (checker *InfrastructureDriftChecker) SetLoggerSuffix(provider, extension string) {
+	checker.logger = log.Log.WithName(fmt.Sprintf("%s-%s-healthcheck-infrastructure-drift", provider, extension))
+}
+
+// DeepCopy clones the health check.
+func (checker *InfrastructureDriftChecker) DeepCopy() healthcheck.HealthCheck {
+	shallowCopy := *checker
+	return &shallowCopy
+}
+
+// Check executes the health check
+//
+// PARAMETERS
+// ctx     context.Context      Execution context
+// request types.NamespacedName Infrastructure to check
+func (checker *InfrastructureDriftChecker) Check(ctx context.Context, request types.NamespacedName) (*healthcheck.SingleCheckResult, error) {
+	infra := &extensionsv1alpha1.Infrastructure{}
+
+	err := checker.seedClient.Get(ctx, request, infra)
+	if nil != err {
+		return nil, err
+	}
+
+	infraConfig, err := transcoder.DecodeInfrastructureConfigFromInfrastructure(infra)
+	if nil != err {
+		return nil, err
+	}
+
+	infraStatus, err := transcoder.DecodeLatestInfrastructureStatusFromInfrastructure(infra)
+	if nil != err {
+		return nil, err
+	}
+
+	secret, err := extensionscontroller.GetSecretByReference(ctx, checker.seedClient, &infra.Spec.SecretRef)
+	if nil != err {
+		return nil, err
+	}
+
+	credentials, err := hcloud.ExtractCredentials(secret)
+	if nil != err {
+		return nil, err
+	}
+
+	drift, err := GetInfrastructureDrift(ctx, apis.GetClientForToken(credentials.CCM().Token), infraConfig, infraStatus)
+	if nil != err {
+		return nil, err
+	}
+
+	if len(drift) == 0 {
+		return &healthcheck.SingleCheckResult{
+			Status: gardencorev1beta1.ConditionTrue,
+		}, nil
+	}
+
+	detail := fmt.Sprintf("Infrastructure drifted from its status: %s", strings.Join(drift, "; "))
+	checker.logger.Info(detail, "infrastructure", request)
+
+	if checker.repair && infra.Annotations[v1beta1constants.GardenerOperation] != v1beta1constants.GardenerOperationReconcile {
+		patch := client.MergeFrom(infra.DeepCopy())
+		metav1.SetMetaDataAnnotation(&infra.ObjectMeta, v1beta1constants.GardenerOperation, v1beta1constants.GardenerOperationReconcile)
+
+		err = checker.seedClient.Patch(ctx, infra, patch)
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	return &healthcheck.SingleCheckResult{
+		Status: gardencorev1beta1.ConditionFalse,
+		Detail: detail,
+	}, nil
+}
+
+// GetInfrastructureDrift returns a description of each difference between the HCloud resources recorded in the
+// infrastructure status and the ones found.
+//
+// PARAMETERS
+// ctx         context.Context            Execution context
+// client      *hcloudclient.Client       HCloud client
+// infraConfig *apis.InfrastructureConfig Infrastructure config
+// infraStatus *apis.InfrastructureStatus Infrastructure status
+func GetInfrastructureDrift(ctx context.Context, client *hcloudclient.Client, infraConfig *apis.InfrastructureConfig, infraStatus *apis.InfrastructureStatus) ([]string, error) {
+	var drift []string
+
+	if "" != infraStatus.SSHFingerprint {
+		sshKey, _, err := client.SSHKey.GetByFingerprint(ctx, infraStatus.SSHFingerprint)
+		if nil != err {
+			return nil, err
+		} else if sshKey == nil {
+			drift = append(drift, fmt.Sprintf("SSH public key with fingerprint %q not found", infraStatus.SSHFingerprint))
+		}
+	}
+
+	if nil != infraStatus.NetworkIDs && "" != infraStatus.NetworkIDs.Workers {
+		networkDrift, err := getNetworkDrift(ctx, client, infraConfig.Networks, infraStatus.NetworkIDs)
+		if nil != err {
+			return nil, err
+		}
+
+		drift = append(drift, networkDrift...)
+	}
+
+	return drift, nil
+}
+
+// getNetworkDrift returns a description of each difference between the workers network recorded and the one found.
+//
+// PARAMETERS
+// ctx        context.Context                      Execution context
+// client     *hcloudclient.Client                 HCloud client
+// networks   *apis.InfrastructureConfigNetworks   Networks configuration
+// networkIDs *apis.InfrastructureConfigNetworkIDs Network IDs recorded
+func getNetworkDrift(ctx context.Context, client *hcloudclient.Client, networks *apis.InfrastructureConfigNetworks, networkIDs *apis.InfrastructureConfigNetworkIDs) ([]string, error) {
+	id, err := strconv.ParseInt(networkIDs.Workers, 10, 64)
+	if nil != err {
+		return nil, err
+	}
+
+	network, _, err := client.Network.GetByID(ctx, id)
+	if nil != err {
+		return nil, err
+	} else if network == nil {
+		return []string{fmt.Sprintf("Workers network %d not found", id)}, nil
+	}
+
+	var drift []string
+
+	if nil != networks && !networkIDs.WorkersExisting {
+		expectedIPRange := networks.Workers
+
+		if nil != networks.WorkersConfiguration {
+			expectedIPRange = networks.WorkersConfiguration.Cidr
+		}
+
+		if nil != networks.Shared {
+			expectedIPRange = networks.Shared.Cidr
+		} else if nil != networks.VSwitch {
+			_, workersRange, err := net.ParseCIDR(expectedIPRange)
+			if nil != err {
+				return nil, err
+			}
+
+			networkRange, err := apis.GetNetworkIPRange(workersRange, networks.VSwitch.Cidr)
+			if nil != err {
+				return nil, err
+			}
+
+			expectedIPRange = networkRange.String()
+		}
+
+		if nil != network.IPRange && network.IPRange.String() != expectedIPRange {
+			drift = append(drift, fmt.Sprintf("Workers network %d uses the IP range %q instead of %q", id, network.IPRange.String(), expectedIPRange))
+		}
+	}
+
+	for _, networkZone := range slices.Sorted(maps.Keys(networkIDs.WorkersSubnets)) {
+		isCovered, err := isCoveredBySubnets(network, hcloudclient.NetworkSubnetTypeCloud, networkIDs.WorkersSubnets[networkZone], networkZone)
+		if nil != err {
+			return nil, err
+		} else if !isCovered {
+			drift = append(drift, fmt.Sprintf("Workers network %d is missing subnets covering %q in network zone %q", id, networkIDs.WorkersSubnets[networkZone], networkZone))
+		}
+	}
+
+	if "" != networkIDs.VSwitchSubnet {
+		isCovered, err := isCoveredBySubnets(network, hcloudclient.NetworkSubnetTypeVSwitch, networkIDs.VSwitchSubnet, "")
+		if nil != err {
+			return nil, err
+		} else if !isCovered {
+			drift = append(drift, fmt.Sprintf("Workers network %d is missing the vSwitch subnet %q", id, networkIDs.VSwitchSubnet))
+		}
+	}
+
+	return drift, nil
+}
+
+// isCoveredBySubnets returns true if the given IP range is fully covered by subnets of the given type and network
+// zone. A network zone's IP range may be covered by multiple subnets after the workers CIDR has been expanded.
+//
+// PARAMETERS
+// network     *hcloudclient.Network          HCloud network
+// subnetType  hcloudclient.NetworkSubnetType Subnet type
+// cidr        string                         IP range to be covered
+// networkZone string                         Network zone or empty to match any
+func isCoveredBySubnets(network *hcloudclient.Network, subnetType hcloudclient.NetworkSubnetType, cidr, networkZone string) (bool, error) {
+	_, ipRange, err := net.ParseCIDR(cidr)
+	if nil != err {
+		return false, err
+	}
+
+	ones, bits := ipRange.Mask.Size()
+	uncoveredSize := uint64(1) << (bits - ones)
+
+	// HCloud subnets never overlap, so the sizes of the subnets contained add up to the size of the IP range.
+	for _, subnet := range network.Subnets {
+		if subnet.Type != subnetType || nil == subnet.IPRange || !ipRange.Contains(subnet.IPRange.IP) {
+			continue
+		}
+
+		if "" != networkZone && string(subnet.NetworkZone) != networkZone {
+			continue
+		}
+
+		subnetOnes, subnetBits := subnet.IPRange.Mask.Size()
+		if subnetBits != bits || subnetOnes < ones {
+			continue
+		}
+
+		uncoveredSize -= min(uncoveredSize, uint64(1)<<(subnetBits-subnetOnes))
+	}
+
+	return 0 == uncoveredSize, nil
+}
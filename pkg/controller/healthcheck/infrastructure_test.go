@@ -0,0 +1,191 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package healthcheck contains functions used for cluster validation
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+var _ = Describe("InfrastructureDriftChecker", func() {
+	var (
+		ctx         context.Context
+		mockTestEnv mock.MockTestEnv
+		infraConfig *apis.InfrastructureConfig
+	)
+
+	BeforeEach(func() {
+		ctx = context.TODO()
+		mockTestEnv = mock.NewMockTestEnv()
+
+		mock.SetupSshKeysEndpointOnMux(mockTestEnv.Mux)
+
+		mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusOK)
+
+			_, _ = res.Write([]byte(`
+{
+	"network": {
+		"id": 42,
+		"name": "Simulated network",
+		"ip_range": "10.250.0.0/16",
+		"subnets": [
+			{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central", "gateway": "10.250.0.1"}
+		],
+		"routes": [],
+		"servers": [],
+		"load_balancers": [],
+		"labels": {},
+		"created": "2016-01-30T23:50:00+00:00"
+	}
+}
+			`))
+		})
+
+		mockTestEnv.Mux.HandleFunc("/networks/44", func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusOK)
+
+			_, _ = res.Write([]byte(`
+{
+	"network": {
+		"id": 44,
+		"name": "Simulated expanded network",
+		"ip_range": "10.250.0.0/18",
+		"subnets": [
+			{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central", "gateway": "10.250.0.1"},
+			{"type": "cloud", "ip_range": "10.250.32.0/19", "network_zone": "eu-central", "gateway": "10.250.0.1"}
+		],
+		"routes": [],
+		"servers": [],
+		"load_balancers": [],
+		"labels": {},
+		"created": "2016-01-30T23:50:00+00:00"
+	}
+}
+			`))
+		})
+
+		mockTestEnv.Mux.HandleFunc("/networks/43", func(res http.ResponseWriter, req *http.Request) {
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusNotFound)
+
+			_, _ = res.Write([]byte(`{"error": {"code": "not_found", "message": "network not found"}}`))
+		})
+
+		infraConfig = &apis.InfrastructureConfig{
+			Networks: &apis.InfrastructureConfigNetworks{
+				Workers: "10.250.0.0/16",
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mockTestEnv.Teardown()
+	})
+
+	Describe("#GetInfrastructureDrift", func() {
+		It("should not report drift for matching resources", func() {
+			infraStatus := &apis.InfrastructureStatus{
+				SSHFingerprint: mock.TestSSHFingerprint,
+				NetworkIDs: &apis.InfrastructureConfigNetworkIDs{
+					Workers:        "42",
+					WorkersSubnets: map[string]string{"eu-central": "10.250.0.0/19"},
+				},
+			}
+
+			drift, err := GetInfrastructureDrift(ctx, mockTestEnv.HcloudClient, infraConfig, infraStatus)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+		})
+
+		It("should report a missing SSH public key and workers network", func() {
+			infraStatus := &apis.InfrastructureStatus{
+				SSHFingerprint: "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff",
+				NetworkIDs: &apis.InfrastructureConfigNetworkIDs{
+					Workers: "43",
+				},
+			}
+
+			drift, err := GetInfrastructureDrift(ctx, mockTestEnv.HcloudClient, infraConfig, infraStatus)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(ConsistOf(
+				`SSH public key with fingerprint "00:11:22:33:44:55:66:77:88:99:aa:bb:cc:dd:ee:ff" not found`,
+				"Workers network 43 not found",
+			))
+		})
+
+		It("should report a changed IP range and missing subnets", func() {
+			infraConfig.Networks.Workers = "10.250.0.0/15"
+
+			infraStatus := &apis.InfrastructureStatus{
+				NetworkIDs: &apis.InfrastructureConfigNetworkIDs{
+					Workers:        "42",
+					WorkersSubnets: map[string]string{"eu-central": "10.250.0.0/19", "us-east": "10.250.32.0/19"},
+					VSwitchSubnet:  "10.250.64.0/24",
+				},
+			}
+
+			drift, err := GetInfrastructureDrift(ctx, mockTestEnv.HcloudClient, infraConfig, infraStatus)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(Equal([]string{
+				`Workers network 42 uses the IP range "10.250.0.0/16" instead of "10.250.0.0/15"`,
+				`Workers network 42 is missing subnets covering "10.250.32.0/19" in network zone "us-east"`,
+				`Workers network 42 is missing the vSwitch subnet "10.250.64.0/24"`,
+			}))
+		})
+
+		It("should accept a network zone covered by multiple subnets", func() {
+			infraConfig.Networks.Workers = "10.250.0.0/18"
+
+			infraStatus := &apis.InfrastructureStatus{
+				NetworkIDs: &apis.InfrastructureConfigNetworkIDs{
+					Workers:        "44",
+					WorkersSubnets: map[string]string{"eu-central": "10.250.0.0/18"},
+				},
+			}
+
+			drift, err := GetInfrastructureDrift(ctx, mockTestEnv.HcloudClient, infraConfig, infraStatus)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+		})
+
+		It("should not compare the IP range of existing networks", func() {
+			infraConfig.Networks.Workers = "10.250.0.0/15"
+
+			infraStatus := &apis.InfrastructureStatus{
+				NetworkIDs: &apis.InfrastructureConfigNetworkIDs{
+					Workers:         "42",
+					WorkersExisting: true,
+					WorkersSubnets:  map[string]string{"eu-central": "10.250.0.0/19"},
+				},
+			}
+
+			drift, err := GetInfrastructureDrift(ctx, mockTestEnv.HcloudClient, infraConfig, infraStatus)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(drift).To(BeEmpty())
+		})
+	})
+})
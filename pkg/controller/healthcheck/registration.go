@@ -38,12 +38,20 @@ import (
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 )
 
+// ConditionTypeInfrastructureHealthy is the condition type reporting HCloud resources drifted from the infrastructure status.
+const ConditionTypeInfrastructureHealthy = "InfrastructureHealthy"
+
 var (
-	defaultSyncPeriod = time.Second * 30
+	defaultSyncPeriod                    = time.Second * 30
+	defaultInfrastructureDriftSyncPeriod = time.Minute * 10
 	// DefaultAddOptions are the default DefaultAddArgs for AddToManager.
 	DefaultAddOptions = healthcheck.DefaultAddArgs{
 		HealthCheckConfig: extensionconfig.HealthCheckConfig{SyncPeriod: metav1.Duration{Duration: defaultSyncPeriod}},
 	}
+	// InfrastructureDriftCheckConfig is the config of the infrastructure drift check requesting the HCloud API.
+	InfrastructureDriftCheckConfig = extensionconfig.HealthCheckConfig{SyncPeriod: metav1.Duration{Duration: defaultInfrastructureDriftSyncPeriod}}
+	// RepairInfrastructureDrift triggers a reconciliation of infrastructures drifted from their status if true.
+	RepairInfrastructureDrift = false
 )
 
 // RegisterHealthChecks registers health checks for each extension resource
@@ -82,6 +90,28 @@ func RegisterHealthChecks(ctx context.Context, mgr manager.Manager, opts healthc
 		return err
 	}
 
+	infraOpts := opts
+	infraOpts.HealthCheckConfig = InfrastructureDriftCheckConfig
+
+	err = healthcheck.DefaultRegistration(
+		ctx,
+		hcloud.Type,
+		extensionsv1alpha1.SchemeGroupVersion.WithKind(extensionsv1alpha1.InfrastructureResource),
+		func() client.ObjectList { return &extensionsv1alpha1.InfrastructureList{} },
+		func() extensionsv1alpha1.Object { return &extensionsv1alpha1.Infrastructure{} },
+		mgr,
+		infraOpts,
+		nil,
+		[]healthcheck.ConditionTypeToHealthCheck{{
+			ConditionType: ConditionTypeInfrastructureHealthy,
+			HealthCheck:   NewInfrastructureDriftChecker(RepairInfrastructureDrift),
+		}},
+		sets.Set[gardencorev1beta1.ConditionType]{},
+	)
+	if err != nil {
+		return err
+	}
+
 	return healthcheck.DefaultRegistration(
 		ctx,
 		hcloud.Type,
@@ -19,16 +19,20 @@ package infrastructure
 
 import (
 	"context"
+	"encoding/json"
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/infrastructure"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/infrastructure/ensurer"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
@@ -40,6 +44,7 @@ type actuator struct {
 	client     client.Client
 	restConfig *rest.Config
 	scheme     *runtime.Scheme
+	recorder   record.EventRecorder
 	gardenID   string
 }
 
@@ -55,9 +60,20 @@ func NewActuator(mgr manager.Manager, gardenID string) infrastructure.Actuator {
 		client:     mgr.GetClient(),
 		restConfig: mgr.GetConfig(),
 		scheme:     mgr.GetScheme(),
+		recorder:   mgr.GetEventRecorderFor(hcloud.Name + "-infrastructure-controller"),
 		gardenID:   gardenID,
 	}
 }
+
+// getResourceOwner returns the owner of the HCloud resources of the given infrastructure.
+//
+// PARAMETERS
+// infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
+// cluster *extensionscontroller.Cluster      Cluster struct
+func (a *actuator) getResourceOwner(infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) *controller.ResourceOwner {
+	return controller.NewResourceOwner(string(cluster.Shoot.GetUID()), a.gardenID, a.recorder, infra)
+}
+
 func (a *actuator) getActuatorConfig(ctx context.Context, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) (*actuatorConfig, error) {
 	cloudProfileConfig, err := transcoder.DecodeCloudProfileConfigFromControllerCluster(cluster)
 	if err != nil {
@@ -89,8 +105,15 @@ func (a *actuator) getActuatorConfig(ctx context.Context, infra *extensionsv1alp
 	return config, nil
 }
 
-func (a *actuator) ForceDelete(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Infrastructure, _ *extensionscontroller.Cluster) error {
-	return nil
+// ForceDelete implements infrastructure.Actuator.ForceDelete
+//
+// PARAMETERS
+// ctx     context.Context                    Execution context
+// log     logr.Logger                        Logger
+// infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
+// cluster *extensionscontroller.Cluster      Cluster struct
+func (a *actuator) ForceDelete(ctx context.Context, log logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
+	return a.forceDelete(ctx, log, infra, cluster)
 }
 
 // Delete implements infrastructure.Actuator.Delete
@@ -110,25 +133,35 @@ func (a *actuator) Delete(ctx context.Context, _ logr.Logger, infra *extensionsv
 // infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
 // cluster *extensionscontroller.Cluster      Cluster struct
 func (a *actuator) Migrate(ctx context.Context, _ logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
-	return nil
+	infraState, err := transcoder.DecodeInfrastructureStateFromInfrastructure(infra)
+	if err != nil {
+		return err
+	}
+
+	// The state is updated after each reconciliation step and may contain IDs not yet part of the provider status.
+	if nil != infraState.ProviderStatus {
+		return nil
+	}
+
+	infraStatus, err := transcoder.DecodeInfrastructureStatusFromInfrastructure(infra)
+	if err != nil {
+		return err
+	}
+
+	infraState.ProviderStatus = infraStatus
+
+	return a.updateProviderState(ctx, infra, infraState)
 }
 
 // Reconcile implements infrastructure.Actuator.Reconcile
 //
 // PARAMETERS
 // ctx     context.Context                    Execution context
+// log     logr.Logger                        Logger
 // infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
 // cluster *extensionscontroller.Cluster      Cluster struct
-func (a *actuator) Reconcile(ctx context.Context, _ logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
-	extendedCtx := context.WithValue(ctx, controller.CtxWrapDataKey("MethodData"), &controller.InfrastructureReconcileMethodData{})
-
-	err := a.reconcile(extendedCtx, infra, cluster)
-
-	if nil != err {
-		a.reconcileOnErrorCleanup(extendedCtx, infra, cluster, err)
-	}
-
-	return err
+func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
+	return a.reconcile(ctx, log, infra, cluster)
 }
 
 // Restore implements infrastructure.Actuator.Restore
@@ -137,8 +170,92 @@ func (a *actuator) Reconcile(ctx context.Context, _ logr.Logger, infra *extensio
 // ctx     context.Context                    Execution context
 // infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
 // cluster *extensionscontroller.Cluster      Cluster struct
-func (a *actuator) Restore(ctx context.Context, _ logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
-	return nil
+func (a *actuator) Restore(ctx context.Context, log logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
+	infraState, err := transcoder.DecodeInfrastructureStateFromInfrastructure(infra)
+	if err != nil {
+		return err
+	}
+
+	// Restore the provider status persisted to adopt the existing resources by ID during reconciliation.
+	if nil != infraState.ProviderStatus {
+		infraStatus := &v1alpha1.InfrastructureStatus{}
+
+		err = a.scheme.Convert(infraState.ProviderStatus, infraStatus, nil)
+		if err != nil {
+			return err
+		}
+
+		infraStatus.TypeMeta = metav1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "InfrastructureStatus",
+		}
+
+		raw, err := json.Marshal(infraStatus)
+		if err != nil {
+			return err
+		}
+
+		infra.Status.ProviderStatus = &runtime.RawExtension{Raw: raw}
+	}
+
+	return a.Reconcile(ctx, log, infra, cluster)
+}
+
+// getInfrastructureStatus returns the infrastructure status persisted last. The state is updated after each step of
+// a reconciliation and takes precedence over the provider status only updated after a successful one.
+//
+// PARAMETERS
+// infra *extensionsv1alpha1.Infrastructure Infrastructure struct
+func (a *actuator) getInfrastructureStatus(infra *extensionsv1alpha1.Infrastructure) (*apis.InfrastructureStatus, error) {
+	return transcoder.DecodeLatestInfrastructureStatusFromInfrastructure(infra)
+}
+
+// isSSHPublicKeyUsed returns a function checking if a SSH public key is used by another infrastructure of this seed
+// in the same HCloud project, e.g. of a shoot not having referenced it yet.
+//
+// PARAMETERS
+// infra *extensionsv1alpha1.Infrastructure Infrastructure struct
+// token string                             HCloud token of the infrastructure
+func (a *actuator) isSSHPublicKeyUsed(infra *extensionsv1alpha1.Infrastructure, token string) ensurer.IsSSHPublicKeyUsedFunc {
+	return func(ctx context.Context, fingerprint string) (bool, error) {
+		infraList := &extensionsv1alpha1.InfrastructureList{}
+
+		err := a.client.List(ctx, infraList)
+		if err != nil {
+			return false, err
+		}
+
+		for _, otherInfra := range infraList.Items {
+			if otherInfra.Spec.Type != hcloud.Type || otherInfra.UID == infra.UID || otherInfra.DeletionTimestamp != nil {
+				continue
+			}
+
+			infraStatus, err := a.getInfrastructureStatus(&otherInfra)
+			if err != nil {
+				return false, err
+			}
+
+			if infraStatus.SSHFingerprint != fingerprint && infraStatus.PreviousSSHFingerprint != fingerprint {
+				continue
+			}
+
+			secret, err := extensionscontroller.GetSecretByReference(ctx, a.client, &otherInfra.Spec.SecretRef)
+			if err != nil {
+				return false, err
+			}
+
+			credentials, err := hcloud.ExtractCredentials(secret)
+			if err != nil {
+				return false, err
+			}
+
+			if string(credentials.CCM().Token) == token {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
 }
 
 // updateProviderStatus updates the infrastructure provider status.
@@ -160,3 +277,36 @@ func (a *actuator) updateProviderStatus(ctx context.Context, infra *extensionsv1
 
 	return a.client.Status().Patch(ctx, infra, patch)
 }
+
+// updateProviderState updates the infrastructure state persisted for control plane migration.
+//
+// PARAMETERS
+// ctx        context.Context                    Execution context
+// infra      *extensionsv1alpha1.Infrastructure Infrastructure struct
+// infraState *apis.InfrastructureState          Infrastructure state to be applied
+func (a *actuator) updateProviderState(ctx context.Context, infra *extensionsv1alpha1.Infrastructure, infraState *apis.InfrastructureState) error {
+	state := &v1alpha1.InfrastructureState{}
+
+	err := a.scheme.Convert(infraState, state, nil)
+	if err != nil {
+		return err
+	}
+
+	state.TypeMeta = metav1.TypeMeta{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "InfrastructureState",
+	}
+
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(infra.DeepCopy())
+
+	infra.Status.State = &runtime.RawExtension{
+		Raw: raw,
+	}
+
+	return a.client.Status().Patch(ctx, infra, patch)
+}
@@ -22,8 +22,10 @@ import (
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
 
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/infrastructure/ensurer"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 )
@@ -46,16 +48,41 @@ func (a *actuator) delete(ctx context.Context, infra *extensionsv1alpha1.Infrast
 	}
 
 	client := apis.GetClientForToken(string(actuatorConfig.token))
+	owner := a.getResourceOwner(infra, cluster)
+	isSSHPublicKeyUsed := a.isSSHPublicKeyUsed(infra, string(actuatorConfig.token))
 
-	infraStatus, _ := transcoder.DecodeInfrastructureStatusFromInfrastructure(infra)
+	infraStatus, err := a.getInfrastructureStatus(infra)
+	if err != nil {
+		return err
+	}
 
 	if nil != infraStatus {
-		err = ensurer.EnsureNetworksDeleted(ctx, client, infra.Namespace, infraStatus.NetworkIDs)
+		err = ensurer.EnsureNATGatewayDeleted(ctx, client, owner, infraStatus.NATGatewayID, infraStatus.NATGatewayIP, infraStatus.NetworkIDs)
+		if err != nil {
+			return err
+		}
+
+		err = ensurer.EnsureFloatingIPsDeleted(ctx, client, owner, infraStatus.FloatingIPIDs)
+		if err != nil {
+			return err
+		}
+
+		err = ensurer.EnsureFirewallDeleted(ctx, client, owner, infraStatus.FirewallID)
+		if err != nil {
+			return err
+		}
+
+		err = ensurer.EnsureNetworksDeleted(ctx, client, owner, infra.Namespace, infraStatus.NetworkIDs)
 		if err != nil {
 			return err
 		}
 
-		err = ensurer.EnsureSSHPublicKeyDeleted(ctx, client, infraStatus.SSHFingerprint)
+		err = ensurer.EnsureSSHPublicKeyDeleted(ctx, client, owner, infraStatus.SSHFingerprint, isSSHPublicKeyUsed)
+		if err != nil {
+			return err
+		}
+
+		err = ensurer.EnsureSSHPublicKeyDeleted(ctx, client, owner, infraStatus.PreviousSSHFingerprint, isSSHPublicKeyUsed)
 		if err != nil {
 			return err
 		}
@@ -63,3 +90,33 @@ func (a *actuator) delete(ctx context.Context, infra *extensionsv1alpha1.Infrast
 
 	return a.updateProviderStatus(ctx, infra, nil)
 }
+
+// forceDelete removes all HCloud resources labeled as being owned by the shoot.
+//
+// PARAMETERS
+// ctx     context.Context                    Execution context
+// log     logr.Logger                        Logger
+// infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
+// cluster *extensionscontroller.Cluster      Cluster struct
+func (a *actuator) forceDelete(ctx context.Context, log logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
+	// The provider config is not required to sweep resources and may be missing for partially created shoots.
+	secret, err := extensionscontroller.GetSecretByReference(ctx, a.client, &infra.Spec.SecretRef)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := hcloud.ExtractCredentials(secret)
+	if err != nil {
+		return err
+	}
+
+	client := apis.GetClientForToken(string(credentials.CCM().Token))
+	owner := a.getResourceOwner(infra, cluster)
+
+	infraStatus, err := a.getInfrastructureStatus(infra)
+	if err != nil {
+		return err
+	}
+
+	return ensurer.EnsureLabeledResourcesDeleted(ctx, client, log, owner, infra.Namespace, infraStatus.NetworkIDs, a.isSSHPublicKeyUsed(infra, string(credentials.CCM().Token)))
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package infrastructure contains functions used at the infrastructure controller
+package infrastructure
+
+import (
+	"context"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
+	hcloudv1alpha1 "github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/v1alpha1"
+)
+
+var _ = Describe("ActuatorMigrate", func() {
+	Describe("#Migrate", func() {
+		It("should persist the provider status in the state", func() {
+			infra := mock.NewInfrastructure()
+			infra.Status.ProviderStatus = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureStatus","sshFingerprint":"dummy","firewallID":"42","networkIDs":{"workers":"42"}}`),
+			}
+
+			mockTestEnv.Client.EXPECT().Status().Return(sw).AnyTimes()
+			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj k8sclient.Object, _ k8sclient.Patch, _ ...k8sclient.SubResourcePatchOption) error {
+				infra, ok := obj.(*extensionsv1alpha1.Infrastructure)
+				Expect(ok).To(BeTrue())
+
+				infraState, err := transcoder.DecodeInfrastructureStateFromInfrastructure(infra)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(infraState.ProviderStatus).NotTo(BeNil())
+				Expect(infraState.ProviderStatus.FirewallID).To(Equal("42"))
+				Expect(infraState.ProviderStatus.NetworkIDs.Workers).To(Equal("42"))
+
+				return nil
+			})
+
+			err := infraActuator.Migrate(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should leave an existing state untouched", func() {
+			infra := mock.NewInfrastructure()
+			infra.Status.ProviderStatus = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureStatus","sshFingerprint":"dummy"}`),
+			}
+			infra.Status.State = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureState","providerStatus":{"sshFingerprint":"dummy","firewallID":"42","networkIDs":{"workers":"42"}}}`),
+			}
+
+			err := infraActuator.Migrate(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			infraState, err := transcoder.DecodeInfrastructureStateFromInfrastructure(infra)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infraState.ProviderStatus.FirewallID).To(Equal("42"))
+			Expect(infraState.ProviderStatus.NetworkIDs.Workers).To(Equal("42"))
+		})
+	})
+
+	Describe("#Restore", func() {
+		It("should adopt the resources persisted in the state", func() {
+			mockTestEnv.Client.EXPECT().Get(gomock.Any(), k8sclient.ObjectKey{Namespace: mock.TestNamespace, Name: mock.TestInfrastructureSecretName}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(func(_ context.Context, _ k8sclient.ObjectKey, secret *corev1.Secret, _ ...k8sclient.GetOption) error {
+				secret.Data = map[string][]byte{
+					"hcloudToken": []byte("dummy-token"),
+				}
+				return nil
+			})
+
+			mockTestEnv.Client.EXPECT().Status().Return(sw).AnyTimes()
+			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(7)
+
+			infra := mock.NewInfrastructure()
+			infra.Status.State = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureState","providerStatus":{"sshFingerprint":"dummy","firewallID":"42","networkIDs":{"workers":"42"}}}`),
+			}
+
+			err := infraActuator.Restore(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			infraStatus, ok := infra.Status.ProviderStatus.Object.(*hcloudv1alpha1.InfrastructureStatus)
+			Expect(ok).To(BeTrue())
+			Expect(infraStatus.FirewallID).To(Equal("42"))
+			Expect(infraStatus.NetworkIDs).NotTo(BeNil())
+			Expect(infraStatus.NetworkIDs.Workers).To(Equal("42"))
+		})
+	})
+})
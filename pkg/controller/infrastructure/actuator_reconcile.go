@@ -20,9 +20,13 @@ package infrastructure
 import (
 	"context"
 	"strconv"
+	"sync"
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils/flow"
+	"github.com/go-logr/logr"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/controller/infrastructure/ensurer"
@@ -32,13 +36,34 @@ import (
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/v1alpha1"
 )
 
+// reconciler holds the data shared by the tasks of an infrastructure reconciliation flow. The infrastructure status
+// is updated by each task and persisted in the infrastructure state afterwards. The state is patched on a copy of the
+// infrastructure guarded by the lock as the tasks read the infrastructure concurrently. Placement groups are not part
+// of the flow as they are managed by the worker controller.
+type reconciler struct {
+	*actuator
+
+	infra       *extensionsv1alpha1.Infrastructure
+	cluster     *extensionscontroller.Cluster
+	client      *hcloud.Client
+	token       string
+	owner       *controller.ResourceOwner
+	infraConfig *apis.InfrastructureConfig
+	zone        string
+
+	lock        sync.Mutex
+	infraStatus *apis.InfrastructureStatus
+	stateInfra  *extensionsv1alpha1.Infrastructure
+}
+
 // reconcile reconciles the infrastructure config.
 //
 // PARAMETERS
 // ctx     context.Context                    Execution context
+// log     logr.Logger                        Logger
 // infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
 // cluster *extensionscontroller.Cluster      Cluster struct
-func (a *actuator) reconcile(ctx context.Context, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
+func (a *actuator) reconcile(ctx context.Context, log logr.Logger, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster) error {
 	actuatorConfig, err := a.getActuatorConfig(ctx, infra, cluster)
 	if err != nil {
 		return err
@@ -49,69 +74,237 @@ func (a *actuator) reconcile(ctx context.Context, infra *extensionsv1alpha1.Infr
 		return err
 	}
 
-	infraConfig, err := transcoder.DecodeInfrastructureConfigFromInfrastructure(infra)
+	// Resume from the resources persisted by the last reconciliation even if it failed.
+	infraStatus, err := a.getInfrastructureStatus(infra)
 	if err != nil {
 		return err
 	}
 
-	client := apis.GetClientForToken(string(actuatorConfig.token))
+	r := &reconciler{
+		actuator:    a,
+		infra:       infra,
+		cluster:     cluster,
+		client:      apis.GetClientForToken(string(actuatorConfig.token)),
+		token:       string(actuatorConfig.token),
+		owner:       a.getResourceOwner(infra, cluster).WithLabels(actuatorConfig.infraConfig.Labels),
+		infraConfig: actuatorConfig.infraConfig,
+		zone:        cpConfig.Zone,
+		infraStatus: infraStatus,
+		stateInfra:  infra.DeepCopy(),
+	}
+
+	g := flow.NewGraph("HCloud infrastructure reconciliation")
+
+	ensureSSHPublicKey := g.Add(flow.Task{
+		Name: "Ensuring SSH public key",
+		Fn:   r.ensureSSHPublicKey,
+	})
+
+	ensureNetworks := g.Add(flow.Task{
+		Name: "Ensuring networks and subnets",
+		Fn:   r.ensureNetworks,
+	})
+
+	_ = g.Add(flow.Task{
+		Name: "Ensuring firewall",
+		Fn:   r.ensureFirewall,
+	})
+
+	_ = g.Add(flow.Task{
+		Name: "Ensuring floating IPs",
+		Fn:   r.ensureFloatingIPs,
+	})
+
+	ensureNATGateway := g.Add(flow.Task{
+		Name:         "Ensuring NAT gateway and routes",
+		Fn:           r.ensureNATGateway,
+		Dependencies: flow.NewTaskIDs(ensureSSHPublicKey, ensureNetworks),
+	})
+
+	_ = g.Add(flow.Task{
+		Name:         "Reporting network route usage",
+		Fn:           r.reportNetworkRouteUsage,
+		Dependencies: flow.NewTaskIDs(ensureNATGateway),
+	})
+
+	err = g.Compile().Run(ctx, flow.Opts{Log: log})
+
+	infra.Status.State = r.stateInfra.Status.State
+	infra.ResourceVersion = r.stateInfra.ResourceVersion
 
-	sshFingerprint, err := ensurer.EnsureSSHPublicKey(ctx, client, cluster, infra)
+	if err != nil {
+		return flow.Causes(err)
+	}
+
+	status := &v1alpha1.InfrastructureStatus{}
+
+	err = a.scheme.Convert(r.infraStatus, status, nil)
 	if err != nil {
 		return err
 	}
 
-	workerNetworkID, err := ensurer.EnsureNetworks(ctx, client, infra.Namespace, cpConfig.Zone, actuatorConfig.infraConfig.Networks)
+	status.TypeMeta = metav1.TypeMeta{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "InfrastructureStatus",
+	}
+
+	return a.updateProviderStatus(ctx, infra, status)
+}
+
+// persistInfrastructureStatus applies the given changes to the infrastructure status and persists the result in the
+// infrastructure state.
+//
+// PARAMETERS
+// ctx    context.Context                         Execution context
+// update func(status *apis.InfrastructureStatus) Function applying the changes
+func (r *reconciler) persistInfrastructureStatus(ctx context.Context, update func(status *apis.InfrastructureStatus)) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	update(r.infraStatus)
+
+	return r.updateProviderState(ctx, r.stateInfra, &apis.InfrastructureState{ProviderStatus: r.infraStatus})
+}
+
+// getPreviousInfrastructureStatus returns a copy of the infrastructure status as seen by the tasks run before.
+func (r *reconciler) getPreviousInfrastructureStatus() *apis.InfrastructureStatus {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.infraStatus.DeepCopy()
+}
+
+// ensureSSHPublicKey is the flow task ensuring the SSH public key.
+//
+// PARAMETERS
+// ctx context.Context Execution context
+func (r *reconciler) ensureSSHPublicKey(ctx context.Context) error {
+	sshFingerprint, previousSSHFingerprint, err := ensurer.EnsureSSHPublicKey(ctx, r.client, r.owner, r.cluster, r.infra, r.getPreviousInfrastructureStatus(), r.isSSHPublicKeyUsed(r.infra, r.token))
 	if err != nil {
 		return err
 	}
 
-	infraStatus := &v1alpha1.InfrastructureStatus{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: v1alpha1.SchemeGroupVersion.String(),
-			Kind:       "InfrastructureStatus",
-		},
-		SSHFingerprint: sshFingerprint,
+	return r.persistInfrastructureStatus(ctx, func(status *apis.InfrastructureStatus) {
+		status.SSHFingerprint = sshFingerprint
+		status.PreviousSSHFingerprint = previousSSHFingerprint
+	})
+}
+
+// ensureNetworks is the flow task ensuring the networks and subnets.
+//
+// PARAMETERS
+// ctx context.Context Execution context
+func (r *reconciler) ensureNetworks(ctx context.Context) error {
+	var workerZones []string
+
+	for _, pool := range r.cluster.Shoot.Spec.Provider.Workers {
+		workerZones = append(workerZones, pool.Zones...)
 	}
 
-	if "" != infraConfig.FloatingPoolName {
-		infraStatus.FloatingPoolName = infraConfig.FloatingPoolName
+	previousInfraStatus := r.getPreviousInfrastructureStatus()
+
+	networkIDs, err := ensurer.EnsureNetworks(ctx, r.client, r.owner, r.infra.Namespace, r.infra.Spec.Region, r.zone, workerZones, r.infraConfig.Networks, previousInfraStatus.NetworkIDs)
+	if err != nil {
+		return err
+	}
+
+	return r.persistInfrastructureStatus(ctx, func(status *apis.InfrastructureStatus) {
+		status.NetworkIDs = networkIDs
+	})
+}
+
+// ensureFirewall is the flow task ensuring the firewall.
+//
+// PARAMETERS
+// ctx context.Context Execution context
+func (r *reconciler) ensureFirewall(ctx context.Context) error {
+	previousInfraStatus := r.getPreviousInfrastructureStatus()
+
+	firewallID, err := ensurer.EnsureFirewall(ctx, r.client, r.owner, r.cluster, r.infra.Namespace, r.infraConfig.Networks, r.infraConfig.Firewall, previousInfraStatus.FirewallID)
+	if err != nil {
+		return err
 	}
 
-	if workerNetworkID > -1 {
-		infraStatus.NetworkIDs = &v1alpha1.InfrastructureConfigNetworkIDs{
-			Workers: strconv.FormatInt(workerNetworkID, 10),
+	return r.persistInfrastructureStatus(ctx, func(status *apis.InfrastructureStatus) {
+		status.FirewallID = strconv.FormatInt(firewallID, 10)
+	})
+}
+
+// ensureFloatingIPs is the flow task ensuring the floating IPs.
+//
+// PARAMETERS
+// ctx context.Context Execution context
+func (r *reconciler) ensureFloatingIPs(ctx context.Context) error {
+	var floatingIPIDs []string
+
+	previousInfraStatus := r.getPreviousInfrastructureStatus()
+
+	if nil != r.infraConfig.FloatingIPs || len(previousInfraStatus.FloatingIPIDs) > 0 {
+		ids, err := ensurer.EnsureFloatingIPs(ctx, r.client, r.owner, r.infra.Namespace, r.zone, r.infraConfig.FloatingPoolName, r.infraConfig.FloatingIPs)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			floatingIPIDs = append(floatingIPIDs, strconv.FormatInt(id, 10))
 		}
 	}
 
-	return a.updateProviderStatus(ctx, infra, infraStatus)
+	return r.persistInfrastructureStatus(ctx, func(status *apis.InfrastructureStatus) {
+		status.FloatingPoolName = r.infraConfig.FloatingPoolName
+		status.FloatingIPIDs = floatingIPIDs
+	})
 }
 
-// reconcileOnErrorCleanup cleans up a failed reconcile request
+// ensureNATGateway is the flow task ensuring the NAT gateway and the default route of the workers network. A NAT
+// gateway removed from the infrastructure config is deleted together with its default route.
 //
 // PARAMETERS
-// ctx     context.Context                    Execution context
-// infra   *extensionsv1alpha1.Infrastructure Infrastructure struct
-// cluster *extensionscontroller.Cluster      Cluster struct
-// err     error                              Error encountered
-func (a *actuator) reconcileOnErrorCleanup(ctx context.Context, infra *extensionsv1alpha1.Infrastructure, cluster *extensionscontroller.Cluster, err error) {
-	actuatorConfig, _ := a.getActuatorConfig(ctx, infra, cluster)
-	resultData := ctx.Value(controller.CtxWrapDataKey("MethodData")).(*controller.InfrastructureReconcileMethodData)
+// ctx context.Context Execution context
+func (r *reconciler) ensureNATGateway(ctx context.Context) error {
+	var natGatewayID, natGatewayIP string
 
-	if nil != actuatorConfig {
-		client := apis.GetClientForToken(string(actuatorConfig.token))
+	previousInfraStatus := r.getPreviousInfrastructureStatus()
 
-		if resultData.NetworkID != 0 {
-			networkIDs := &apis.InfrastructureConfigNetworkIDs{
-				Workers: strconv.FormatInt(resultData.NetworkID, 10),
-			}
+	if nil == r.infraConfig.NATGateway && "" != previousInfraStatus.NATGatewayID {
+		err := ensurer.EnsureNATGatewayDeleted(ctx, r.client, r.owner, previousInfraStatus.NATGatewayID, previousInfraStatus.NATGatewayIP, previousInfraStatus.NetworkIDs)
+		if err != nil {
+			return err
+		}
+	}
 
-			_ = ensurer.EnsureNetworksDeleted(ctx, client, infra.Namespace, networkIDs)
+	if nil != r.infraConfig.NATGateway {
+		id, ip, err := ensurer.EnsureNATGateway(ctx, r.client, r.owner, r.infra.Namespace, r.zone, previousInfraStatus.SSHFingerprint, r.infraConfig.Networks, previousInfraStatus.NetworkIDs, r.infraConfig.NATGateway, previousInfraStatus.NATGatewayID, previousInfraStatus.NATGatewayIP)
+		if err != nil {
+			return err
 		}
 
-		if resultData.SSHKeyID != 0 {
-			sshKeyID := strconv.FormatInt(resultData.SSHKeyID, 10)
-			_ = ensurer.EnsureSSHPublicKeyDeleted(ctx, client, sshKeyID)
+		if nil != ip {
+			natGatewayID = strconv.FormatInt(id, 10)
+			natGatewayIP = ip.String()
 		}
 	}
+
+	return r.persistInfrastructureStatus(ctx, func(status *apis.InfrastructureStatus) {
+		status.NATGatewayID = natGatewayID
+		status.NATGatewayIP = natGatewayIP
+	})
+}
+
+// reportNetworkRouteUsage is the flow task recording the route usage of the workers network. Routes for the pod CIDR
+// of each node are added by the cloud-controller-manager unless native routing is used.
+//
+// PARAMETERS
+// ctx context.Context Execution context
+func (r *reconciler) reportNetworkRouteUsage(ctx context.Context) error {
+	previousInfraStatus := r.getPreviousInfrastructureStatus()
+
+	networkRoutes, err := ensurer.GetNetworkRouteUsage(ctx, r.client, previousInfraStatus.NetworkIDs)
+	if err != nil {
+		return err
+	}
+
+	return r.persistInfrastructureStatus(ctx, func(status *apis.InfrastructureStatus) {
+		status.NetworkRoutes = networkRoutes
+	})
 }
@@ -18,9 +18,13 @@ limitations under the License.
 package infrastructure
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/gardener/gardener/extensions/pkg/controller/infrastructure"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/gardener/gardener/pkg/extensions"
 	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
 	mockmanager "github.com/gardener/gardener/third_party/mock/controller-runtime/manager"
@@ -30,11 +34,15 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 	hcloudv1alpha1 "github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/v1alpha1"
 )
 
@@ -54,7 +62,11 @@ var _ = BeforeSuite(func() {
 	mockTestEnv = mock.NewMockTestEnv()
 
 	apis.SetClientForToken("dummy-token", mockTestEnv.HcloudClient)
+	mock.SetupFirewallEndpointOnMux(mockTestEnv.Mux)
+	mock.SetupFirewallsEndpointOnMux(mockTestEnv.Mux)
+	mock.SetupFloatingIPsEndpointOnMux(mockTestEnv.Mux)
 	mock.SetupLocationsEndpointOnMux(mockTestEnv.Mux)
+	mock.SetupNetworkEndpointOnMux(mockTestEnv.Mux)
 	mock.SetupNetworksEndpointOnMux(mockTestEnv.Mux)
 	mock.SetupPlacementGroupsEndpointOnMux(mockTestEnv.Mux)
 	mock.SetupSshKeysEndpointOnMux(mockTestEnv.Mux)
@@ -73,6 +85,7 @@ var _ = BeforeSuite(func() {
 	_ = hcloudv1alpha1.AddToScheme(scheme)
 	mgr.EXPECT().GetScheme().Return(scheme)
 	mgr.EXPECT().GetConfig().Return(config)
+	mgr.EXPECT().GetEventRecorderFor(gomock.Any()).Return(record.NewFakeRecorder(10))
 	infraActuator = NewActuator(mgr, "garden")
 })
 
@@ -91,10 +104,189 @@ var _ = Describe("ActuatorReconcile", func() {
 			})
 
 			mockTestEnv.Client.EXPECT().Status().Return(sw).AnyTimes()
-			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			// One state update per reconciliation task followed by the provider status update
+			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(7)
 
-			err := infraActuator.Reconcile(ctx, logr.Logger{}, mock.NewInfrastructure(), cluster)
+			infra := mock.NewInfrastructure()
+
+			err := infraActuator.Reconcile(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			infraStatus, ok := infra.Status.ProviderStatus.Object.(*hcloudv1alpha1.InfrastructureStatus)
+			Expect(ok).To(BeTrue())
+			Expect(infraStatus.FirewallID).To(Equal("42"))
+			Expect(infraStatus.NetworkRoutes).To(Equal(&hcloudv1alpha1.InfrastructureStatusNetworkRoutes{Used: 2, Limit: apis.NetworkRouteLimit}))
+
+			infraState, err := transcoder.DecodeInfrastructureStateFromInfrastructure(infra)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infraState.ProviderStatus).NotTo(BeNil())
+			Expect(infraState.ProviderStatus.FirewallID).To(Equal("42"))
+		})
+
+		It("should successfully reconcile the floating pool", func() {
+			mockTestEnv.Client.EXPECT().Get(gomock.Any(), k8sclient.ObjectKey{Namespace: mock.TestNamespace, Name: mock.TestInfrastructureSecretName}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(func(_ context.Context, _ k8sclient.ObjectKey, secret *corev1.Secret, _ ...k8sclient.GetOption) error {
+				secret.Data = map[string][]byte{
+					"hcloudToken": []byte("dummy-token"),
+				}
+				return nil
+			})
+
+			mockTestEnv.Client.EXPECT().Status().Return(sw).AnyTimes()
+			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(7)
+
+			infra := mock.NewInfrastructure()
+			infra.Spec.ProviderConfig.Raw = []byte(`{
+				"apiVersion": "hcloud.provider.extensions.gardener.cloud/v1alpha1",
+				"kind": "InfrastructureConfig",
+				"floatingPoolName": "MY-FLOATING-POOL",
+				"floatingIPs": {"count": 1},
+				"networks": {"workers": "10.250.0.0/19"}
+			}`)
+
+			err := infraActuator.Reconcile(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			infraStatus, ok := infra.Status.ProviderStatus.Object.(*hcloudv1alpha1.InfrastructureStatus)
+			Expect(ok).To(BeTrue())
+			Expect(infraStatus.FloatingPoolName).To(Equal(mock.TestFloatingPoolName))
+			Expect(infraStatus.FloatingIPIDs).To(Equal([]string{"43"}))
+		})
+
+		It("should resume from the state persisted by a failed reconciliation", func() {
+			var persistedState *runtime.RawExtension
+
+			mockTestEnv.Client.EXPECT().Get(gomock.Any(), k8sclient.ObjectKey{Namespace: mock.TestNamespace, Name: mock.TestInfrastructureSecretName}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(func(_ context.Context, _ k8sclient.ObjectKey, secret *corev1.Secret, _ ...k8sclient.GetOption) error {
+				secret.Data = map[string][]byte{
+					"hcloudToken": []byte("dummy-token"),
+				}
+				return nil
+			}).Times(2)
+
+			mockTestEnv.Client.EXPECT().Status().Return(sw).AnyTimes()
+			// Fail the state update of the last task after all other tasks persisted their resources
+			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj k8sclient.Object, _ k8sclient.Patch, _ ...k8sclient.SubResourcePatchOption) error {
+				state := obj.(*extensionsv1alpha1.Infrastructure).Status.State
+				if bytes.Contains(state.Raw, []byte(`"networkRoutes"`)) {
+					return errors.New("simulated state update failure")
+				}
+
+				persistedState = state.DeepCopy()
+				return nil
+			}).Times(6)
+
+			infra := mock.NewInfrastructure()
+
+			err := infraActuator.Reconcile(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).To(HaveOccurred())
+
+			infra.Status.State = persistedState
+
+			var firstInfraState *apis.InfrastructureState
+
+			sw.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj k8sclient.Object, _ k8sclient.Patch, _ ...k8sclient.SubResourcePatchOption) error {
+				if nil == firstInfraState {
+					infraState, err := transcoder.DecodeInfrastructureStateFromInfrastructure(obj.(*extensionsv1alpha1.Infrastructure))
+					Expect(err).NotTo(HaveOccurred())
+					firstInfraState = infraState
+				}
+
+				return nil
+			}).Times(7)
+
+			err = infraActuator.Reconcile(ctx, logr.Logger{}, infra, cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The first task of the second run already sees the resources of all tasks of the failed run.
+			Expect(firstInfraState.ProviderStatus).NotTo(BeNil())
+			Expect(firstInfraState.ProviderStatus.SSHFingerprint).NotTo(BeEmpty())
+			Expect(firstInfraState.ProviderStatus.FirewallID).To(Equal("42"))
+			Expect(firstInfraState.ProviderStatus.NetworkIDs).NotTo(BeNil())
+			Expect(firstInfraState.ProviderStatus.NetworkIDs.Workers).To(Equal("42"))
+
+			infraStatus, ok := infra.Status.ProviderStatus.Object.(*hcloudv1alpha1.InfrastructureStatus)
+			Expect(ok).To(BeTrue())
+			Expect(infraStatus.FirewallID).To(Equal("42"))
+			Expect(infraStatus.NetworkIDs.Workers).To(Equal("42"))
+			Expect(infraStatus.NetworkRoutes).To(Equal(&hcloudv1alpha1.InfrastructureStatusNetworkRoutes{Used: 2, Limit: apis.NetworkRouteLimit}))
+		})
+	})
+
+	Describe("#isSSHPublicKeyUsed", func() {
+		newInfrastructure := func(uid, secretName, fingerprint string) extensionsv1alpha1.Infrastructure {
+			infra := mock.NewInfrastructure()
+			infra.UID = types.UID(uid)
+			infra.Spec.Type = hcloud.Type
+			infra.Spec.SecretRef.Name = secretName
+			infra.Status.ProviderStatus = &runtime.RawExtension{
+				Raw: []byte(fmt.Sprintf(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureStatus","sshFingerprint":%q}`, fingerprint)),
+			}
+
+			return *infra
+		}
+
+		expectInfrastructures := func(secretName, token string) {
+			mockTestEnv.Client.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&extensionsv1alpha1.InfrastructureList{})).DoAndReturn(func(_ context.Context, infraList *extensionsv1alpha1.InfrastructureList, _ ...k8sclient.ListOption) error {
+				infraList.Items = []extensionsv1alpha1.Infrastructure{
+					newInfrastructure("self", "self", "dummy"),
+					newInfrastructure("other", secretName, "dummy"),
+				}
+
+				return nil
+			})
+
+			mockTestEnv.Client.EXPECT().Get(gomock.Any(), k8sclient.ObjectKey{Namespace: mock.TestNamespace, Name: secretName}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(func(_ context.Context, _ k8sclient.ObjectKey, secret *corev1.Secret, _ ...k8sclient.GetOption) error {
+				secret.Data = map[string][]byte{
+					"hcloudToken": []byte(token),
+				}
+
+				return nil
+			})
+		}
+
+		It("should ignore infrastructures of other HCloud projects", func() {
+			expectInfrastructures("other-project", "other-token")
+			infra := newInfrastructure("self", "self", "dummy")
+
+			used, err := infraActuator.(*actuator).isSSHPublicKeyUsed(&infra, "dummy-token")(ctx, "dummy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(used).To(BeFalse())
+		})
+
+		It("should detect infrastructures of the same HCloud project", func() {
+			expectInfrastructures("same-project", "dummy-token")
+			infra := newInfrastructure("self", "self", "dummy")
+
+			used, err := infraActuator.(*actuator).isSSHPublicKeyUsed(&infra, "dummy-token")(ctx, "dummy")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(used).To(BeTrue())
+		})
+	})
+
+	Describe("#getInfrastructureStatus", func() {
+		It("should prefer the state persisted by an unfinished reconciliation", func() {
+			infra := mock.NewInfrastructure()
+			infra.Status.ProviderStatus = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureStatus","sshFingerprint":"dummy"}`),
+			}
+			infra.Status.State = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureState","providerStatus":{"sshFingerprint":"dummy","firewallID":"42","networkIDs":{"workers":"42"}}}`),
+			}
+
+			infraStatus, err := infraActuator.(*actuator).getInfrastructureStatus(infra)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(infraStatus.FirewallID).To(Equal("42"))
+			Expect(infraStatus.NetworkIDs.Workers).To(Equal("42"))
+		})
+
+		It("should fall back to the provider status", func() {
+			infra := mock.NewInfrastructure()
+			infra.Status.ProviderStatus = &runtime.RawExtension{
+				Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureStatus","sshFingerprint":"dummy","firewallID":"42"}`),
+			}
+
+			infraStatus, err := infraActuator.(*actuator).getInfrastructureStatus(infra)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(infraStatus.FirewallID).To(Equal("42"))
 		})
 	})
 })
@@ -0,0 +1,375 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+const (
+	// defaultNodePortRange is the Kubernetes default service node port range used if the shoot does not configure one.
+	defaultNodePortRange = "30000-32767"
+	// allPortsRange is the port range matching all ports for TCP and UDP rules.
+	allPortsRange = "1-65535"
+	// firewallRole is the role label value of firewalls created.
+	firewallRole = "infrastructure-firewall-v1"
+)
+
+// EnsureFirewall verifies that the firewall applied to the worker nodes is available and has the expected rules.
+//
+// PARAMETERS
+// ctx        context.Context                    Execution context
+// client     *hcloud.Client                     HCloud client
+// owner      *controller.ResourceOwner          Resource owner
+// cluster    *extensionscontroller.Cluster      Cluster struct
+// namespace  string                             Shoot namespace
+// networks   *apis.InfrastructureConfigNetworks Networks struct
+// firewall   *apis.InfrastructureConfigFirewall Firewall struct
+// firewallID string                             Firewall ID of the previous reconciliation
+func EnsureFirewall(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, cluster *extensionscontroller.Cluster, namespace string, networks *apis.InfrastructureConfigNetworks, firewall *apis.InfrastructureConfigFirewall, firewallID string) (int64, error) {
+	rules, err := getFirewallRules(cluster, networks, firewall)
+	if nil != err {
+		return -1, err
+	}
+
+	name := fmt.Sprintf("%s-workers", namespace)
+
+	resource := hcloud.FirewallResource{
+		Type: hcloud.FirewallResourceTypeLabelSelector,
+		LabelSelector: &hcloud.FirewallResourceLabelSelector{
+			Selector: fmt.Sprintf("mcm.gardener.cloud/cluster=%s,mcm.gardener.cloud/role=node", namespace),
+		},
+	}
+
+	var hcloudFirewall *hcloud.Firewall
+
+	if "" != firewallID {
+		id, err := strconv.ParseInt(firewallID, 10, 64)
+		if nil != err {
+			return -1, err
+		}
+
+		hcloudFirewall, _, err = client.Firewall.GetByID(ctx, id)
+		if nil != err {
+			return -1, err
+		}
+	}
+
+	if hcloudFirewall == nil {
+		hcloudFirewall, _, err = client.Firewall.GetByName(ctx, name)
+		if nil != err {
+			return -1, err
+		}
+	}
+
+	if hcloudFirewall != nil && !owner.IsOwnerOf(hcloudFirewall.Labels, firewallRole) {
+		return -1, fmt.Errorf("Firewall %q (%d) is not owned by this shoot", hcloudFirewall.Name, hcloudFirewall.ID)
+	}
+
+	if hcloudFirewall == nil {
+		labels := owner.Labels(firewallRole)
+
+		opts := hcloud.FirewallCreateOpts{
+			Name:    name,
+			Labels:  labels,
+			Rules:   rules,
+			ApplyTo: []hcloud.FirewallResource{resource},
+		}
+
+		result, _, err := client.Firewall.Create(ctx, opts)
+		if nil != err {
+			return -1, err
+		}
+
+		return result.Firewall.ID, nil
+	}
+
+	_, _, err = client.Firewall.SetRules(ctx, hcloudFirewall, hcloud.FirewallSetRulesOpts{Rules: rules})
+	if nil != err {
+		return -1, err
+	}
+
+	if labels, changed := owner.MergeLabels(hcloudFirewall.Labels, firewallRole); changed {
+		_, _, err = client.Firewall.Update(ctx, hcloudFirewall, hcloud.FirewallUpdateOpts{Labels: labels})
+		if nil != err {
+			return -1, err
+		}
+	}
+
+	for _, appliedTo := range hcloudFirewall.AppliedTo {
+		if appliedTo.Type == resource.Type && appliedTo.LabelSelector != nil && appliedTo.LabelSelector.Selector == resource.LabelSelector.Selector {
+			return hcloudFirewall.ID, nil
+		}
+	}
+
+	_, _, err = client.Firewall.ApplyResources(ctx, hcloudFirewall, []hcloud.FirewallResource{resource})
+	if nil != err {
+		return -1, err
+	}
+
+	return hcloudFirewall.ID, nil
+}
+
+// EnsureFirewallDeleted removes any previously created firewall identified by the given ID.
+//
+// PARAMETERS
+// ctx        context.Context           Execution context
+// client     *hcloud.Client            HCloud client
+// owner      *controller.ResourceOwner Resource owner
+// firewallID string                    Firewall ID
+func EnsureFirewallDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, firewallID string) error {
+	if "" != firewallID {
+		id, err := strconv.ParseInt(firewallID, 10, 64)
+		if nil != err {
+			return err
+		}
+
+		firewall, _, err := client.Firewall.GetByID(ctx, id)
+		if nil != err {
+			return err
+		} else if firewall != nil {
+			if !owner.IsOwnerOf(firewall.Labels, firewallRole) {
+				owner.RefuseDeletion("firewall", firewall.Name, firewall.ID)
+				return nil
+			}
+
+			if len(firewall.AppliedTo) > 0 {
+				actions, _, err := client.Firewall.RemoveResources(ctx, firewall, firewall.AppliedTo)
+				if nil != err {
+					return err
+				}
+
+				err = client.Action.WaitFor(ctx, actions...)
+				if nil != err {
+					return err
+				}
+			}
+
+			_, err = client.Firewall.Delete(ctx, firewall)
+			if nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getNodePortRange returns the service node port range of the kube-apiserver configured in the firewall struct given.
+//
+// PARAMETERS
+// firewall *apis.InfrastructureConfigFirewall Firewall struct
+func getNodePortRange(firewall *apis.InfrastructureConfigFirewall) (string, error) {
+	if nil == firewall || "" == firewall.NodePortRange {
+		return defaultNodePortRange, nil
+	}
+
+	portRange, err := utilnet.ParsePortRange(firewall.NodePortRange)
+	if nil != err {
+		return "", err
+	}
+
+	if portRange.Size < 2 {
+		return strconv.Itoa(portRange.Base), nil
+	}
+
+	return fmt.Sprintf("%d-%d", portRange.Base, portRange.Base+portRange.Size-1), nil
+}
+
+// getFirewallRules returns the generated default rules followed by the rules configured.
+//
+// Hetzner firewalls only filter traffic of public interfaces. Traffic within the private network is not affected,
+// the cluster internal rules allow nodes and pods to reach each other over public addresses, e.g. for IPv6 shoots
+// or shoots without a private network.
+//
+// PARAMETERS
+// cluster  *extensionscontroller.Cluster      Cluster struct
+// networks *apis.InfrastructureConfigNetworks Networks struct
+// firewall *apis.InfrastructureConfigFirewall Firewall struct
+func getFirewallRules(cluster *extensionscontroller.Cluster, networks *apis.InfrastructureConfigNetworks, firewall *apis.InfrastructureConfigFirewall) ([]hcloud.FirewallRule, error) {
+	var internalCidrs []string
+
+	nodePortRange, err := getNodePortRange(firewall)
+	if nil != err {
+		return nil, err
+	}
+
+	if nil != networks {
+		if nil != networks.WorkersConfiguration {
+			internalCidrs = append(internalCidrs, networks.WorkersConfiguration.Cidr)
+		} else if "" != networks.Workers {
+			internalCidrs = append(internalCidrs, networks.Workers)
+		}
+
+		// Shoots joining a shared network communicate privately with all other shoots using it.
+		if nil != networks.Shared {
+			internalCidrs = append(internalCidrs, networks.Shared.Cidr)
+		}
+	}
+
+	if nil != cluster.Shoot.Spec.Networking {
+		if nil != cluster.Shoot.Spec.Networking.Nodes {
+			internalCidrs = append(internalCidrs, *cluster.Shoot.Spec.Networking.Nodes)
+		}
+
+		if nil != cluster.Shoot.Spec.Networking.Pods {
+			internalCidrs = append(internalCidrs, *cluster.Shoot.Spec.Networking.Pods)
+		}
+	}
+
+	// IPv6 and dual-stack shoots report the ranges of all IP families in the status only.
+	if nil != cluster.Shoot.Status.Networking {
+		internalCidrs = append(internalCidrs, cluster.Shoot.Status.Networking.Nodes...)
+		internalCidrs = append(internalCidrs, cluster.Shoot.Status.Networking.Pods...)
+	}
+
+	anyIPs, err := parseCidrs([]string{"0.0.0.0/0", "::/0"})
+	if nil != err {
+		return nil, err
+	}
+
+	rules := []hcloud.FirewallRule{
+		{
+			Direction:   hcloud.FirewallRuleDirectionIn,
+			Protocol:    hcloud.FirewallRuleProtocolICMP,
+			SourceIPs:   anyIPs,
+			Description: hcloud.Ptr("gardener: ICMP"),
+		},
+		{
+			Direction:   hcloud.FirewallRuleDirectionIn,
+			Protocol:    hcloud.FirewallRuleProtocolTCP,
+			SourceIPs:   anyIPs,
+			Port:        hcloud.Ptr(nodePortRange),
+			Description: hcloud.Ptr("gardener: TCP node ports"),
+		},
+		{
+			Direction:   hcloud.FirewallRuleDirectionIn,
+			Protocol:    hcloud.FirewallRuleProtocolUDP,
+			SourceIPs:   anyIPs,
+			Port:        hcloud.Ptr(nodePortRange),
+			Description: hcloud.Ptr("gardener: UDP node ports"),
+		},
+	}
+
+	if len(internalCidrs) > 0 {
+		internalIPs, err := parseCidrs(getUniqueCidrs(internalCidrs))
+		if nil != err {
+			return nil, err
+		}
+
+		rules = append(rules,
+			hcloud.FirewallRule{
+				Direction:   hcloud.FirewallRuleDirectionIn,
+				Protocol:    hcloud.FirewallRuleProtocolTCP,
+				SourceIPs:   internalIPs,
+				Port:        hcloud.Ptr(allPortsRange),
+				Description: hcloud.Ptr("gardener: TCP cluster internal"),
+			},
+			hcloud.FirewallRule{
+				Direction:   hcloud.FirewallRuleDirectionIn,
+				Protocol:    hcloud.FirewallRuleProtocolUDP,
+				SourceIPs:   internalIPs,
+				Port:        hcloud.Ptr(allPortsRange),
+				Description: hcloud.Ptr("gardener: UDP cluster internal"),
+			},
+		)
+	}
+
+	if nil != firewall {
+		for _, rule := range firewall.Rules {
+			hcloudRule := hcloud.FirewallRule{
+				Direction: rule.Direction,
+				Protocol:  rule.Protocol,
+			}
+
+			if "" != rule.Port {
+				hcloudRule.Port = hcloud.Ptr(rule.Port)
+			}
+
+			if "" != rule.Description {
+				hcloudRule.Description = hcloud.Ptr(rule.Description)
+			}
+
+			hcloudRule.SourceIPs, err = parseCidrs(rule.SourceIPs)
+			if nil != err {
+				return nil, err
+			}
+
+			hcloudRule.DestinationIPs, err = parseCidrs(rule.DestinationIPs)
+			if nil != err {
+				return nil, err
+			}
+
+			rules = append(rules, hcloudRule)
+		}
+	}
+
+	return rules, nil
+}
+
+// getUniqueCidrs returns the given CIDRs without duplicates. IPv4 shoots report the node and pod ranges of the spec
+// in the status as well and the nodes range usually equals the workers CIDR.
+//
+// PARAMETERS
+// cidrs []string CIDRs
+func getUniqueCidrs(cidrs []string) []string {
+	var result []string
+	known := map[string]bool{}
+
+	for _, cidr := range cidrs {
+		if !known[cidr] {
+			known[cidr] = true
+			result = append(result, cidr)
+		}
+	}
+
+	return result
+}
+
+// parseCidrs returns the list of networks for the given, de-duplicated CIDR strings.
+//
+// PARAMETERS
+// cidrs []string CIDRs to parse
+func parseCidrs(cidrs []string) ([]net.IPNet, error) {
+	var result []net.IPNet
+	known := map[string]bool{}
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if nil != err {
+			return nil, err
+		}
+
+		if !known[ipNet.String()] {
+			known[ipNet.String()] = true
+			result = append(result, *ipNet)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"net"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+)
+
+func getRuleByDescription(rules []hcloud.FirewallRule, description string) *hcloud.FirewallRule {
+	for _, rule := range rules {
+		if nil != rule.Description && *rule.Description == description {
+			return &rule
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("Firewall", func() {
+	var cluster *extensionscontroller.Cluster
+
+	BeforeEach(func() {
+		cluster = &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}}
+	})
+
+	Describe("#getFirewallRules", func() {
+		It("should open the Kubernetes default node port range", func() {
+			rules, err := getFirewallRules(cluster, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rule := getRuleByDescription(rules, "gardener: TCP node ports")
+			Expect(rule).NotTo(BeNil())
+			Expect(*rule.Port).To(Equal(defaultNodePortRange))
+		})
+
+		It("should open the node port range configured", func() {
+			firewall := &apis.InfrastructureConfigFirewall{NodePortRange: "20000+1000"}
+
+			rules, err := getFirewallRules(cluster, nil, firewall)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, description := range []string{"gardener: TCP node ports", "gardener: UDP node ports"} {
+				rule := getRuleByDescription(rules, description)
+				Expect(rule).NotTo(BeNil())
+				Expect(*rule.Port).To(Equal("20000-21000"))
+			}
+		})
+
+		It("should fail for an invalid node port range", func() {
+			_, err := getFirewallRules(cluster, nil, &apis.InfrastructureConfigFirewall{NodePortRange: "invalid"})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should allow cluster internal traffic from the workers and pods ranges", func() {
+			cluster.Shoot.Spec.Networking = &gardencorev1beta1.Networking{Pods: hcloud.Ptr("100.96.0.0/11")}
+			networks := &apis.InfrastructureConfigNetworks{Workers: "10.250.0.0/19"}
+
+			rules, err := getFirewallRules(cluster, networks, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rule := getRuleByDescription(rules, "gardener: TCP cluster internal")
+			Expect(rule).NotTo(BeNil())
+			Expect(toStrings([]*net.IPNet{&rule.SourceIPs[0], &rule.SourceIPs[1]})).To(Equal([]string{"10.250.0.0/19", "100.96.0.0/11"}))
+		})
+
+		It("should only allow each cluster internal range once", func() {
+			cluster.Shoot.Spec.Networking = &gardencorev1beta1.Networking{Nodes: hcloud.Ptr("10.250.0.0/19"), Pods: hcloud.Ptr("100.96.0.0/11")}
+			cluster.Shoot.Status.Networking = &gardencorev1beta1.NetworkingStatus{
+				Nodes: []string{"10.250.0.0/19", "2001:db8::/64"},
+				Pods:  []string{"100.96.0.0/11"},
+			}
+			networks := &apis.InfrastructureConfigNetworks{Workers: "10.250.0.0/19"}
+
+			rules, err := getFirewallRules(cluster, networks, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, description := range []string{"gardener: TCP cluster internal", "gardener: UDP cluster internal"} {
+				rule := getRuleByDescription(rules, description)
+				Expect(rule).NotTo(BeNil())
+				Expect(rule.SourceIPs).To(HaveLen(3))
+				Expect(toStrings([]*net.IPNet{&rule.SourceIPs[0], &rule.SourceIPs[1], &rule.SourceIPs[2]})).To(Equal([]string{"10.250.0.0/19", "100.96.0.0/11", "2001:db8::/64"}))
+			}
+		})
+	})
+})
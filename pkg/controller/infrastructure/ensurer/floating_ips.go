@@ -0,0 +1,227 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+const (
+	// floatingIPRole is the role label value of floating IPs created.
+	floatingIPRole = "infrastructure-floating-ip-v1"
+	// labelFloatingPool is the label containing the floating pool name of a floating IP.
+	labelFloatingPool = "hcloud.provider.extensions.gardener.cloud/floating-pool"
+)
+
+// EnsureFloatingIPs verifies that the configured number of floating IPs is available in the floating pool.
+// Floating IPs of a different IP family or exceeding the configured number are released.
+//
+// PARAMETERS
+// ctx         context.Context                       Execution context
+// client      *hcloud.Client                        HCloud client
+// owner       *controller.ResourceOwner             Resource owner
+// namespace   string                                Shoot namespace
+// zone        string                                Shoot zone
+// poolName    string                                Floating pool name
+// floatingIPs *apis.InfrastructureConfigFloatingIPs Floating IPs struct
+func EnsureFloatingIPs(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace, zone, poolName string, floatingIPs *apis.InfrastructureConfigFloatingIPs) ([]int64, error) {
+	count := 0
+	ipType := hcloud.FloatingIPTypeIPv4
+
+	if nil != floatingIPs {
+		count = floatingIPs.Count
+
+		if "" != floatingIPs.IPFamily {
+			ipType = floatingIPs.IPFamily
+		}
+	}
+
+	existingIPs, err := getFloatingIPs(ctx, client, owner, namespace)
+	if nil != err {
+		return nil, err
+	}
+
+	// Floating IPs assigned to a server are kept in favor of unassigned ones to keep addresses in use stable.
+	slices.SortStableFunc(existingIPs, func(a, b *hcloud.FloatingIP) int {
+		if (nil == a.Server) != (nil == b.Server) {
+			if nil != a.Server {
+				return -1
+			}
+
+			return 1
+		}
+
+		return 0
+	})
+
+	var (
+		ids      []int64
+		usedName = map[string]bool{}
+	)
+
+	for _, floatingIP := range existingIPs {
+		if floatingIP.Type != ipType || len(ids) >= count {
+			_, err = client.FloatingIP.Delete(ctx, floatingIP)
+			if nil != err && !hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+				return nil, err
+			}
+
+			continue
+		}
+
+		labels, changed := owner.MergeLabels(floatingIP.Labels, floatingIPRole)
+
+		if labels[labelFloatingPool] != poolName {
+			labels[labelFloatingPool] = poolName
+			changed = true
+		}
+
+		if changed {
+			_, _, err = client.FloatingIP.Update(ctx, floatingIP, hcloud.FloatingIPUpdateOpts{Labels: labels})
+			if nil != err {
+				return nil, err
+			}
+		}
+
+		ids = append(ids, floatingIP.ID)
+		usedName[floatingIP.Name] = true
+	}
+
+	for index := 0; len(ids) < count; index++ {
+		name := getFloatingIPName(namespace, index)
+		if usedName[name] {
+			continue
+		}
+
+		labels := owner.Labels(floatingIPRole)
+		labels[labelFloatingPool] = poolName
+
+		opts := hcloud.FloatingIPCreateOpts{
+			Type:         ipType,
+			HomeLocation: &hcloud.Location{Name: apis.GetLocationFromZone(zone)},
+			Name:         hcloud.Ptr(name),
+			Description:  hcloud.Ptr(fmt.Sprintf("Floating pool %s of %s", poolName, namespace)),
+			Labels:       labels,
+		}
+
+		result, _, err := client.FloatingIP.Create(ctx, opts)
+		if nil != err {
+			return nil, err
+		}
+
+		ids = append(ids, result.FloatingIP.ID)
+		usedName[name] = true
+	}
+
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// EnsureFloatingIPsDeleted removes any previously created floating IPs identified by the given IDs.
+//
+// PARAMETERS
+// ctx           context.Context           Execution context
+// client        *hcloud.Client            HCloud client
+// owner         *controller.ResourceOwner Resource owner
+// floatingIPIDs []string                  Floating IP IDs
+func EnsureFloatingIPsDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, floatingIPIDs []string) error {
+	for _, floatingIPID := range floatingIPIDs {
+		id, err := strconv.ParseInt(floatingIPID, 10, 64)
+		if nil != err {
+			return err
+		}
+
+		floatingIP, _, err := client.FloatingIP.GetByID(ctx, id)
+		if nil != err {
+			return err
+		} else if nil == floatingIP {
+			continue
+		}
+
+		if !owner.IsOwnerOf(floatingIP.Labels, floatingIPRole) {
+			owner.RefuseDeletion("floating IP", floatingIP.Name, floatingIP.ID)
+			continue
+		}
+
+		_, err = client.FloatingIP.Delete(ctx, floatingIP)
+		if nil != err && !hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getFloatingIPName returns the name of the floating IP with the given index.
+//
+// PARAMETERS
+// namespace string Shoot namespace
+// index     int    Floating IP index
+func getFloatingIPName(namespace string, index int) string {
+	return fmt.Sprintf("%s-floating-ip-%d", namespace, index)
+}
+
+// getFloatingIPs returns all floating IPs of the floating pool owned by the shoot.
+//
+// PARAMETERS
+// ctx       context.Context           Execution context
+// client    *hcloud.Client            HCloud client
+// owner     *controller.ResourceOwner Resource owner
+// namespace string                    Shoot namespace
+func getFloatingIPs(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string) ([]*hcloud.FloatingIP, error) {
+	labelSelector := fmt.Sprintf("%s=%s", controller.LabelRole, floatingIPRole)
+
+	if "" != owner.ClusterID {
+		labelSelector = fmt.Sprintf("%s,%s=%s", labelSelector, controller.LabelClusterID, owner.ClusterID)
+	}
+
+	floatingIPs, err := client.FloatingIP.AllWithOpts(ctx, hcloud.FloatingIPListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: labelSelector},
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	namePrefix := getFloatingIPName(namespace, 0)
+	namePrefix = namePrefix[:len(namePrefix)-1]
+
+	var result []*hcloud.FloatingIP
+
+	for _, floatingIP := range floatingIPs {
+		if strings.HasPrefix(floatingIP.Name, namePrefix) && owner.IsOwnerOf(floatingIP.Labels, floatingIPRole) {
+			result = append(result, floatingIP)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *hcloud.FloatingIP) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result, nil
+}
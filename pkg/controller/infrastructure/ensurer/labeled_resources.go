@@ -0,0 +1,564 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+const (
+	// labelMCMCluster is the label containing the shoot namespace of servers created by the machine controller manager.
+	labelMCMCluster = "mcm.gardener.cloud/cluster"
+	// labelCCMServiceUID is the label containing the service UID of load balancers created by the cloud controller manager.
+	labelCCMServiceUID = "hcloud-ccm/service-uid"
+)
+
+// EnsureLabeledResourcesDeleted removes all HCloud resources of the shoot. Resources are selected by the cluster ID
+// label, servers additionally by the label of the machine controller manager. Volumes and load balancers of the
+// cloud controller manager and CSI driver are selected by the servers and the workers network they are attached to.
+// Subnets and routes added to existing or shared networks are removed as well.
+//
+// Resources are deleted in dependency order. Failures are collected and returned after all resource types
+// have been processed to remove as much as possible in a single run.
+//
+// PARAMETERS
+// ctx        context.Context                      Execution context
+// client     *hcloud.Client                       HCloud client
+// log        logr.Logger                          Logger used to report deleted resources
+// owner      *controller.ResourceOwner            Resource owner the resources are labeled with
+// namespace  string                               Shoot namespace
+// networkIDs *apis.InfrastructureConfigNetworkIDs Network IDs struct of the last reconciliation if known
+// isUsed     IsSSHPublicKeyUsedFunc               Function checking if a SSH public key is used by another shoot
+func EnsureLabeledResourcesDeleted(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, namespace string, networkIDs *apis.InfrastructureConfigNetworkIDs, isUsed IsSSHPublicKeyUsedFunc) error {
+	if "" == owner.ClusterID {
+		return fmt.Errorf("Cluster ID is required to delete labeled resources")
+	}
+
+	listOpts := hcloud.ListOpts{
+		LabelSelector: fmt.Sprintf("%s=%s", controller.LabelClusterID, owner.ClusterID),
+	}
+
+	servers, err := getShootServers(ctx, client, owner, namespace, networkIDs, listOpts)
+	if nil != err {
+		return err
+	}
+
+	return errors.Join(
+		deleteLabeledLoadBalancers(ctx, client, log, owner, servers, networkIDs, listOpts),
+		deleteLabeledVolumes(ctx, client, log, owner, servers, listOpts),
+		deleteLabeledServers(ctx, client, log, owner, servers),
+		deleteLabeledFloatingIPs(ctx, client, log, owner, listOpts),
+		deleteLabeledPrimaryIPs(ctx, client, log, owner, listOpts),
+		deleteLabeledFirewalls(ctx, client, log, owner, listOpts),
+		deleteLabeledPlacementGroups(ctx, client, log, owner, listOpts),
+		deleteLabeledNetworks(ctx, client, log, owner, listOpts),
+		releaseUnownedNetwork(ctx, client, owner, networkIDs),
+		deleteLabeledSSHKeys(ctx, client, log, owner, listOpts, isUsed),
+	)
+}
+
+// deleteLabeledResource calls the given delete function and reports the result.
+//
+// PARAMETERS
+// log        logr.Logger               Logger used to report deleted resources
+// owner      *controller.ResourceOwner Resource owner
+// kind       string                    Resource kind
+// id         int64                     Resource ID
+// name       string                    Resource name
+// isOwned    bool                      True if the resource is owned by the shoot
+// deleteFunc func() error              Function deleting the resource
+func deleteLabeledResource(log logr.Logger, owner *controller.ResourceOwner, kind string, id int64, name string, isOwned bool, deleteFunc func() error) error {
+	if !isOwned {
+		owner.RefuseDeletion(kind, name, id)
+		return nil
+	}
+
+	err := deleteFunc()
+	if nil != err {
+		if hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("Failed to delete %s %q (%d): %w", kind, name, id, err)
+	}
+
+	log.Info("Deleted labeled resource", "kind", kind, "id", id, "name", name)
+
+	return nil
+}
+
+// hasForeignOwner returns true if the given labels identify a HCloud resource as owned by another shoot or garden.
+//
+// PARAMETERS
+// owner  *controller.ResourceOwner Resource owner
+// labels map[string]string         HCloud resource labels
+func hasForeignOwner(owner *controller.ResourceOwner, labels map[string]string) bool {
+	if clusterID, ok := labels[controller.LabelClusterID]; ok && clusterID != owner.ClusterID {
+		return true
+	}
+
+	if gardenID, ok := labels[controller.LabelGardenID]; ok && gardenID != owner.GardenID {
+		return true
+	}
+
+	return false
+}
+
+// getShootServers returns all servers of the shoot. Servers created by the machine controller manager before they
+// have been labeled with the cluster ID are only returned if attached to the workers network of the shoot.
+//
+// PARAMETERS
+// ctx        context.Context                      Execution context
+// client     *hcloud.Client                       HCloud client
+// owner      *controller.ResourceOwner            Resource owner
+// namespace  string                               Shoot namespace
+// networkIDs *apis.InfrastructureConfigNetworkIDs Network IDs struct of the last reconciliation if known
+// listOpts   hcloud.ListOpts                      List options containing the label selector
+func getShootServers(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string, networkIDs *apis.InfrastructureConfigNetworkIDs, listOpts hcloud.ListOpts) ([]*hcloud.Server, error) {
+	servers, err := client.Server.AllWithOpts(ctx, hcloud.ServerListOpts{ListOpts: listOpts})
+	if nil != err {
+		return nil, err
+	}
+
+	if "" == namespace || nil == networkIDs || "" == networkIDs.Workers {
+		return servers, nil
+	}
+
+	machineServers, err := client.Server.AllWithOpts(ctx, hcloud.ServerListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: fmt.Sprintf("%s=%s", labelMCMCluster, namespace)},
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	known := map[int64]bool{}
+
+	for _, server := range servers {
+		known[server.ID] = true
+	}
+
+	for _, server := range machineServers {
+		if known[server.ID] {
+			continue
+		}
+
+		for _, privateNet := range server.PrivateNet {
+			if nil != privateNet.Network && strconv.FormatInt(privateNet.Network.ID, 10) == networkIDs.Workers {
+				known[server.ID] = true
+				servers = append(servers, server)
+
+				break
+			}
+		}
+	}
+
+	return servers, nil
+}
+
+// deleteLabeledServers removes all servers given.
+//
+// PARAMETERS
+// ctx     context.Context           Execution context
+// client  *hcloud.Client            HCloud client
+// log     logr.Logger               Logger used to report deleted resources
+// owner   *controller.ResourceOwner Resource owner
+// servers []*hcloud.Server          Servers of the shoot
+func deleteLabeledServers(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, servers []*hcloud.Server) error {
+	var errs []error
+
+	for _, server := range servers {
+		errs = append(errs, deleteLabeledResource(log, owner, "server", server.ID, server.Name, !hasForeignOwner(owner, server.Labels), func() error {
+			result, _, err := client.Server.DeleteWithResult(ctx, server)
+			if nil != err {
+				return err
+			}
+
+			return client.Action.WaitFor(ctx, result.Action)
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledLoadBalancers removes all load balancers matching the given list options as well as the ones of the
+// cloud controller manager attached to the servers or the workers network owned by the shoot.
+//
+// PARAMETERS
+// ctx        context.Context                      Execution context
+// client     *hcloud.Client                       HCloud client
+// log        logr.Logger                          Logger used to report deleted resources
+// owner      *controller.ResourceOwner            Resource owner
+// servers    []*hcloud.Server                     Servers of the shoot
+// networkIDs *apis.InfrastructureConfigNetworkIDs Network IDs struct of the last reconciliation if known
+// listOpts   hcloud.ListOpts                      List options containing the label selector
+func deleteLabeledLoadBalancers(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, servers []*hcloud.Server, networkIDs *apis.InfrastructureConfigNetworkIDs, listOpts hcloud.ListOpts) error {
+	loadBalancers, err := client.LoadBalancer.AllWithOpts(ctx, hcloud.LoadBalancerListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	serviceLoadBalancers, err := client.LoadBalancer.AllWithOpts(ctx, hcloud.LoadBalancerListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: labelCCMServiceUID},
+	})
+	if nil != err {
+		return err
+	}
+
+	serverLoadBalancerIDs := map[int64]bool{}
+
+	for _, server := range servers {
+		for _, loadBalancer := range server.LoadBalancers {
+			serverLoadBalancerIDs[loadBalancer.ID] = true
+		}
+	}
+
+	var workersNetworkID string
+
+	if nil != networkIDs && !networkIDs.WorkersExisting && !networkIDs.WorkersShared {
+		workersNetworkID = networkIDs.Workers
+	}
+
+	known := map[int64]bool{}
+
+	for _, loadBalancer := range loadBalancers {
+		known[loadBalancer.ID] = true
+	}
+
+	for _, loadBalancer := range serviceLoadBalancers {
+		if known[loadBalancer.ID] {
+			continue
+		}
+
+		isAttached := serverLoadBalancerIDs[loadBalancer.ID]
+
+		for _, privateNet := range loadBalancer.PrivateNet {
+			if nil != privateNet.Network && "" != workersNetworkID && strconv.FormatInt(privateNet.Network.ID, 10) == workersNetworkID {
+				isAttached = true
+			}
+		}
+
+		if isAttached {
+			known[loadBalancer.ID] = true
+			loadBalancers = append(loadBalancers, loadBalancer)
+		}
+	}
+
+	var errs []error
+
+	for _, loadBalancer := range loadBalancers {
+		errs = append(errs, deleteLabeledResource(log, owner, "load balancer", loadBalancer.ID, loadBalancer.Name, !hasForeignOwner(owner, loadBalancer.Labels), func() error {
+			_, err := client.LoadBalancer.Delete(ctx, loadBalancer)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledFloatingIPs removes all floating IPs matching the given list options.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// listOpts hcloud.ListOpts           List options containing the label selector
+func deleteLabeledFloatingIPs(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, listOpts hcloud.ListOpts) error {
+	floatingIPs, err := client.FloatingIP.AllWithOpts(ctx, hcloud.FloatingIPListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	var errs []error
+
+	for _, floatingIP := range floatingIPs {
+		errs = append(errs, deleteLabeledResource(log, owner, "floating IP", floatingIP.ID, floatingIP.Name, owner.IsOwnerOf(floatingIP.Labels, ""), func() error {
+			_, err := client.FloatingIP.Delete(ctx, floatingIP)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledPrimaryIPs removes all unassigned primary IPs matching the given list options.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// listOpts hcloud.ListOpts           List options containing the label selector
+func deleteLabeledPrimaryIPs(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, listOpts hcloud.ListOpts) error {
+	primaryIPs, err := client.PrimaryIP.AllWithOpts(ctx, hcloud.PrimaryIPListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	var errs []error
+
+	for _, primaryIP := range primaryIPs {
+		if 0 != primaryIP.AssigneeID {
+			errs = append(errs, fmt.Errorf("Failed to delete primary IP %q (%d): still assigned to %d", primaryIP.Name, primaryIP.ID, primaryIP.AssigneeID))
+			continue
+		}
+
+		errs = append(errs, deleteLabeledResource(log, owner, "primary IP", primaryIP.ID, primaryIP.Name, owner.IsOwnerOf(primaryIP.Labels, ""), func() error {
+			_, err := client.PrimaryIP.Delete(ctx, primaryIP)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledVolumes detaches and removes all volumes matching the given list options or attached to the servers
+// of the shoot.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// servers  []*hcloud.Server          Servers of the shoot
+// listOpts hcloud.ListOpts           List options containing the label selector
+func deleteLabeledVolumes(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, servers []*hcloud.Server, listOpts hcloud.ListOpts) error {
+	volumes, err := client.Volume.AllWithOpts(ctx, hcloud.VolumeListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	known := map[int64]bool{}
+
+	for _, volume := range volumes {
+		known[volume.ID] = true
+	}
+
+	for _, server := range servers {
+		for _, serverVolume := range server.Volumes {
+			if known[serverVolume.ID] {
+				continue
+			}
+
+			volume, _, err := client.Volume.GetByID(ctx, serverVolume.ID)
+			if nil != err {
+				return err
+			} else if volume == nil {
+				continue
+			}
+
+			known[volume.ID] = true
+			volumes = append(volumes, volume)
+		}
+	}
+
+	var errs []error
+
+	for _, volume := range volumes {
+		errs = append(errs, deleteLabeledResource(log, owner, "volume", volume.ID, volume.Name, !hasForeignOwner(owner, volume.Labels), func() error {
+			if nil != volume.Server {
+				action, _, err := client.Volume.Detach(ctx, volume)
+				if nil != err {
+					return err
+				}
+
+				err = client.Action.WaitFor(ctx, action)
+				if nil != err {
+					return err
+				}
+			}
+
+			_, err := client.Volume.Delete(ctx, volume)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledFirewalls removes all firewalls matching the given list options.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// listOpts hcloud.ListOpts           List options containing the label selector
+func deleteLabeledFirewalls(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, listOpts hcloud.ListOpts) error {
+	firewalls, err := client.Firewall.AllWithOpts(ctx, hcloud.FirewallListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	var errs []error
+
+	for _, firewall := range firewalls {
+		errs = append(errs, deleteLabeledResource(log, owner, "firewall", firewall.ID, firewall.Name, owner.IsOwnerOf(firewall.Labels, ""), func() error {
+			if len(firewall.AppliedTo) > 0 {
+				actions, _, err := client.Firewall.RemoveResources(ctx, firewall, firewall.AppliedTo)
+				if nil != err {
+					return err
+				}
+
+				err = client.Action.WaitFor(ctx, actions...)
+				if nil != err {
+					return err
+				}
+			}
+
+			_, err := client.Firewall.Delete(ctx, firewall)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledPlacementGroups removes all placement groups matching the given list options.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// listOpts hcloud.ListOpts           List options containing the label selector
+func deleteLabeledPlacementGroups(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, listOpts hcloud.ListOpts) error {
+	placementGroups, err := client.PlacementGroup.AllWithOpts(ctx, hcloud.PlacementGroupListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	var errs []error
+
+	for _, placementGroup := range placementGroups {
+		errs = append(errs, deleteLabeledResource(log, owner, "placement group", placementGroup.ID, placementGroup.Name, owner.IsOwnerOf(placementGroup.Labels, ""), func() error {
+			_, err := client.PlacementGroup.Delete(ctx, placementGroup)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteLabeledNetworks removes all networks matching the given list options.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// listOpts hcloud.ListOpts           List options containing the label selector
+func deleteLabeledNetworks(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, listOpts hcloud.ListOpts) error {
+	networks, err := client.Network.AllWithOpts(ctx, hcloud.NetworkListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	var errs []error
+
+	for _, network := range networks {
+		errs = append(errs, deleteLabeledResource(log, owner, "network", network.ID, network.Name, owner.IsOwnerOf(network.Labels, ""), func() error {
+			_, err := client.Network.Delete(ctx, network)
+			return err
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// releaseUnownedNetwork removes the subnets and routes of the shoot from an existing or shared workers network.
+//
+// PARAMETERS
+// ctx        context.Context                      Execution context
+// client     *hcloud.Client                       HCloud client
+// owner      *controller.ResourceOwner            Resource owner
+// networkIDs *apis.InfrastructureConfigNetworkIDs Network IDs struct of the last reconciliation if known
+func releaseUnownedNetwork(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, networkIDs *apis.InfrastructureConfigNetworkIDs) error {
+	if nil == networkIDs {
+		return nil
+	}
+
+	if networkIDs.WorkersExisting {
+		return ensureExistingNetworkSubnetsDeleted(ctx, client, networkIDs)
+	}
+
+	if networkIDs.WorkersShared {
+		return ensureSharedNetworkReleased(ctx, client, owner, networkIDs)
+	}
+
+	return nil
+}
+
+// deleteLabeledSSHKeys releases all SSH keys matching the given list options or referenced by the shoot. Shared
+// SSH keys are only removed if no other shoot references them anymore.
+//
+// PARAMETERS
+// ctx      context.Context           Execution context
+// client   *hcloud.Client            HCloud client
+// log      logr.Logger               Logger used to report deleted resources
+// owner    *controller.ResourceOwner Resource owner
+// listOpts hcloud.ListOpts           List options containing the label selector
+// isUsed   IsSSHPublicKeyUsedFunc    Function checking if a SSH public key is used by another shoot
+func deleteLabeledSSHKeys(ctx context.Context, client *hcloud.Client, log logr.Logger, owner *controller.ResourceOwner, listOpts hcloud.ListOpts, isUsed IsSSHPublicKeyUsedFunc) error {
+	sshKeys, err := client.SSHKey.AllWithOpts(ctx, hcloud.SSHKeyListOpts{ListOpts: listOpts})
+	if nil != err {
+		return err
+	}
+
+	referencedSSHKeys, err := client.SSHKey.AllWithOpts(ctx, hcloud.SSHKeyListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: owner.ReferenceLabel()},
+	})
+	if nil != err {
+		return err
+	}
+
+	var errs []error
+	known := map[int64]bool{}
+
+	for _, sshKey := range append(sshKeys, referencedSSHKeys...) {
+		if known[sshKey.ID] {
+			continue
+		}
+
+		known[sshKey.ID] = true
+
+		if !isSharedSSHPublicKey(owner, sshKey.Labels) {
+			owner.RefuseDeletion("SSH key", sshKey.Name, sshKey.ID)
+			continue
+		}
+
+		err := EnsureSSHPublicKeyDeleted(ctx, client, owner, sshKey.Fingerprint, isUsed)
+		if nil != err {
+			errs = append(errs, fmt.Errorf("Failed to release SSH key %q (%d): %w", sshKey.Name, sshKey.ID, err))
+			continue
+		}
+
+		log.Info("Released labeled SSH key", "id", sshKey.ID, "name", sshKey.Name)
+	}
+
+	return errors.Join(errs...)
+}
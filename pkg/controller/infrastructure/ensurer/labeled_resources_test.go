@@ -0,0 +1,194 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+const testLabeledServerTemplate = `{"id": %d, "name": "test-server-%d", "status": "running", "created": "2016-01-30T23:50:00+00:00", "labels": %s, "private_net": [{"network": %d, "ip": "10.250.0.%d", "alias_ips": []}], "volumes": %s, "load_balancers": %s}`
+
+func writeListResponse(res http.ResponseWriter, key string, items ...string) {
+	res.Header().Add("Content-Type", "application/json; charset=utf-8")
+	res.WriteHeader(http.StatusOK)
+
+	_, _ = fmt.Fprintf(res, `{%q: [%s]}`, key, strings.Join(items, ","))
+}
+
+func handleDeletions(mux *http.ServeMux, path string, deleted *[]string, response string) {
+	mux.HandleFunc(path, func(res http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodDelete {
+			res.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		*deleted = append(*deleted, strings.TrimPrefix(req.URL.Path, path))
+
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write([]byte(response))
+	})
+}
+
+var _ = Describe("Labeled resources", func() {
+	var (
+		mockTestEnv mock.MockTestEnv
+		owner       *controller.ResourceOwner
+	)
+
+	BeforeEach(func() {
+		mockTestEnv = mock.NewMockTestEnv()
+		owner = controller.NewResourceOwner("shoot-uid", "", nil, nil)
+
+		for _, key := range []string{"floating_ips", "primary_ips", "firewalls", "placement_groups", "networks", "ssh_keys"} {
+			mockTestEnv.Mux.HandleFunc("/"+key, func(res http.ResponseWriter, req *http.Request) {
+				writeListResponse(res, key)
+			})
+		}
+	})
+
+	AfterEach(func() {
+		mockTestEnv.Teardown()
+	})
+
+	Describe("#EnsureLabeledResourcesDeleted", func() {
+		It("should delete the servers, volumes and load balancers of the shoot", func() {
+			var deletedServers, deletedVolumes, deletedLoadBalancers []string
+
+			mockTestEnv.Mux.HandleFunc("/servers", func(res http.ResponseWriter, req *http.Request) {
+				switch req.URL.Query().Get("label_selector") {
+				case "cluster.gardener.cloud/id=shoot-uid":
+					writeListResponse(res, "servers", fmt.Sprintf(testLabeledServerTemplate, 1, 1, `{"cluster.gardener.cloud/id": "shoot-uid"}`, 42, 1, "[]", "[]"))
+				case "mcm.gardener.cloud/cluster=shoot--foobar--hcloud":
+					writeListResponse(res, "servers",
+						fmt.Sprintf(testLabeledServerTemplate, 2, 2, `{"mcm.gardener.cloud/cluster": "shoot--foobar--hcloud"}`, 42, 2, "[7]", "[8]"),
+						fmt.Sprintf(testLabeledServerTemplate, 3, 3, `{"mcm.gardener.cloud/cluster": "shoot--foobar--hcloud"}`, 43, 3, "[]", "[]"),
+						fmt.Sprintf(testLabeledServerTemplate, 4, 4, `{"mcm.gardener.cloud/cluster": "shoot--foobar--hcloud", "cluster.gardener.cloud/id": "other-uid"}`, 42, 4, "[]", "[]"),
+					)
+				default:
+					writeListResponse(res, "servers")
+				}
+			})
+			handleDeletions(mockTestEnv.Mux, "/servers/", &deletedServers, `{"action": {"id": 1, "command": "delete_server", "status": "success", "progress": 100, "started": "2016-01-30T23:50:00+00:00", "resources": []}}`)
+
+			mockTestEnv.Mux.HandleFunc("/load_balancers", func(res http.ResponseWriter, req *http.Request) {
+				if "hcloud-ccm/service-uid" != req.URL.Query().Get("label_selector") {
+					writeListResponse(res, "load_balancers")
+					return
+				}
+
+				writeListResponse(res, "load_balancers",
+					`{"id": 8, "name": "a8", "labels": {"hcloud-ccm/service-uid": "8"}, "private_net": [], "targets": [], "services": []}`,
+					`{"id": 9, "name": "a9", "labels": {"hcloud-ccm/service-uid": "9"}, "private_net": [{"network": 42, "ip": "10.250.0.9"}], "targets": [], "services": []}`,
+					`{"id": 10, "name": "a10", "labels": {"hcloud-ccm/service-uid": "10"}, "private_net": [{"network": 43, "ip": "10.250.0.10"}], "targets": [], "services": []}`,
+				)
+			})
+			handleDeletions(mockTestEnv.Mux, "/load_balancers/", &deletedLoadBalancers, "")
+
+			mockTestEnv.Mux.HandleFunc("/volumes", func(res http.ResponseWriter, req *http.Request) {
+				writeListResponse(res, "volumes")
+			})
+			mockTestEnv.Mux.HandleFunc("/volumes/7", func(res http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodDelete {
+					deletedVolumes = append(deletedVolumes, "7")
+					res.WriteHeader(http.StatusNoContent)
+
+					return
+				}
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+				_, _ = res.Write([]byte(`{"volume": {"id": 7, "name": "pvc-7", "server": 2, "labels": {}, "created": "2016-01-30T23:50:00+00:00"}}`))
+			})
+			mockTestEnv.Mux.HandleFunc("/volumes/7/actions/detach", func(res http.ResponseWriter, req *http.Request) {
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+				_, _ = res.Write([]byte(testActionResponse))
+			})
+
+			networkIDs := &apis.InfrastructureConfigNetworkIDs{Workers: "42"}
+
+			err := EnsureLabeledResourcesDeleted(context.TODO(), mockTestEnv.HcloudClient, logr.Discard(), owner, "shoot--foobar--hcloud", networkIDs, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedServers).To(ConsistOf("1", "2"))
+			Expect(deletedVolumes).To(ConsistOf("7"))
+			Expect(deletedLoadBalancers).To(ConsistOf("8", "9"))
+		})
+
+		It("should remove the subnet and routes added to an existing network", func() {
+			var deletedRoutes, deletedSubnets []string
+
+			for _, key := range []string{"servers", "load_balancers", "volumes"} {
+				mockTestEnv.Mux.HandleFunc("/"+key, func(res http.ResponseWriter, req *http.Request) {
+					writeListResponse(res, key)
+				})
+			}
+
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+
+				_, _ = res.Write([]byte(`{"network": {"id": 42, "name": "existing-network", "ip_range": "10.0.0.0/8", "subnets": [
+					{"type": "cloud", "ip_range": "10.1.0.0/16", "network_zone": "eu-central", "gateway": "10.0.0.1"},
+					{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central", "gateway": "10.0.0.1"}
+				], "routes": [
+					{"destination": "10.96.0.0/24", "gateway": "10.250.0.2"},
+					{"destination": "10.200.0.0/24", "gateway": "10.1.0.2"}
+				], "servers": [], "labels": {}, "created": "2016-01-30T23:50:00+00:00"}}`))
+			})
+
+			mockTestEnv.Mux.HandleFunc("/networks/42/actions/delete_route", func(res http.ResponseWriter, req *http.Request) {
+				deletedRoutes = append(deletedRoutes, decodeRequestBody(req)["destination"].(string))
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+				_, _ = res.Write([]byte(testActionResponse))
+			})
+
+			mockTestEnv.Mux.HandleFunc("/networks/42/actions/delete_subnet", func(res http.ResponseWriter, req *http.Request) {
+				deletedSubnets = append(deletedSubnets, decodeRequestBody(req)["ip_range"].(string))
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+				_, _ = res.Write([]byte(testActionResponse))
+			})
+
+			networkIDs := &apis.InfrastructureConfigNetworkIDs{
+				Workers:         "42",
+				WorkersExisting: true,
+				WorkersSubnet:   "10.250.0.0/19",
+			}
+
+			err := EnsureLabeledResourcesDeleted(context.TODO(), mockTestEnv.HcloudClient, logr.Discard(), owner, "shoot--foobar--hcloud", networkIDs, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedRoutes).To(Equal([]string{"10.96.0.0/24"}))
+			Expect(deletedSubnets).To(Equal([]string{"10.250.0.0/19"}))
+		})
+	})
+})
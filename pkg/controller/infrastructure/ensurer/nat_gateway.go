@@ -0,0 +1,370 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	hcloudextension "github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+const (
+	// defaultNATGatewayServerType is the HCloud server type used for the NAT gateway if not configured.
+	defaultNATGatewayServerType = "cx22"
+	// defaultNATGatewayImageName is the HCloud image name used for the NAT gateway if not configured.
+	defaultNATGatewayImageName = "ubuntu-24.04"
+	// defaultRouteDestination is the destination of the default route added to the workers network.
+	defaultRouteDestination = "0.0.0.0/0"
+	// natGatewayRole is the role label value of NAT gateway servers created.
+	natGatewayRole = "infrastructure-nat-gateway-v1"
+	// natGatewayUserDataTemplate is the cloud-init configuration enabling masquerading for the given CIDR.
+	natGatewayUserDataTemplate = `#cloud-config
+write_files:
+- path: /etc/sysctl.d/99-gardener-nat-gateway.conf
+  content: |
+    net.ipv4.ip_forward=1
+- path: /etc/systemd/system/gardener-nat-gateway.service
+  content: |
+    [Unit]
+    Description=Gardener NAT gateway masquerading
+    After=network-online.target
+    Wants=network-online.target
+
+    [Service]
+    Type=oneshot
+    RemainAfterExit=true
+    ExecStart=/usr/sbin/iptables -t nat -A POSTROUTING -s %s -o eth0 -j MASQUERADE
+
+    [Install]
+    WantedBy=multi-user.target
+runcmd:
+- sysctl --system
+- systemctl daemon-reload
+- systemctl enable --now gardener-nat-gateway.service
+`
+)
+
+// EnsureNATGateway verifies that the NAT gateway server is available and set as the default route of the workers network.
+//
+// PARAMETERS
+// ctx            context.Context                      Execution context
+// client         *hcloud.Client                       HCloud client
+// owner          *controller.ResourceOwner            Resource owner
+// namespace      string                               Shoot namespace
+// zone           string                               Shoot zone
+// sshFingerprint string                               SSH fingerprint of the key to deploy
+// networks       *apis.InfrastructureConfigNetworks   Networks struct
+// networkIDs     *apis.InfrastructureConfigNetworkIDs Network IDs struct
+// natGateway     *apis.InfrastructureConfigNATGateway NAT gateway struct
+// natGatewayID   string                               NAT gateway server ID of the previous reconciliation
+// natGatewayIP   string                               NAT gateway private IP of the previous reconciliation
+func EnsureNATGateway(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace, zone, sshFingerprint string, networks *apis.InfrastructureConfigNetworks, networkIDs *apis.InfrastructureConfigNetworkIDs, natGateway *apis.InfrastructureConfigNATGateway, natGatewayID, natGatewayIP string) (int64, net.IP, error) {
+	// Worker nodes egressing through the NAT gateway are deployed without public IPs using the machine class field
+	// publicNet only supported with extended machine class fields.
+	if !hcloudextension.ExtendedMachineClassFields {
+		return -1, nil, fmt.Errorf("NAT gateway requires extendedMachineClassFields to be enabled")
+	}
+
+	if nil == networks || nil == networkIDs || "" == networkIDs.Workers {
+		return -1, nil, fmt.Errorf("NAT gateway requires a workers network")
+	}
+
+	workersCidr := networks.Workers
+
+	if nil != networks.WorkersConfiguration {
+		workersCidr = networks.WorkersConfiguration.Cidr
+	}
+
+	networkID, err := strconv.ParseInt(networkIDs.Workers, 10, 64)
+	if nil != err {
+		return -1, nil, err
+	}
+
+	network, _, err := client.Network.GetByID(ctx, networkID)
+	if nil != err {
+		return -1, nil, err
+	} else if network == nil {
+		return -1, nil, fmt.Errorf("Failed to find workers network with ID %d", networkID)
+	}
+
+	name := fmt.Sprintf("%s-nat-gateway", namespace)
+
+	var server *hcloud.Server
+
+	if "" != natGatewayID {
+		id, err := strconv.ParseInt(natGatewayID, 10, 64)
+		if nil != err {
+			return -1, nil, err
+		}
+
+		server, _, err = client.Server.GetByID(ctx, id)
+		if nil != err {
+			return -1, nil, err
+		}
+	}
+
+	if server == nil {
+		server, _, err = client.Server.GetByName(ctx, name)
+		if nil != err {
+			return -1, nil, err
+		}
+	}
+
+	if server != nil && !owner.IsOwnerOf(server.Labels, natGatewayRole) {
+		return -1, nil, fmt.Errorf("Server %q (%d) is not owned by this shoot", server.Name, server.ID)
+	}
+
+	if server == nil {
+		serverType := defaultNATGatewayServerType
+		imageName := defaultNATGatewayImageName
+
+		if "" != natGateway.ServerType {
+			serverType = natGateway.ServerType
+		}
+
+		if "" != natGateway.ImageName {
+			imageName = natGateway.ImageName
+		}
+
+		labels := owner.Labels(natGatewayRole)
+
+		opts := hcloud.ServerCreateOpts{
+			Name:       name,
+			ServerType: &hcloud.ServerType{Name: serverType},
+			Image:      &hcloud.Image{Name: imageName},
+			Location:   &hcloud.Location{Name: apis.GetLocationFromZone(zone)},
+			Networks:   []*hcloud.Network{network},
+			UserData:   fmt.Sprintf(natGatewayUserDataTemplate, workersCidr),
+			Labels:     labels,
+			PublicNet: &hcloud.ServerCreatePublicNet{
+				EnableIPv4: true,
+				EnableIPv6: true,
+			},
+		}
+
+		if "" != sshFingerprint {
+			sshKey, _, err := client.SSHKey.GetByFingerprint(ctx, sshFingerprint)
+			if nil != err {
+				return -1, nil, err
+			} else if sshKey != nil {
+				opts.SSHKeys = []*hcloud.SSHKey{sshKey}
+			}
+		}
+
+		result, _, err := client.Server.Create(ctx, opts)
+		if nil != err {
+			return -1, nil, err
+		}
+
+		err = client.Action.WaitFor(ctx, append([]*hcloud.Action{result.Action}, result.NextActions...)...)
+		if nil != err {
+			return -1, nil, err
+		}
+
+		server, _, err = client.Server.GetByID(ctx, result.Server.ID)
+		if nil != err {
+			return -1, nil, err
+		} else if server == nil {
+			return -1, nil, fmt.Errorf("Failed to find NAT gateway server with ID %d", result.Server.ID)
+		}
+	} else if labels, changed := owner.MergeLabels(server.Labels, natGatewayRole); changed {
+		server, _, err = client.Server.Update(ctx, server, hcloud.ServerUpdateOpts{Labels: labels})
+		if nil != err {
+			return -1, nil, err
+		}
+	}
+
+	ip := getServerPrivateIP(server, network.ID)
+
+	if nil == ip {
+		action, _, err := client.Server.AttachToNetwork(ctx, server, hcloud.ServerAttachToNetworkOpts{Network: network})
+		if nil != err {
+			return -1, nil, err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return -1, nil, err
+		}
+
+		server, _, err = client.Server.GetByID(ctx, server.ID)
+		if nil != err {
+			return -1, nil, err
+		} else if server != nil {
+			ip = getServerPrivateIP(server, network.ID)
+		}
+
+		if nil == ip {
+			return -1, nil, fmt.Errorf("Failed to attach NAT gateway server %q to network %q", name, network.Name)
+		}
+	}
+
+	err = ensureDefaultRoute(ctx, client, network, ip, net.ParseIP(natGatewayIP))
+	if nil != err {
+		return -1, nil, err
+	}
+
+	return server.ID, ip, nil
+}
+
+// EnsureNATGatewayDeleted removes any previously created NAT gateway server and the default route pointing to it.
+//
+// PARAMETERS
+// ctx          context.Context                      Execution context
+// client       *hcloud.Client                       HCloud client
+// owner        *controller.ResourceOwner            Resource owner
+// natGatewayID string                               NAT gateway server ID
+// natGatewayIP string                               NAT gateway private IP
+// networkIDs   *apis.InfrastructureConfigNetworkIDs Network IDs struct
+func EnsureNATGatewayDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, natGatewayID, natGatewayIP string, networkIDs *apis.InfrastructureConfigNetworkIDs) error {
+	if "" != natGatewayIP && nil != networkIDs && "" != networkIDs.Workers {
+		networkID, err := strconv.ParseInt(networkIDs.Workers, 10, 64)
+		if nil != err {
+			return err
+		}
+
+		network, _, err := client.Network.GetByID(ctx, networkID)
+		if nil != err {
+			return err
+		} else if network != nil {
+			gateway := net.ParseIP(natGatewayIP)
+
+			for _, route := range network.Routes {
+				if route.Destination.String() != defaultRouteDestination || !route.Gateway.Equal(gateway) {
+					continue
+				}
+
+				action, _, err := client.Network.DeleteRoute(ctx, network, hcloud.NetworkDeleteRouteOpts{Route: route})
+				if nil != err {
+					return err
+				}
+
+				err = client.Action.WaitFor(ctx, action)
+				if nil != err {
+					return err
+				}
+			}
+		}
+	}
+
+	if "" != natGatewayID {
+		id, err := strconv.ParseInt(natGatewayID, 10, 64)
+		if nil != err {
+			return err
+		}
+
+		server, _, err := client.Server.GetByID(ctx, id)
+		if nil != err {
+			return err
+		} else if server != nil {
+			if !owner.IsOwnerOf(server.Labels, natGatewayRole) {
+				owner.RefuseDeletion("server", server.Name, server.ID)
+				return nil
+			}
+
+			result, _, err := client.Server.DeleteWithResult(ctx, server)
+			if nil != err {
+				return err
+			}
+
+			err = client.Action.WaitFor(ctx, result.Action)
+			if nil != err {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensureDefaultRoute verifies that the default route of the given network points to the gateway IP given. A default
+// route is only replaced if it points to the NAT gateway of the previous reconciliation, routes added by others are
+// refused to be changed.
+//
+// PARAMETERS
+// ctx             context.Context Execution context
+// client          *hcloud.Client  HCloud client
+// network         *hcloud.Network HCloud network
+// gateway         net.IP          Gateway IP
+// previousGateway net.IP          Gateway IP of the previous reconciliation
+func ensureDefaultRoute(ctx context.Context, client *hcloud.Client, network *hcloud.Network, gateway, previousGateway net.IP) error {
+	_, destination, err := net.ParseCIDR(defaultRouteDestination)
+	if nil != err {
+		return err
+	}
+
+	for _, route := range network.Routes {
+		if route.Destination.String() != destination.String() {
+			continue
+		}
+
+		if route.Gateway.Equal(gateway) {
+			return nil
+		}
+
+		if nil == previousGateway || !route.Gateway.Equal(previousGateway) {
+			return fmt.Errorf("Network %q already has a default route via %s not managed by this shoot", network.Name, route.Gateway.String())
+		}
+
+		action, _, err := client.Network.DeleteRoute(ctx, network, hcloud.NetworkDeleteRouteOpts{Route: route})
+		if nil != err {
+			return err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return err
+		}
+	}
+
+	opts := hcloud.NetworkAddRouteOpts{
+		Route: hcloud.NetworkRoute{
+			Destination: destination,
+			Gateway:     gateway,
+		},
+	}
+
+	action, _, err := client.Network.AddRoute(ctx, network, opts)
+	if nil != err {
+		return err
+	}
+
+	return client.Action.WaitFor(ctx, action)
+}
+
+// getServerPrivateIP returns the private IP of the server in the network given.
+//
+// PARAMETERS
+// server    *hcloud.Server HCloud server
+// networkID int64          HCloud network ID
+func getServerPrivateIP(server *hcloud.Server, networkID int64) net.IP {
+	for _, privateNet := range server.PrivateNet {
+		if nil != privateNet.Network && privateNet.Network.ID == networkID {
+			return privateNet.IP
+		}
+	}
+
+	return nil
+}
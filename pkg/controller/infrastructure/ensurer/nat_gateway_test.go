@@ -0,0 +1,158 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+var _ = Describe("NAT gateway", func() {
+	var (
+		mockTestEnv   mock.MockTestEnv
+		addedRoutes   []map[string]interface{}
+		deletedRoutes []map[string]interface{}
+	)
+
+	newNetworkWithDefaultRoute := func(gateway string) *hcloud.Network {
+		network := &hcloud.Network{ID: 42, Name: "test-network", IPRange: parseIPRange("10.250.0.0/19")}
+
+		if "" != gateway {
+			network.Routes = []hcloud.NetworkRoute{{Destination: parseIPRange(defaultRouteDestination), Gateway: net.ParseIP(gateway)}}
+		}
+
+		return network
+	}
+
+	BeforeEach(func() {
+		mockTestEnv = mock.NewMockTestEnv()
+		addedRoutes = nil
+		deletedRoutes = nil
+
+		mockTestEnv.Mux.HandleFunc("/networks/42/actions/add_route", func(res http.ResponseWriter, req *http.Request) {
+			addedRoutes = append(addedRoutes, decodeRequestBody(req))
+
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusCreated)
+			_, _ = res.Write([]byte(testActionResponse))
+		})
+
+		mockTestEnv.Mux.HandleFunc("/networks/42/actions/delete_route", func(res http.ResponseWriter, req *http.Request) {
+			deletedRoutes = append(deletedRoutes, decodeRequestBody(req))
+
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusCreated)
+			_, _ = res.Write([]byte(testActionResponse))
+		})
+	})
+
+	AfterEach(func() {
+		mockTestEnv.Teardown()
+	})
+
+	Describe("#ensureDefaultRoute", func() {
+		It("should add the default route", func() {
+			err := ensureDefaultRoute(context.TODO(), mockTestEnv.HcloudClient, newNetworkWithDefaultRoute(""), net.ParseIP("10.250.0.2"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addedRoutes).To(HaveLen(1))
+			Expect(addedRoutes[0]).To(HaveKeyWithValue("destination", defaultRouteDestination))
+			Expect(addedRoutes[0]).To(HaveKeyWithValue("gateway", "10.250.0.2"))
+			Expect(deletedRoutes).To(BeEmpty())
+		})
+
+		It("should keep the default route pointing to the NAT gateway", func() {
+			err := ensureDefaultRoute(context.TODO(), mockTestEnv.HcloudClient, newNetworkWithDefaultRoute("10.250.0.2"), net.ParseIP("10.250.0.2"), net.ParseIP("10.250.0.2"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addedRoutes).To(BeEmpty())
+			Expect(deletedRoutes).To(BeEmpty())
+		})
+
+		It("should replace the default route of the previous NAT gateway", func() {
+			err := ensureDefaultRoute(context.TODO(), mockTestEnv.HcloudClient, newNetworkWithDefaultRoute("10.250.0.3"), net.ParseIP("10.250.0.2"), net.ParseIP("10.250.0.3"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedRoutes).To(HaveLen(1))
+			Expect(deletedRoutes[0]).To(HaveKeyWithValue("gateway", "10.250.0.3"))
+			Expect(addedRoutes).To(HaveLen(1))
+		})
+
+		It("should refuse to replace a default route not managed by the shoot", func() {
+			err := ensureDefaultRoute(context.TODO(), mockTestEnv.HcloudClient, newNetworkWithDefaultRoute("10.250.0.9"), net.ParseIP("10.250.0.2"), nil)
+			Expect(err).To(HaveOccurred())
+			Expect(addedRoutes).To(BeEmpty())
+			Expect(deletedRoutes).To(BeEmpty())
+		})
+	})
+
+	Describe("#EnsureNATGateway", func() {
+		It("should fail unless extended machine class fields are enabled", func() {
+			networks := &apis.InfrastructureConfigNetworks{Workers: "10.250.0.0/19"}
+			networkIDs := &apis.InfrastructureConfigNetworkIDs{Workers: "42"}
+
+			_, _, err := EnsureNATGateway(context.TODO(), mockTestEnv.HcloudClient, controller.NewResourceOwner("", "", nil, nil), "test-namespace", "hel1-dc2", "", networks, networkIDs, &apis.InfrastructureConfigNATGateway{}, "", "")
+			Expect(err).To(HaveOccurred())
+			Expect(addedRoutes).To(BeEmpty())
+		})
+	})
+
+	Describe("#EnsureNATGatewayDeleted", func() {
+		It("should remove the default route and the NAT gateway server", func() {
+			owner := controller.NewResourceOwner("shoot-uid", "", nil, nil)
+			serverDeleted := false
+
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+
+				_, _ = res.Write([]byte(`{"network": {"id": 42, "name": "test-network", "ip_range": "10.250.0.0/19", "subnets": [], "routes": [
+					{"destination": "0.0.0.0/0", "gateway": "10.250.0.2"},
+					{"destination": "10.96.0.0/24", "gateway": "10.250.0.3"}
+				], "servers": [], "labels": {}, "created": "2016-01-30T23:50:00+00:00"}}`))
+			})
+
+			mockTestEnv.Mux.HandleFunc("/servers/44", func(res http.ResponseWriter, req *http.Request) {
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+
+				if req.Method == http.MethodDelete {
+					serverDeleted = true
+					_, _ = res.Write([]byte(testActionResponse))
+
+					return
+				}
+
+				_, _ = fmt.Fprintf(res, `{"server": {"id": 44, "name": "test-nat-gateway", "labels": {%q: "shoot-uid", %q: %q}}}`, controller.LabelClusterID, controller.LabelRole, natGatewayRole)
+			})
+
+			err := EnsureNATGatewayDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, "44", "10.250.0.2", &apis.InfrastructureConfigNetworkIDs{Workers: "42"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedRoutes).To(HaveLen(1))
+			Expect(deletedRoutes[0]).To(HaveKeyWithValue("destination", defaultRouteDestination))
+			Expect(serverDeleted).To(BeTrue())
+		})
+	})
+})
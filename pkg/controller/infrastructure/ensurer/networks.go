@@ -19,8 +19,11 @@ package ensurer
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"net"
+	"slices"
+	"strconv"
 
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 
@@ -28,15 +31,37 @@ import (
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
 )
 
+// networkRole is the role label value of networks created.
+const networkRole = "workers-network-v1"
+
+// networkZoneSubnetBits is the number of bits used to split the workers CIDR into a subnet per network zone.
+const networkZoneSubnetBits = 2
+
+// networkZoneSubnetOrder defines the part of the workers CIDR used as subnet of each network zone.
+var networkZoneSubnetOrder = []hcloud.NetworkZone{
+	hcloud.NetworkZoneEUCentral,
+	hcloud.NetworkZoneUSEast,
+	hcloud.NetworkZoneUSWest,
+	hcloud.NetworkZoneAPSouthEast,
+}
+
 // EnsureNetworks verifies the network resources requested are available.
 //
 // PARAMETERS
-// ctx       context.Context                    Execution context
-// client    *hcloud.Client                     HCloud client
-// namespace string                             Shoot namespace
-// zone      string                             Shoot zone
-// networks  *apis.InfrastructureConfigNetworks Networks struct
-func EnsureNetworks(ctx context.Context, client *hcloud.Client, namespace, zone string, networks *apis.InfrastructureConfigNetworks) (int64, error) {
+// ctx         context.Context                      Execution context
+// client      *hcloud.Client                       HCloud client
+// owner       *controller.ResourceOwner            Resource owner
+// namespace   string                               Shoot namespace
+// region      string                               Shoot region
+// zone        string                               Shoot zone
+// workerZones []string                             Zones of all worker pools
+// networks    *apis.InfrastructureConfigNetworks   Networks struct
+// networkIDs  *apis.InfrastructureConfigNetworkIDs Network IDs struct of the previous reconciliation
+func EnsureNetworks(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace, region, zone string, workerZones []string, networks *apis.InfrastructureConfigNetworks, networkIDs *apis.InfrastructureConfigNetworkIDs) (*apis.InfrastructureConfigNetworkIDs, error) {
+	if nil == networks {
+		return nil, nil
+	}
+
 	workersConfiguration := networks.WorkersConfiguration
 
 	if nil == workersConfiguration && "" != networks.Workers {
@@ -46,12 +71,17 @@ func EnsureNetworks(ctx context.Context, client *hcloud.Client, namespace, zone
 	}
 
 	if nil != workersConfiguration {
+		// Regions spanning multiple locations are HCloud network zones themselves.
+		if "" == workersConfiguration.Zone && apis.IsNetworkZoneRegion(region) {
+			workersConfiguration.Zone = hcloud.NetworkZone(region)
+		}
+
 		if "" == workersConfiguration.Zone {
-			locationName := apis.GetRegionFromZone(zone)
+			locationName := apis.GetLocationFromZone(zone)
 
 			locations, err := client.Location.All(ctx)
 			if nil != err {
-				return -1, err
+				return nil, err
 			}
 
 			for _, location := range locations {
@@ -62,45 +92,605 @@ func EnsureNetworks(ctx context.Context, client *hcloud.Client, namespace, zone
 			}
 
 			if "" == workersConfiguration.Zone {
-				return -1, fmt.Errorf("Failed to find matching location for zone %q", zone)
+				return nil, fmt.Errorf("Failed to find matching location for zone %q", zone)
 			}
 		}
 
-		name := fmt.Sprintf("%s-workers", namespace)
+		_, ipRange, err := net.ParseCIDR(workersConfiguration.Cidr)
+		if nil != err {
+			return nil, err
+		}
+
+		// HCloud networks are IPv4 only. IPv6 traffic of the worker nodes uses their public IPv6 addresses.
+		if nil == ipRange.IP.To4() {
+			return nil, fmt.Errorf("Workers CIDR %q is not an IPv4 range supported by HCloud networks", ipRange.String())
+		}
 
-		network, _, err := client.Network.GetByName(ctx, name)
+		subnets, err := getWorkersSubnets(ipRange, workersConfiguration.Zone, region, workerZones)
 		if nil != err {
-			return -1, err
-		} else if network == nil {
-			_, ipRange, _ := net.ParseCIDR(workersConfiguration.Cidr)
+			return nil, err
+		}
+
+		var (
+			network *hcloud.Network
+			result  *apis.InfrastructureConfigNetworkIDs
+		)
+
+		if nil != networks.Existing {
+			if len(subnets) > 1 {
+				return nil, fmt.Errorf("Worker pools spanning multiple network zones are not supported for existing networks")
+			}
+
+			network, result, err = ensureExistingNetwork(ctx, client, networks.Existing, ipRange, workersConfiguration.Zone, networkIDs)
+			if nil != err {
+				return nil, err
+			}
+		} else if nil != networks.Shared {
+			network, result, err = ensureSharedNetwork(ctx, client, owner, networks.Shared, subnets)
+			if nil != err {
+				return nil, err
+			}
+
+			subnets, err = ensureWorkersSubnets(ctx, client, network, ipRange, subnets)
+			if nil != err {
+				return nil, err
+			}
+		} else {
+			name := fmt.Sprintf("%s-workers", namespace)
+			isReferenced := false
+
+			if nil != networkIDs && !networkIDs.WorkersExisting && "" != networkIDs.Workers {
+				id, err := strconv.ParseInt(networkIDs.Workers, 10, 64)
+				if nil != err {
+					return nil, err
+				}
+
+				network, _, err = client.Network.GetByID(ctx, id)
+				if nil != err {
+					return nil, err
+				}
+
+				isReferenced = network != nil
+			}
+
+			if network == nil {
+				network, _, err = client.Network.GetByName(ctx, name)
+				if nil != err {
+					return nil, err
+				}
+			}
+
+			// Networks of earlier versions are adopted if referenced by the status of the shoot.
+			if network != nil && !owner.IsOwnerOf(network.Labels, networkRole) && !(isReferenced && owner.IsAdoptable(network.Labels, networkRole)) {
+				return nil, fmt.Errorf("Network %q (%d) is not owned by this shoot", network.Name, network.ID)
+			}
+
+			var vSwitchCidr string
+
+			if nil != networks.VSwitch {
+				vSwitchCidr = networks.VSwitch.Cidr
+			}
+
+			networkRange, err := apis.GetNetworkIPRange(ipRange, vSwitchCidr)
+			if nil != err {
+				return nil, err
+			}
+
+			if network == nil {
+				labels := owner.Labels(networkRole)
 
-			labels := map[string]string{"hcloud.provider.extensions.gardener.cloud/role": "workers-network-v1"}
+				opts := hcloud.NetworkCreateOpts{
+					Name:    name,
+					IPRange: networkRange,
+					Labels:  labels,
+				}
 
-			opts := hcloud.NetworkCreateOpts{
-				Name:    name,
-				IPRange: ipRange,
-				Subnets: []hcloud.NetworkSubnet{
-					hcloud.NetworkSubnet{
+				for _, networkZone := range getSortedNetworkZones(subnets) {
+					opts.Subnets = append(opts.Subnets, hcloud.NetworkSubnet{
 						Type:        hcloud.NetworkSubnetTypeCloud,
-						IPRange:     ipRange,
-						NetworkZone: workersConfiguration.Zone,
-					}},
-				Labels: labels,
+						IPRange:     subnets[networkZone],
+						NetworkZone: networkZone,
+					})
+				}
+
+				network, _, err = client.Network.Create(ctx, opts)
+				if nil != err {
+					return nil, err
+				}
+			} else {
+				if labels, changed := owner.MergeLabels(network.Labels, networkRole); changed {
+					network, _, err = client.Network.Update(ctx, network, hcloud.NetworkUpdateOpts{Labels: labels})
+					if nil != err {
+						return nil, err
+					}
+				}
+
+				err = ensureNetworkIPRange(ctx, client, network, networkRange)
+				if nil != err {
+					return nil, err
+				}
+
+				subnets, err = ensureWorkersSubnets(ctx, client, network, ipRange, subnets)
+				if nil != err {
+					return nil, err
+				}
+			}
+
+			result = &apis.InfrastructureConfigNetworkIDs{
+				Workers:     strconv.FormatInt(network.ID, 10),
+				WorkersName: name,
+			}
+		}
+
+		result.WorkersSubnets = map[string]string{}
+
+		for networkZone, subnet := range subnets {
+			result.WorkersSubnets[string(networkZone)] = subnet.String()
+		}
+
+		err = ensureVSwitchSubnet(ctx, client, network, networks.VSwitch, workersConfiguration.Zone, !result.WorkersExisting, networkIDs, result)
+		if nil != err {
+			return nil, err
+		}
+
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+// getWorkersSubnets returns the cloud subnets of the workers network by HCloud network zone. The workers CIDR is split into a fixed part per network zone if worker pools span multiple network zones.
+//
+// PARAMETERS
+// ipRange     *net.IPNet         Workers CIDR
+// networkZone hcloud.NetworkZone Network zone of the control plane zone
+// region      string             Shoot region
+// workerZones []string           Zones of all worker pools
+func getWorkersSubnets(ipRange *net.IPNet, networkZone hcloud.NetworkZone, region string, workerZones []string) (map[hcloud.NetworkZone]*net.IPNet, error) {
+	networkZones := map[hcloud.NetworkZone]bool{networkZone: true}
+
+	for _, workerZone := range workerZones {
+		workerNetworkZone := apis.GetNetworkZone(region, workerZone)
+		if "" == workerNetworkZone {
+			return nil, fmt.Errorf("Failed to find network zone for zone %q", workerZone)
+		}
+
+		networkZones[workerNetworkZone] = true
+	}
+
+	if 1 == len(networkZones) {
+		return map[hcloud.NetworkZone]*net.IPNet{networkZone: ipRange}, nil
+	}
+
+	ones, bits := ipRange.Mask.Size()
+	subnetOnes := ones + networkZoneSubnetBits
+
+	if subnetOnes > bits {
+		return nil, fmt.Errorf("Workers CIDR %q is too small to be split into subnets for multiple network zones", ipRange.String())
+	}
+
+	subnets := map[hcloud.NetworkZone]*net.IPNet{}
+
+	for index, subnetNetworkZone := range networkZoneSubnetOrder {
+		if !networkZones[subnetNetworkZone] {
+			continue
+		}
+
+		subnets[subnetNetworkZone] = getIPRangeAt(ipRange, subnetOnes, uint32(index))
+		delete(networkZones, subnetNetworkZone)
+	}
+
+	for unknownNetworkZone := range networkZones {
+		return nil, fmt.Errorf("Network zone %q is not supported for worker pools spanning multiple network zones", unknownNetworkZone)
+	}
+
+	return subnets, nil
+}
+
+// getSortedNetworkZones returns the network zones of the subnets given in a stable order.
+//
+// PARAMETERS
+// subnets map[hcloud.NetworkZone]*net.IPNet Subnets by network zone
+func getSortedNetworkZones(subnets map[hcloud.NetworkZone]*net.IPNet) []hcloud.NetworkZone {
+	networkZones := make([]hcloud.NetworkZone, 0, len(subnets))
+
+	for networkZone := range subnets {
+		networkZones = append(networkZones, networkZone)
+	}
+
+	slices.Sort(networkZones)
+
+	return networkZones
+}
+
+// ensureNetworkIPRange expands the IP range of the workers network if the workers CIDR has been enlarged or a vSwitch
+// subnet outside of it has been added.
+//
+// PARAMETERS
+// ctx     context.Context Execution context
+// client  *hcloud.Client  HCloud client
+// network *hcloud.Network HCloud network
+// ipRange *net.IPNet      Network IP range
+func ensureNetworkIPRange(ctx context.Context, client *hcloud.Client, network *hcloud.Network, ipRange *net.IPNet) error {
+	if network.IPRange.String() == ipRange.String() {
+		return nil
+	}
+
+	if !isSubnetOf(network.IPRange, ipRange) {
+		return fmt.Errorf("IP range %q of network %q can only be expanded to a range containing it instead of %q", network.IPRange.String(), network.Name, ipRange.String())
+	}
+
+	action, _, err := client.Network.ChangeIPRange(ctx, network, hcloud.NetworkChangeIPRangeOpts{IPRange: ipRange})
+	if nil != err {
+		return err
+	}
+
+	err = client.Action.WaitFor(ctx, action)
+	if nil != err {
+		return err
+	}
+
+	network.IPRange = ipRange
+
+	return nil
+}
+
+// ensureWorkersSubnets adds missing cloud subnets to the workers network. The part of the IP range of a network zone
+// not covered by its subnets yet, e.g. after expanding the workers CIDR, is appended as additional subnets. A network
+// zone whose IP range conflicts with existing subnets keeps its subnet in the workers CIDR or gets the first free one
+// of the same size. It returns the IP ranges covered by the subnets of each network zone.
+//
+// PARAMETERS
+// ctx          context.Context                   Execution context
+// client       *hcloud.Client                    HCloud client
+// network      *hcloud.Network                   HCloud network
+// workersRange *net.IPNet                        Workers CIDR
+// subnets      map[hcloud.NetworkZone]*net.IPNet Subnets by network zone
+func ensureWorkersSubnets(ctx context.Context, client *hcloud.Client, network *hcloud.Network, workersRange *net.IPNet, subnets map[hcloud.NetworkZone]*net.IPNet) (map[hcloud.NetworkZone]*net.IPNet, error) {
+	networkSubnets := slices.Clone(network.Subnets)
+	result := map[hcloud.NetworkZone]*net.IPNet{}
+
+	for _, networkZone := range getSortedNetworkZones(subnets) {
+		ipRange := subnets[networkZone]
+
+		var (
+			conflictingSubnet *hcloud.NetworkSubnet
+			keptSubnet        *net.IPNet
+			zoneSubnets       []*net.IPNet
+		)
+
+		for index, subnet := range networkSubnets {
+			isZoneSubnet := subnet.Type == hcloud.NetworkSubnetTypeCloud && subnet.NetworkZone == networkZone
+
+			if isZoneSubnet && nil == keptSubnet && isSubnetOf(subnet.IPRange, workersRange) {
+				keptSubnet = subnet.IPRange
+			}
+
+			if !isOverlapping(subnet.IPRange, ipRange) {
+				continue
+			}
+
+			if isZoneSubnet && isSubnetOf(subnet.IPRange, ipRange) {
+				zoneSubnets = append(zoneSubnets, subnet.IPRange)
+			} else if nil == conflictingSubnet {
+				conflictingSubnet = &networkSubnets[index]
+			}
+		}
+
+		addedRanges := getUncoveredIPRanges(ipRange, zoneSubnets)
+
+		if nil != conflictingSubnet {
+			if nil != keptSubnet {
+				result[networkZone] = keptSubnet
+				continue
+			}
+
+			freeRange := getFreeIPRange(workersRange, ipRange, networkSubnets)
+			if nil == freeRange {
+				return nil, fmt.Errorf("Existing subnet %q of network %q conflicts with subnet %q required in network zone %q and no free range is left in %q", conflictingSubnet.IPRange.String(), network.Name, ipRange.String(), networkZone, workersRange.String())
+			}
+
+			ipRange = freeRange
+			addedRanges = []*net.IPNet{freeRange}
+		}
+
+		for _, addedRange := range addedRanges {
+			subnet := hcloud.NetworkSubnet{
+				Type:        hcloud.NetworkSubnetTypeCloud,
+				IPRange:     addedRange,
+				NetworkZone: networkZone,
+			}
+
+			action, _, err := client.Network.AddSubnet(ctx, network, hcloud.NetworkAddSubnetOpts{Subnet: subnet})
+			if nil != err {
+				return nil, err
 			}
 
-			network, _, err = client.Network.Create(ctx, opts)
+			err = client.Action.WaitFor(ctx, action)
 			if nil != err {
-				return -1, err
+				return nil, err
+			}
+
+			networkSubnets = append(networkSubnets, subnet)
+		}
+
+		result[networkZone] = ipRange
+	}
+
+	return result, nil
+}
+
+// getUncoveredIPRanges returns the smallest set of IP ranges covering the part of the given IP range not covered by
+// any of the subnets given.
+//
+// PARAMETERS
+// ipRange *net.IPNet   IP range to cover
+// subnets []*net.IPNet Subnets of the IP range
+func getUncoveredIPRanges(ipRange *net.IPNet, subnets []*net.IPNet) []*net.IPNet {
+	isOverlapped := false
+
+	for _, subnet := range subnets {
+		if isSubnetOf(ipRange, subnet) {
+			return nil
+		} else if isOverlapping(ipRange, subnet) {
+			isOverlapped = true
+		}
+	}
+
+	if !isOverlapped {
+		return []*net.IPNet{ipRange}
+	}
+
+	ones, _ := ipRange.Mask.Size()
+
+	return append(getUncoveredIPRanges(getIPRangeAt(ipRange, ones+1, 0), subnets), getUncoveredIPRanges(getIPRangeAt(ipRange, ones+1, 1), subnets)...)
+}
+
+// getFreeIPRange returns the first IP range of the workers CIDR with the size of the given one not overlapping any
+// subnet of the network.
+//
+// PARAMETERS
+// workersRange   *net.IPNet             Workers CIDR
+// ipRange        *net.IPNet             IP range defining the size
+// networkSubnets []hcloud.NetworkSubnet Subnets of the network
+func getFreeIPRange(workersRange, ipRange *net.IPNet, networkSubnets []hcloud.NetworkSubnet) *net.IPNet {
+	workersOnes, _ := workersRange.Mask.Size()
+	ones, _ := ipRange.Mask.Size()
+
+	for index := uint32(0); index < 1<<(ones-workersOnes); index++ {
+		freeRange := getIPRangeAt(workersRange, ones, index)
+		isFree := true
+
+		for _, subnet := range networkSubnets {
+			if isOverlapping(subnet.IPRange, freeRange) {
+				isFree = false
+				break
 			}
+		}
+
+		if isFree {
+			return freeRange
+		}
+	}
+
+	return nil
+}
+
+// getIPRangeAt returns the IP range with the given prefix length at the index given within an IPv4 range.
+//
+// PARAMETERS
+// ipRange *net.IPNet IPv4 range to split
+// ones    int        Prefix length of the IP range to return
+// index   uint32     Index of the IP range to return
+func getIPRangeAt(ipRange *net.IPNet, ones int, index uint32) *net.IPNet {
+	_, bits := ipRange.Mask.Size()
+
+	ip := make(net.IP, len(ipRange.IP.To4()))
+	copy(ip, ipRange.IP.To4())
+
+	binary.BigEndian.PutUint32(ip, binary.BigEndian.Uint32(ip)|index<<(bits-ones))
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(ones, bits)}
+}
+
+// ensureExistingNetwork verifies that the existing network referenced contains the worker subnet requested.
+//
+// PARAMETERS
+// ctx         context.Context                           Execution context
+// client      *hcloud.Client                            HCloud client
+// existing    *apis.InfrastructureConfigExistingNetwork Existing network reference
+// ipRange     *net.IPNet                                Worker subnet IP range
+// networkZone hcloud.NetworkZone                        Worker subnet network zone
+// networkIDs  *apis.InfrastructureConfigNetworkIDs      Network IDs struct of the previous reconciliation
+func ensureExistingNetwork(ctx context.Context, client *hcloud.Client, existing *apis.InfrastructureConfigExistingNetwork, ipRange *net.IPNet, networkZone hcloud.NetworkZone, networkIDs *apis.InfrastructureConfigNetworkIDs) (*hcloud.Network, *apis.InfrastructureConfigNetworkIDs, error) {
+	var (
+		network *hcloud.Network
+		err     error
+	)
+
+	if 0 != existing.ID {
+		network, _, err = client.Network.GetByID(ctx, existing.ID)
+	} else {
+		network, _, err = client.Network.GetByName(ctx, existing.Name)
+	}
+
+	if nil != err {
+		return nil, nil, err
+	} else if network == nil {
+		return nil, nil, fmt.Errorf("Failed to find existing network with ID %d or name %q", existing.ID, existing.Name)
+	}
+
+	if !isSubnetOf(ipRange, network.IPRange) {
+		return nil, nil, fmt.Errorf("Workers CIDR %q is not part of the IP range %q of the existing network %q", ipRange.String(), network.IPRange.String(), network.Name)
+	}
+
+	result := &apis.InfrastructureConfigNetworkIDs{
+		Workers:         strconv.FormatInt(network.ID, 10),
+		WorkersName:     network.Name,
+		WorkersExisting: true,
+	}
+
+	for _, subnet := range network.Subnets {
+		if subnet.IPRange.String() != ipRange.String() {
+			continue
+		}
+
+		if subnet.Type != hcloud.NetworkSubnetTypeCloud || subnet.NetworkZone != networkZone {
+			return nil, nil, fmt.Errorf("Existing subnet %q of network %q does not match the expected type %q in network zone %q", ipRange.String(), network.Name, hcloud.NetworkSubnetTypeCloud, networkZone)
+		}
+
+		// Only claim ownership of the subnet if it has been added by a previous reconciliation.
+		if nil != networkIDs && networkIDs.WorkersSubnet == ipRange.String() {
+			result.WorkersSubnet = networkIDs.WorkersSubnet
+		}
+
+		return network, result, nil
+	}
+
+	opts := hcloud.NetworkAddSubnetOpts{
+		Subnet: hcloud.NetworkSubnet{
+			Type:        hcloud.NetworkSubnetTypeCloud,
+			IPRange:     ipRange,
+			NetworkZone: networkZone,
+		},
+	}
+
+	action, _, err := client.Network.AddSubnet(ctx, network, opts)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	err = client.Action.WaitFor(ctx, action)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	result.WorkersSubnet = ipRange.String()
+
+	return network, result, nil
+}
+
+// ensureVSwitchSubnet verifies that the vSwitch subnet requested is part of the given network and that its routes are
+// exposed. The vSwitch subnet of the previous reconciliation is removed if it has been changed or removed. Routes are
+// only exposed to the vSwitch for networks owned by the shoot, existing networks keep their setting.
+//
+// PARAMETERS
+// ctx         context.Context                      Execution context
+// client      *hcloud.Client                       HCloud client
+// network     *hcloud.Network                      HCloud network
+// vSwitch     *apis.InfrastructureConfigVSwitch    VSwitch struct
+// networkZone hcloud.NetworkZone                   VSwitch subnet network zone
+// isOwned     bool                                 True if the network is owned by the shoot
+// networkIDs  *apis.InfrastructureConfigNetworkIDs Network IDs struct of the previous reconciliation
+// result      *apis.InfrastructureConfigNetworkIDs Network IDs struct to update
+func ensureVSwitchSubnet(ctx context.Context, client *hcloud.Client, network *hcloud.Network, vSwitch *apis.InfrastructureConfigVSwitch, networkZone hcloud.NetworkZone, isOwned bool, networkIDs *apis.InfrastructureConfigNetworkIDs, result *apis.InfrastructureConfigNetworkIDs) error {
+	var previousSubnet string
 
-			resultData := ctx.Value(controller.CtxWrapDataKey("MethodData")).(*controller.InfrastructureReconcileMethodData)
-			resultData.NetworkID = network.ID
+	if nil != networkIDs && networkIDs.Workers == result.Workers {
+		previousSubnet = networkIDs.VSwitchSubnet
+	}
+
+	if nil == vSwitch {
+		return ensureSubnetDeleted(ctx, client, network, hcloud.NetworkSubnetTypeVSwitch, previousSubnet)
+	}
+
+	_, ipRange, err := net.ParseCIDR(vSwitch.Cidr)
+	if nil != err {
+		return err
+	}
+
+	if previousSubnet != ipRange.String() {
+		err = ensureSubnetDeleted(ctx, client, network, hcloud.NetworkSubnetTypeVSwitch, previousSubnet)
+		if nil != err {
+			return err
+		}
+	}
+
+	if !isSubnetOf(ipRange, network.IPRange) {
+		return fmt.Errorf("VSwitch CIDR %q is not part of the IP range %q of the network %q", ipRange.String(), network.IPRange.String(), network.Name)
+	}
+
+	subnetFound := false
+
+	for _, subnet := range network.Subnets {
+		if subnet.IPRange.String() != ipRange.String() {
+			continue
+		}
+
+		if subnet.Type != hcloud.NetworkSubnetTypeVSwitch || subnet.VSwitchID != vSwitch.ID {
+			return fmt.Errorf("Existing subnet %q of network %q does not match the expected vSwitch %d", ipRange.String(), network.Name, vSwitch.ID)
+		}
+
+		subnetFound = true
+		break
+	}
+
+	result.VSwitch = strconv.FormatInt(vSwitch.ID, 10)
+
+	if !subnetFound {
+		opts := hcloud.NetworkAddSubnetOpts{
+			Subnet: hcloud.NetworkSubnet{
+				Type:        hcloud.NetworkSubnetTypeVSwitch,
+				IPRange:     ipRange,
+				NetworkZone: networkZone,
+				VSwitchID:   vSwitch.ID,
+			},
+		}
+
+		action, _, err := client.Network.AddSubnet(ctx, network, opts)
+		if nil != err {
+			return err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return err
+		}
+
+		result.VSwitchSubnet = ipRange.String()
+	} else if isOwned || previousSubnet == ipRange.String() {
+		// Only claim ownership of a subnet found in an existing network if it has been added by a previous reconciliation.
+		result.VSwitchSubnet = ipRange.String()
+	}
+
+	if isOwned && !network.ExposeRoutesToVSwitch {
+		_, _, err = client.Network.Update(ctx, network, hcloud.NetworkUpdateOpts{ExposeRoutesToVSwitch: hcloud.Ptr(true)})
+		if nil != err {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// GetNetworkRouteUsage returns the route usage of the workers network. Routes of all shoots are counted for shared
+// networks. Nil is returned if the workers network is unknown.
+//
+// PARAMETERS
+// ctx      context.Context                      Execution context
+// client   *hcloud.Client                       HCloud client
+// networks *apis.InfrastructureConfigNetworkIDs Network IDs struct
+func GetNetworkRouteUsage(ctx context.Context, client *hcloud.Client, networks *apis.InfrastructureConfigNetworkIDs) (*apis.InfrastructureStatusNetworkRoutes, error) {
+	if networks == nil || "" == networks.Workers {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(networks.Workers, 10, 64)
+	if nil != err {
+		return nil, err
+	}
 
-		return network.ID, nil
+	network, _, err := client.Network.GetByID(ctx, id)
+	if nil != err {
+		return nil, err
+	} else if network == nil {
+		return nil, fmt.Errorf("Workers network %d not found", id)
 	}
 
-	return -1, nil
+	return &apis.InfrastructureStatusNetworkRoutes{
+		Used:  len(network.Routes),
+		Limit: apis.NetworkRouteLimit,
+	}, nil
 }
 
 // EnsureNetworksDeleted removes any previously created network resources.
@@ -108,16 +698,44 @@ func EnsureNetworks(ctx context.Context, client *hcloud.Client, namespace, zone
 // PARAMETERS
 // ctx       context.Context                      Execution context
 // client    *hcloud.Client                       HCloud client
+// owner     *controller.ResourceOwner            Resource owner
 // namespace string                               Shoot namespace
 // networks  *apis.InfrastructureConfigNetworkIDs Network IDs struct
-func EnsureNetworksDeleted(ctx context.Context, client *hcloud.Client, namespace string, networks *apis.InfrastructureConfigNetworkIDs) error {
+func EnsureNetworksDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string, networks *apis.InfrastructureConfigNetworkIDs) error {
+	if networks != nil && networks.WorkersExisting {
+		return ensureExistingNetworkSubnetsDeleted(ctx, client, networks)
+	}
+
+	if networks != nil && networks.WorkersShared {
+		return ensureSharedNetworkReleased(ctx, client, owner, networks)
+	}
+
 	if networks != nil && "" != networks.Workers {
-		name := fmt.Sprintf("%s-workers", namespace)
+		id, err := strconv.ParseInt(networks.Workers, 10, 64)
+		if nil != err {
+			return err
+		}
 
-		network, _, err := client.Network.GetByName(ctx, name)
+		network, _, err := client.Network.GetByID(ctx, id)
 		if nil != err {
 			return err
-		} else if network != nil {
+		}
+
+		isReferenced := network != nil
+
+		if network == nil {
+			network, _, err = client.Network.GetByName(ctx, fmt.Sprintf("%s-workers", namespace))
+			if nil != err {
+				return err
+			}
+		}
+
+		if network != nil {
+			if !owner.IsOwnerOf(network.Labels, networkRole) && !(isReferenced && owner.IsAdoptable(network.Labels, networkRole)) {
+				owner.RefuseDeletion("network", network.Name, network.ID)
+				return nil
+			}
+
 			_, err := client.Network.Delete(ctx, network)
 			if nil != err {
 				return err
@@ -127,3 +745,126 @@ func EnsureNetworksDeleted(ctx context.Context, client *hcloud.Client, namespace
 
 	return nil
 }
+
+// ensureExistingNetworkSubnetsDeleted removes the workers and vSwitch subnets previously added to an existing network.
+//
+// PARAMETERS
+// ctx      context.Context                      Execution context
+// client   *hcloud.Client                       HCloud client
+// networks *apis.InfrastructureConfigNetworkIDs Network IDs struct
+func ensureExistingNetworkSubnetsDeleted(ctx context.Context, client *hcloud.Client, networks *apis.InfrastructureConfigNetworkIDs) error {
+	if "" == networks.Workers || ("" == networks.WorkersSubnet && "" == networks.VSwitchSubnet) {
+		return nil
+	}
+
+	id, err := strconv.ParseInt(networks.Workers, 10, 64)
+	if nil != err {
+		return err
+	}
+
+	network, _, err := client.Network.GetByID(ctx, id)
+	if nil != err {
+		return err
+	} else if network == nil {
+		return nil
+	}
+
+	err = ensureSubnetDeleted(ctx, client, network, hcloud.NetworkSubnetTypeVSwitch, networks.VSwitchSubnet)
+	if nil != err {
+		return err
+	}
+
+	if "" == networks.WorkersSubnet {
+		return nil
+	}
+
+	_, workersSubnet, err := net.ParseCIDR(networks.WorkersSubnet)
+	if nil != err {
+		return err
+	}
+
+	// Routes via nodes, e.g. added by the cloud controller manager, block the removal of the workers subnet.
+	err = ensureSubnetRoutesDeleted(ctx, client, network, []*net.IPNet{workersSubnet})
+	if nil != err {
+		return err
+	}
+
+	return ensureSubnetDeleted(ctx, client, network, hcloud.NetworkSubnetTypeCloud, networks.WorkersSubnet)
+}
+
+// ensureSubnetRoutesDeleted removes all routes of the network with a gateway in any of the subnets given.
+//
+// PARAMETERS
+// ctx     context.Context Execution context
+// client  *hcloud.Client  HCloud client
+// network *hcloud.Network HCloud network
+// subnets []*net.IPNet    Subnets containing the gateways of the routes to remove
+func ensureSubnetRoutesDeleted(ctx context.Context, client *hcloud.Client, network *hcloud.Network, subnets []*net.IPNet) error {
+	for _, route := range network.Routes {
+		if !isIPInSubnets(route.Gateway, subnets) {
+			continue
+		}
+
+		action, _, err := client.Network.DeleteRoute(ctx, network, hcloud.NetworkDeleteRouteOpts{Route: route})
+		if nil != err {
+			return err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ensureSubnetDeleted removes the subnet of the given type and CIDR from the network if present.
+//
+// PARAMETERS
+// ctx        context.Context          Execution context
+// client     *hcloud.Client           HCloud client
+// network    *hcloud.Network          HCloud network
+// subnetType hcloud.NetworkSubnetType Subnet type
+// cidr       string                   Subnet CIDR
+func ensureSubnetDeleted(ctx context.Context, client *hcloud.Client, network *hcloud.Network, subnetType hcloud.NetworkSubnetType, cidr string) error {
+	if "" == cidr {
+		return nil
+	}
+
+	for _, subnet := range network.Subnets {
+		if subnet.Type != subnetType || subnet.IPRange.String() != cidr {
+			continue
+		}
+
+		action, _, err := client.Network.DeleteSubnet(ctx, network, hcloud.NetworkDeleteSubnetOpts{Subnet: subnet})
+		if nil != err {
+			return err
+		}
+
+		return client.Action.WaitFor(ctx, action)
+	}
+
+	return nil
+}
+
+// isSubnetOf returns true if the given subnet is fully contained in the given network.
+//
+// PARAMETERS
+// subnet  *net.IPNet Subnet to check
+// network *net.IPNet Network the subnet should be part of
+func isSubnetOf(subnet, network *net.IPNet) bool {
+	subnetOnes, subnetBits := subnet.Mask.Size()
+	networkOnes, networkBits := network.Mask.Size()
+
+	return subnetBits == networkBits && subnetOnes >= networkOnes && network.Contains(subnet.IP)
+}
+
+// isOverlapping returns true if the given IP ranges overlap.
+//
+// PARAMETERS
+// ipRange      *net.IPNet IP range to check
+// otherIPRange *net.IPNet IP range to check against
+func isOverlapping(ipRange, otherIPRange *net.IPNet) bool {
+	return ipRange.Contains(otherIPRange.IP) || otherIPRange.Contains(ipRange.IP)
+}
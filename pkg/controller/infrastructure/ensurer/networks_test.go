@@ -0,0 +1,309 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+const testActionResponse = `{"action": {"id": 1, "command": "test", "status": "success", "progress": 100, "started": "2016-01-30T23:50:00+00:00", "resources": []}}`
+
+func writeNetworkResponse(res http.ResponseWriter, status int, id int64, ipRange string, subnets string) {
+	res.Header().Add("Content-Type", "application/json; charset=utf-8")
+	res.WriteHeader(status)
+
+	_, _ = fmt.Fprintf(res, `{"network": {"id": %d, "name": "test-network", "ip_range": %q, "subnets": [%s], "routes": [], "servers": [], "labels": {}, "created": "2016-01-30T23:50:00+00:00"}}`, id, ipRange, subnets)
+}
+
+func decodeRequestBody(req *http.Request) map[string]interface{} {
+	body, err := io.ReadAll(req.Body)
+	Expect(err).NotTo(HaveOccurred())
+
+	var data map[string]interface{}
+	Expect(json.Unmarshal(body, &data)).To(Succeed())
+
+	return data
+}
+
+func parseIPRange(cidr string) *net.IPNet {
+	_, ipRange, err := net.ParseCIDR(cidr)
+	Expect(err).NotTo(HaveOccurred())
+
+	return ipRange
+}
+
+func toStrings(ipRanges []*net.IPNet) []string {
+	var cidrs []string
+
+	for _, ipRange := range ipRanges {
+		cidrs = append(cidrs, ipRange.String())
+	}
+
+	return cidrs
+}
+
+var _ = Describe("Networks", func() {
+	Describe("#getUncoveredIPRanges", func() {
+		It("should return the IP range if no subnet covers it", func() {
+			Expect(toStrings(getUncoveredIPRanges(parseIPRange("10.250.0.0/18"), nil))).To(Equal([]string{"10.250.0.0/18"}))
+		})
+
+		It("should return nothing if a subnet covers it", func() {
+			Expect(getUncoveredIPRanges(parseIPRange("10.250.0.0/19"), []*net.IPNet{parseIPRange("10.250.0.0/19")})).To(BeEmpty())
+		})
+
+		It("should return the space added by expanding the IP range", func() {
+			uncovered := getUncoveredIPRanges(parseIPRange("10.250.0.0/17"), []*net.IPNet{parseIPRange("10.250.0.0/19")})
+			Expect(toStrings(uncovered)).To(Equal([]string{"10.250.32.0/19", "10.250.64.0/18"}))
+		})
+	})
+
+	Describe("#getFreeIPRange", func() {
+		It("should return the first IP range not overlapping any subnet", func() {
+			networkSubnets := []hcloud.NetworkSubnet{
+				{Type: hcloud.NetworkSubnetTypeCloud, IPRange: parseIPRange("10.250.0.0/19"), NetworkZone: hcloud.NetworkZoneEUCentral},
+			}
+
+			freeRange := getFreeIPRange(parseIPRange("10.250.0.0/18"), parseIPRange("10.250.16.0/20"), networkSubnets)
+			Expect(freeRange).NotTo(BeNil())
+			Expect(freeRange.String()).To(Equal("10.250.32.0/20"))
+		})
+
+		It("should return nil if no IP range is free", func() {
+			networkSubnets := []hcloud.NetworkSubnet{
+				{Type: hcloud.NetworkSubnetTypeCloud, IPRange: parseIPRange("10.250.0.0/19"), NetworkZone: hcloud.NetworkZoneEUCentral},
+			}
+
+			Expect(getFreeIPRange(parseIPRange("10.250.0.0/19"), parseIPRange("10.250.16.0/20"), networkSubnets)).To(BeNil())
+		})
+	})
+
+	Describe("#getWorkersSubnets", func() {
+		It("should split the workers CIDR by network zone", func() {
+			subnets, err := getWorkersSubnets(parseIPRange("10.250.0.0/18"), hcloud.NetworkZoneEUCentral, "hel1", []string{"hel1-dc2", "ash-dc1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subnets).To(HaveLen(2))
+			Expect(subnets[hcloud.NetworkZoneEUCentral].String()).To(Equal("10.250.0.0/20"))
+			Expect(subnets[hcloud.NetworkZoneUSEast].String()).To(Equal("10.250.16.0/20"))
+		})
+	})
+
+	Describe("#EnsureNetworks", func() {
+		var (
+			mockTestEnv  mock.MockTestEnv
+			owner        *controller.ResourceOwner
+			addedSubnets []map[string]interface{}
+			updates      []map[string]interface{}
+		)
+
+		BeforeEach(func() {
+			mockTestEnv = mock.NewMockTestEnv()
+			owner = controller.NewResourceOwner("shoot-uid", "", nil, nil)
+			addedSubnets = nil
+			updates = nil
+
+			mockTestEnv.Mux.HandleFunc("/networks/42/actions/add_subnet", func(res http.ResponseWriter, req *http.Request) {
+				addedSubnets = append(addedSubnets, decodeRequestBody(req))
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+				_, _ = res.Write([]byte(testActionResponse))
+			})
+		})
+
+		AfterEach(func() {
+			mockTestEnv.Teardown()
+		})
+
+		It("should create the workers network with a range containing the vSwitch subnet", func() {
+			var createRequest map[string]interface{}
+
+			mockTestEnv.Mux.HandleFunc("/networks", func(res http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodPost {
+					createRequest = decodeRequestBody(req)
+					writeNetworkResponse(res, http.StatusCreated, 42, "10.250.0.0/17", `{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central"}`)
+
+					return
+				}
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+				_, _ = res.Write([]byte(`{"networks": []}`))
+			})
+
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodPut))
+				updates = append(updates, decodeRequestBody(req))
+				writeNetworkResponse(res, http.StatusOK, 42, "10.250.0.0/17", "")
+			})
+
+			networks := &apis.InfrastructureConfigNetworks{
+				WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: "10.250.0.0/19", Zone: hcloud.NetworkZoneEUCentral},
+				VSwitch:              &apis.InfrastructureConfigVSwitch{ID: 12345, Cidr: "10.250.64.0/24"},
+			}
+
+			networkIDs, err := EnsureNetworks(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud", "hel1", "hel1-dc2", nil, networks, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkIDs.Workers).To(Equal("42"))
+			Expect(networkIDs.VSwitchSubnet).To(Equal("10.250.64.0/24"))
+
+			Expect(createRequest["ip_range"]).To(Equal("10.250.0.0/17"))
+			Expect(createRequest["subnets"]).To(HaveLen(1))
+
+			Expect(addedSubnets).To(HaveLen(1))
+			Expect(addedSubnets[0]).To(HaveKeyWithValue("type", "vswitch"))
+			Expect(addedSubnets[0]).To(HaveKeyWithValue("ip_range", "10.250.64.0/24"))
+			Expect(addedSubnets[0]).To(HaveKeyWithValue("vswitch_id", BeNumerically("==", 12345)))
+
+			Expect(updates).To(HaveLen(1))
+			Expect(updates[0]).To(HaveKeyWithValue("expose_routes_to_vswitch", true))
+		})
+
+		It("should not expose the routes of an existing network to the vSwitch", func() {
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+				writeNetworkResponse(res, http.StatusOK, 42, "10.0.0.0/8", "")
+			})
+
+			networks := &apis.InfrastructureConfigNetworks{
+				WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: "10.250.0.0/19", Zone: hcloud.NetworkZoneEUCentral},
+				Existing:             &apis.InfrastructureConfigExistingNetwork{ID: 42},
+				VSwitch:              &apis.InfrastructureConfigVSwitch{ID: 12345, Cidr: "10.251.0.0/24"},
+			}
+
+			networkIDs, err := EnsureNetworks(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud", "hel1", "hel1-dc2", nil, networks, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkIDs.WorkersExisting).To(BeTrue())
+			Expect(networkIDs.WorkersSubnet).To(Equal("10.250.0.0/19"))
+			Expect(networkIDs.VSwitchSubnet).To(Equal("10.251.0.0/24"))
+
+			Expect(addedSubnets).To(HaveLen(2))
+			Expect(addedSubnets[1]).To(HaveKeyWithValue("type", "vswitch"))
+		})
+
+		It("should not claim a vSwitch subnet of an existing network not added by the shoot", func() {
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				writeNetworkResponse(res, http.StatusOK, 42, "10.0.0.0/8", `{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central"}, {"type": "vswitch", "ip_range": "10.251.0.0/24", "network_zone": "eu-central", "vswitch_id": 12345}`)
+			})
+
+			networks := &apis.InfrastructureConfigNetworks{
+				WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: "10.250.0.0/19", Zone: hcloud.NetworkZoneEUCentral},
+				Existing:             &apis.InfrastructureConfigExistingNetwork{ID: 42},
+				VSwitch:              &apis.InfrastructureConfigVSwitch{ID: 12345, Cidr: "10.251.0.0/24"},
+			}
+
+			networkIDs, err := EnsureNetworks(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud", "hel1", "hel1-dc2", nil, networks, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkIDs.VSwitch).To(Equal("12345"))
+			Expect(networkIDs.VSwitchSubnet).To(BeEmpty())
+			Expect(addedSubnets).To(BeEmpty())
+		})
+
+		It("should adopt a workers network of an earlier version referenced by the status", func() {
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				if req.Method == http.MethodPut {
+					updates = append(updates, decodeRequestBody(req))
+				}
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+				_, _ = res.Write([]byte(`{"network": {"id": 42, "name": "shoot--foobar--hcloud-workers", "ip_range": "10.250.0.0/19", "subnets": [{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central"}], "routes": [], "servers": [], "labels": {"hcloud.provider.extensions.gardener.cloud/role": "workers-network-v1"}, "created": "2016-01-30T23:50:00+00:00"}}`))
+			})
+
+			networks := &apis.InfrastructureConfigNetworks{
+				WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: "10.250.0.0/19", Zone: hcloud.NetworkZoneEUCentral},
+			}
+
+			networkIDs, err := EnsureNetworks(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud", "hel1", "hel1-dc2", nil, networks, &apis.InfrastructureConfigNetworkIDs{Workers: "42"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(networkIDs.Workers).To(Equal("42"))
+
+			Expect(updates).To(HaveLen(1))
+			Expect(updates[0]["labels"]).To(HaveKeyWithValue(controller.LabelClusterID, "shoot-uid"))
+		})
+
+		It("should refuse a workers network of an earlier version not referenced by the status", func() {
+			mockTestEnv.Mux.HandleFunc("/networks", func(res http.ResponseWriter, req *http.Request) {
+				Expect(req.Method).To(Equal(http.MethodGet))
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusOK)
+				_, _ = res.Write([]byte(`{"networks": [{"id": 42, "name": "shoot--foobar--hcloud-workers", "ip_range": "10.250.0.0/19", "subnets": [], "routes": [], "servers": [], "labels": {"hcloud.provider.extensions.gardener.cloud/role": "workers-network-v1"}, "created": "2016-01-30T23:50:00+00:00"}]}`))
+			})
+
+			networks := &apis.InfrastructureConfigNetworks{
+				WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: "10.250.0.0/19", Zone: hcloud.NetworkZoneEUCentral},
+			}
+
+			_, err := EnsureNetworks(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud", "hel1", "hel1-dc2", nil, networks, nil)
+			Expect(err).To(MatchError(ContainSubstring("is not owned by this shoot")))
+		})
+	})
+
+	Describe("#EnsureNetworksDeleted", func() {
+		var mockTestEnv mock.MockTestEnv
+
+		BeforeEach(func() {
+			mockTestEnv = mock.NewMockTestEnv()
+		})
+
+		AfterEach(func() {
+			mockTestEnv.Teardown()
+		})
+
+		It("should remove the workers and vSwitch subnets added to an existing network", func() {
+			var deletedSubnets []string
+
+			mockTestEnv.Mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+				writeNetworkResponse(res, http.StatusOK, 42, "10.0.0.0/8", `{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central"}, {"type": "vswitch", "ip_range": "10.251.0.0/24", "network_zone": "eu-central", "vswitch_id": 12345}, {"type": "cloud", "ip_range": "10.1.0.0/16", "network_zone": "eu-central"}`)
+			})
+
+			mockTestEnv.Mux.HandleFunc("/networks/42/actions/delete_subnet", func(res http.ResponseWriter, req *http.Request) {
+				deletedSubnets = append(deletedSubnets, decodeRequestBody(req)["ip_range"].(string))
+
+				res.Header().Add("Content-Type", "application/json; charset=utf-8")
+				res.WriteHeader(http.StatusCreated)
+				_, _ = res.Write([]byte(testActionResponse))
+			})
+
+			networkIDs := &apis.InfrastructureConfigNetworkIDs{
+				Workers:         "42",
+				WorkersExisting: true,
+				WorkersSubnet:   "10.250.0.0/19",
+				VSwitchSubnet:   "10.251.0.0/24",
+			}
+
+			err := EnsureNetworksDeleted(context.TODO(), mockTestEnv.HcloudClient, controller.NewResourceOwner("shoot-uid", "", nil, nil), "shoot--foobar--hcloud", networkIDs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedSubnets).To(Equal([]string{"10.251.0.0/24", "10.250.0.0/19"}))
+		})
+	})
+})
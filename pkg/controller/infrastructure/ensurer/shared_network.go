@@ -0,0 +1,291 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"strconv"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+)
+
+const (
+	// sharedNetworkRole is the role label value of networks shared between shoots.
+	sharedNetworkRole = "shared-network-v1"
+	// sharedNetworkUpdateRetries is the number of attempts to update the references of a shared network.
+	sharedNetworkUpdateRetries = 5
+)
+
+// ensureSharedNetwork verifies that the shared network requested exists and is able to contain the worker subnets of
+// this shoot. Shared networks are created by the first shoot joining them and are marked as used by each shoot with a
+// reference label.
+//
+// PARAMETERS
+// ctx     context.Context                         Execution context
+// client  *hcloud.Client                          HCloud client
+// owner   *controller.ResourceOwner               Resource owner
+// shared  *apis.InfrastructureConfigSharedNetwork Shared network reference
+// subnets map[hcloud.NetworkZone]*net.IPNet       Worker subnets by network zone
+func ensureSharedNetwork(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, shared *apis.InfrastructureConfigSharedNetwork, subnets map[hcloud.NetworkZone]*net.IPNet) (*hcloud.Network, *apis.InfrastructureConfigNetworkIDs, error) {
+	_, ipRange, err := net.ParseCIDR(shared.Cidr)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	for _, subnet := range subnets {
+		if !isSubnetOf(subnet, ipRange) {
+			return nil, nil, fmt.Errorf("Workers subnet %q is not part of the IP range %q of the shared network %q", subnet.String(), ipRange.String(), shared.Name)
+		}
+	}
+
+	name := getSharedNetworkName(shared.Name)
+
+	network, _, err := client.Network.GetByName(ctx, name)
+	if nil != err {
+		return nil, nil, err
+	}
+
+	if network == nil {
+		labels := getSharedNetworkLabels(owner.IdentityLabels(sharedNetworkRole))
+
+		if "" != owner.ReferenceLabel() {
+			labels[owner.ReferenceLabel()] = "true"
+		}
+
+		opts := hcloud.NetworkCreateOpts{
+			Name:    name,
+			IPRange: ipRange,
+			Labels:  labels,
+		}
+
+		network, _, err = client.Network.Create(ctx, opts)
+		if nil != err {
+			return nil, nil, err
+		}
+	} else {
+		if !isSharedNetwork(owner, network.Labels) {
+			return nil, nil, fmt.Errorf("Network %q (%d) is not a shared network managed by this garden", network.Name, network.ID)
+		}
+
+		if network.IPRange.String() != ipRange.String() {
+			return nil, nil, fmt.Errorf("Shared network %q uses the IP range %q instead of %q", shared.Name, network.IPRange.String(), ipRange.String())
+		}
+
+		network, err = updateSharedNetworkReference(ctx, client, owner, network, true)
+		if nil != err {
+			return nil, nil, err
+		} else if network == nil {
+			return nil, nil, fmt.Errorf("Shared network %q has been deleted concurrently", shared.Name)
+		}
+	}
+
+	result := &apis.InfrastructureConfigNetworkIDs{
+		Workers:       strconv.FormatInt(network.ID, 10),
+		WorkersName:   network.Name,
+		WorkersShared: true,
+	}
+
+	return network, result, nil
+}
+
+// ensureSharedNetworkReleased removes the worker subnets and routes of this shoot from the shared network given.
+// The shared network is only removed if no other shoot references it anymore.
+//
+// PARAMETERS
+// ctx      context.Context                      Execution context
+// client   *hcloud.Client                       HCloud client
+// owner    *controller.ResourceOwner            Resource owner
+// networks *apis.InfrastructureConfigNetworkIDs Network IDs struct
+func ensureSharedNetworkReleased(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, networks *apis.InfrastructureConfigNetworkIDs) error {
+	if "" == networks.Workers {
+		return nil
+	}
+
+	id, err := strconv.ParseInt(networks.Workers, 10, 64)
+	if nil != err {
+		return err
+	}
+
+	network, _, err := client.Network.GetByID(ctx, id)
+	if nil != err {
+		return err
+	} else if network == nil {
+		return nil
+	}
+
+	if !isSharedNetwork(owner, network.Labels) {
+		owner.RefuseDeletion("shared network", network.Name, network.ID)
+		return nil
+	}
+
+	var subnets []*net.IPNet
+
+	for _, cidr := range networks.WorkersSubnets {
+		_, subnet, err := net.ParseCIDR(cidr)
+		if nil != err {
+			return err
+		}
+
+		subnets = append(subnets, subnet)
+	}
+
+	err = ensureSubnetRoutesDeleted(ctx, client, network, subnets)
+	if nil != err {
+		return err
+	}
+
+	for _, networkSubnet := range network.Subnets {
+		if networkSubnet.Type != hcloud.NetworkSubnetTypeCloud || !isIPInSubnets(networkSubnet.IPRange.IP, subnets) {
+			continue
+		}
+
+		action, _, err := client.Network.DeleteSubnet(ctx, network, hcloud.NetworkDeleteSubnetOpts{Subnet: networkSubnet})
+		if nil != err {
+			return err
+		}
+
+		err = client.Action.WaitFor(ctx, action)
+		if nil != err {
+			return err
+		}
+	}
+
+	// The subnets and servers of other shoots left are only known once the ones of this shoot have been removed.
+	network, _, err = client.Network.GetByID(ctx, network.ID)
+	if nil != err || network == nil {
+		return err
+	}
+
+	network, err = updateSharedNetworkReference(ctx, client, owner, network, false)
+	if nil != err || network == nil {
+		return err
+	}
+
+	if controller.HasReferences(getSharedNetworkLabels(network.Labels)) {
+		return nil
+	}
+
+	// Shoots joining the shared network concurrently or not referencing it yet still use it with their subnets,
+	// servers and load balancers.
+	if len(network.Subnets) > 0 || len(network.Servers) > 0 || len(network.LoadBalancers) > 0 {
+		return nil
+	}
+
+	_, err = client.Network.Delete(ctx, network)
+
+	return err
+}
+
+// updateSharedNetworkReference adds or removes the reference label of the shoot to or from the given shared network.
+// Shared networks are updated by all shoots using them without concurrency control. The network is read again after
+// each update and the update is retried if it has been overwritten concurrently. The network updated is returned or
+// nil if it has been deleted concurrently.
+//
+// PARAMETERS
+// ctx         context.Context           Execution context
+// client      *hcloud.Client            HCloud client
+// owner       *controller.ResourceOwner Resource owner
+// network     *hcloud.Network           Shared network
+// isReference bool                      True to add the reference label, false to remove it
+func updateSharedNetworkReference(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, network *hcloud.Network, isReference bool) (*hcloud.Network, error) {
+	referenceLabel := owner.ReferenceLabel()
+
+	for i := 0; i < sharedNetworkUpdateRetries; i++ {
+		labels := getSharedNetworkLabels(network.Labels)
+
+		if "" != referenceLabel {
+			if isReference {
+				labels[referenceLabel] = "true"
+			} else {
+				delete(labels, referenceLabel)
+			}
+		}
+
+		if maps.Equal(labels, network.Labels) {
+			return network, nil
+		}
+
+		_, _, err := client.Network.Update(ctx, network, hcloud.NetworkUpdateOpts{Labels: labels})
+		if nil != err {
+			if hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		network, _, err = client.Network.GetByID(ctx, network.ID)
+		if nil != err || network == nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("Failed to update the references of shared network %q changed concurrently", network.Name)
+}
+
+// getSharedNetworkName returns the HCloud network name of the shared network given.
+//
+// PARAMETERS
+// name string Shared network name
+func getSharedNetworkName(name string) string {
+	return fmt.Sprintf("shared-network-%s", name)
+}
+
+// getSharedNetworkLabels returns a copy of the given shared network labels without the shoot specific cluster ID.
+//
+// PARAMETERS
+// labels map[string]string Shared network labels
+func getSharedNetworkLabels(labels map[string]string) map[string]string {
+	sharedLabels := map[string]string{}
+	maps.Copy(sharedLabels, labels)
+
+	delete(sharedLabels, controller.LabelClusterID)
+
+	return sharedLabels
+}
+
+// isSharedNetwork returns true if the given labels identify a shared network managed by this garden. The cluster ID
+// is not checked as shared networks are used by multiple shoots.
+//
+// PARAMETERS
+// owner  *controller.ResourceOwner Resource owner
+// labels map[string]string         Network labels
+func isSharedNetwork(owner *controller.ResourceOwner, labels map[string]string) bool {
+	return owner.IsManagerOf(getSharedNetworkLabels(labels), sharedNetworkRole)
+}
+
+// isIPInSubnets returns true if the given IP is part of any of the subnets given.
+//
+// PARAMETERS
+// ip      net.IP       IP to check
+// subnets []*net.IPNet Subnets to check against
+func isIPInSubnets(ip net.IP, subnets []*net.IPNet) bool {
+	for _, subnet := range subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+var _ = Describe("Shared network", func() {
+	var (
+		mockTestEnv    mock.MockTestEnv
+		owner          *controller.ResourceOwner
+		otherReference string
+		labels         map[string]string
+		subnets        []string
+		servers        []int64
+		updates        int
+		deleted        bool
+		onUpdate       func()
+	)
+
+	writeNetwork := func(res http.ResponseWriter, format string) {
+		labelsData, err := json.Marshal(labels)
+		Expect(err).NotTo(HaveOccurred())
+
+		var subnetsData []map[string]string
+		for _, subnet := range subnets {
+			subnetsData = append(subnetsData, map[string]string{"type": "cloud", "ip_range": subnet, "network_zone": "eu-central", "gateway": "10.0.0.1"})
+		}
+
+		subnetsJSON, err := json.Marshal(subnetsData)
+		Expect(err).NotTo(HaveOccurred())
+
+		serversJSON, err := json.Marshal(servers)
+		Expect(err).NotTo(HaveOccurred())
+
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+		res.WriteHeader(http.StatusOK)
+
+		_, _ = fmt.Fprintf(res, format, fmt.Sprintf(`{"id": 7, "name": "shared-network-test", "ip_range": "10.0.0.0/8", "subnets": %s, "routes": [], "servers": %s, "load_balancers": [], "labels": %s, "created": "2016-01-30T23:50:00+00:00"}`, subnetsJSON, serversJSON, labelsData))
+	}
+
+	BeforeEach(func() {
+		mockTestEnv = mock.NewMockTestEnv()
+		owner = controller.NewResourceOwner("shoot-uid", "", nil, nil)
+		otherReference = controller.LabelReferencePrefix + "other-uid"
+		labels = map[string]string{controller.LabelRole: sharedNetworkRole, otherReference: "true"}
+		subnets = []string{"10.250.0.0/19"}
+		servers = []int64{}
+		updates = 0
+		deleted = false
+		onUpdate = nil
+
+		mockTestEnv.Mux.HandleFunc("/networks", func(res http.ResponseWriter, req *http.Request) {
+			Expect(req.URL.Query().Get("name")).To(Equal("shared-network-test"))
+			writeNetwork(res, `{"networks": [%s]}`)
+		})
+
+		mockTestEnv.Mux.HandleFunc("/networks/7", func(res http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodDelete:
+				deleted = true
+				res.WriteHeader(http.StatusNoContent)
+
+				return
+			case http.MethodPut:
+				updates++
+				labels = map[string]string{}
+
+				for key, value := range decodeRequestBody(req)["labels"].(map[string]interface{}) {
+					labels[key] = value.(string)
+				}
+
+				if nil != onUpdate {
+					onUpdate()
+				}
+			}
+
+			writeNetwork(res, `{"network": %s}`)
+		})
+
+		mockTestEnv.Mux.HandleFunc("/networks/7/actions/delete_subnet", func(res http.ResponseWriter, req *http.Request) {
+			ipRange := decodeRequestBody(req)["ip_range"].(string)
+
+			var remaining []string
+			for _, subnet := range subnets {
+				if subnet != ipRange {
+					remaining = append(remaining, subnet)
+				}
+			}
+
+			subnets = remaining
+
+			res.Header().Add("Content-Type", "application/json; charset=utf-8")
+			res.WriteHeader(http.StatusCreated)
+			_, _ = res.Write([]byte(testActionResponse))
+		})
+	})
+
+	AfterEach(func() {
+		mockTestEnv.Teardown()
+	})
+
+	Describe("#ensureSharedNetwork", func() {
+		It("should retry adding the reference overwritten concurrently", func() {
+			onUpdate = func() {
+				// Another shoot writes the labels read before the first update.
+				onUpdate = nil
+				labels = map[string]string{controller.LabelRole: sharedNetworkRole, otherReference: "true"}
+			}
+
+			shared := &apis.InfrastructureConfigSharedNetwork{Name: "test", Cidr: "10.0.0.0/8"}
+			workersSubnets := map[hcloud.NetworkZone]*net.IPNet{hcloud.NetworkZoneEUCentral: parseIPRange("10.251.0.0/19")}
+
+			network, networkIDs, err := ensureSharedNetwork(context.TODO(), mockTestEnv.HcloudClient, owner, shared, workersSubnets)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updates).To(Equal(2))
+			Expect(network.Labels).To(HaveKey(otherReference))
+			Expect(network.Labels).To(HaveKey(owner.ReferenceLabel()))
+			Expect(networkIDs.WorkersShared).To(BeTrue())
+		})
+	})
+
+	Describe("#ensureSharedNetworkReleased", func() {
+		var networkIDs *apis.InfrastructureConfigNetworkIDs
+
+		BeforeEach(func() {
+			subnets = append(subnets, "10.251.0.0/19")
+			labels[owner.ReferenceLabel()] = "true"
+
+			networkIDs = &apis.InfrastructureConfigNetworkIDs{
+				Workers:        "7",
+				WorkersShared:  true,
+				WorkersSubnets: map[string]string{string(hcloud.NetworkZoneEUCentral): "10.251.0.0/19"},
+			}
+		})
+
+		It("should retry removing the reference overwritten concurrently", func() {
+			onUpdate = func() {
+				// Another shoot writes the labels read before the first update.
+				onUpdate = nil
+				labels = map[string]string{controller.LabelRole: sharedNetworkRole, otherReference: "true", owner.ReferenceLabel(): "true"}
+			}
+
+			Expect(ensureSharedNetworkReleased(context.TODO(), mockTestEnv.HcloudClient, owner, networkIDs)).To(Succeed())
+			Expect(updates).To(Equal(2))
+			Expect(labels).NotTo(HaveKey(owner.ReferenceLabel()))
+			Expect(subnets).To(Equal([]string{"10.250.0.0/19"}))
+			Expect(deleted).To(BeFalse())
+		})
+
+		It("should delete the shared network no longer used", func() {
+			delete(labels, otherReference)
+			subnets = []string{"10.251.0.0/19"}
+
+			Expect(ensureSharedNetworkReleased(context.TODO(), mockTestEnv.HcloudClient, owner, networkIDs)).To(Succeed())
+			Expect(subnets).To(BeEmpty())
+			Expect(deleted).To(BeTrue())
+		})
+
+		It("should keep the shared network if subnets of other shoots are left", func() {
+			delete(labels, otherReference)
+
+			Expect(ensureSharedNetworkReleased(context.TODO(), mockTestEnv.HcloudClient, owner, networkIDs)).To(Succeed())
+			Expect(subnets).To(Equal([]string{"10.250.0.0/19"}))
+			Expect(deleted).To(BeFalse())
+		})
+
+		It("should keep the shared network if servers are attached", func() {
+			delete(labels, otherReference)
+			subnets = []string{"10.251.0.0/19"}
+			servers = []int64{42}
+
+			Expect(ensureSharedNetworkReleased(context.TODO(), mockTestEnv.HcloudClient, owner, networkIDs)).To(Succeed())
+			Expect(deleted).To(BeFalse())
+		})
+	})
+})
@@ -20,88 +20,157 @@ package ensurer
 import (
 	"context"
 	"fmt"
+	"maps"
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
-	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 )
 
-// EnsureSSHPublicKey verifies that the SSH public key resource requested is available.
+const (
+	// sshPublicKeyRole is the role label value of SSH public keys.
+	sshPublicKeyRole = "infrastructure-ssh-v1"
+	// sshPublicKeyUpdateRetries is the number of attempts to update the reference labels of a SSH public key changed
+	// concurrently by other shoots.
+	sshPublicKeyUpdateRetries = 5
+)
+
+// IsSSHPublicKeyUsedFunc returns true if the SSH public key with the given fingerprint is used by another shoot.
+type IsSSHPublicKeyUsedFunc func(ctx context.Context, fingerprint string) (bool, error)
+
+// EnsureSSHPublicKey verifies that the SSH public key resource requested is available. SSH public keys are shared
+// between all shoots using the same key and are marked as used by this shoot with a reference label. The key
+// replaced by a SSH keypair rotation is kept until the rotation has been completed and returned as the previous
+// fingerprint until then. All SSH public keys used before are released if the shoot disables SSH access.
 //
 // PARAMETERS
-// ctx       context.Context  Execution context
-// client    *hcloud.Client   HCloud client
-// publicKey []byte           SSH public key
-func EnsureSSHPublicKey(ctx context.Context, client *hcloud.Client, cluster *extensionscontroller.Cluster, infra *extensionsv1alpha1.Infrastructure) (string, error) {
+// ctx               context.Context                    Execution context
+// client            *hcloud.Client                     HCloud client
+// owner             *controller.ResourceOwner          Resource owner
+// cluster           *extensionscontroller.Cluster      Cluster struct
+// infra             *extensionsv1alpha1.Infrastructure Infrastructure struct
+// oldProviderStatus *apis.InfrastructureStatus         Infrastructure status of the last reconciliation
+// isUsed            IsSSHPublicKeyUsedFunc             Function checking if a SSH public key is used by another shoot
+func EnsureSSHPublicKey(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, cluster *extensionscontroller.Cluster, infra *extensionsv1alpha1.Infrastructure, oldProviderStatus *apis.InfrastructureStatus, isUsed IsSSHPublicKeyUsedFunc) (string, string, error) {
+	if nil != cluster && !apis.IsSSHAccessEnabled(cluster.Shoot) {
+		for _, oldFingerprint := range []string{oldProviderStatus.SSHFingerprint, oldProviderStatus.PreviousSSHFingerprint} {
+			err := EnsureSSHPublicKeyDeleted(ctx, client, owner, oldFingerprint, isUsed)
+			if nil != err {
+				return "", "", err
+			}
+		}
+
+		return "", "", nil
+	}
+
 	publicKey := infra.Spec.SSHPublicKey
 
 	if len(publicKey) == 0 {
-		return "", fmt.Errorf("SSH public key given is empty")
+		return "", "", fmt.Errorf("SSH public key given is empty")
 	}
 
-	oldProviderStatus, err := transcoder.DecodeInfrastructureStatus(infra.Status.GetProviderStatus())
+	fingerprint, err := apis.GetSSHFingerprint(publicKey)
 	if nil != err {
-		return "", err
+		return "", "", err
 	}
 
-	oldFingerprint := oldProviderStatus.SSHFingerprint
+	previousFingerprint := oldProviderStatus.PreviousSSHFingerprint
 
-	fingerprint, err := apis.GetSSHFingerprint(publicKey)
-	if nil != err {
-		return "", err
+	if oldProviderStatus.SSHFingerprint != fingerprint {
+		if "" != previousFingerprint && previousFingerprint != fingerprint {
+			err := EnsureSSHPublicKeyDeleted(ctx, client, owner, previousFingerprint, isUsed)
+			if nil != err {
+				return "", "", err
+			}
+		}
+
+		previousFingerprint = oldProviderStatus.SSHFingerprint
 	}
 
-	if oldFingerprint != fingerprint {
-		err := EnsureSSHPublicKeyDeleted(ctx, client, oldFingerprint)
+	if previousFingerprint == fingerprint {
+		previousFingerprint = ""
+	} else if "" != previousFingerprint && !isSSHKeypairRotationInProgress(cluster) {
+		err := EnsureSSHPublicKeyDeleted(ctx, client, owner, previousFingerprint, isUsed)
 		if nil != err {
-			return "", err
+			return "", "", err
 		}
-	}
 
-	labels := map[string]string{
-		"cluster.gardener.cloud/id":                      string(cluster.Shoot.GetUID()),
-		"cluster.gardener.cloud/name":                    cluster.Shoot.Name,
-		"hcloud.provider.extensions.gardener.cloud/role": "infrastructure-ssh-v1",
+		previousFingerprint = ""
 	}
 
 	sshKey, _, err := client.SSHKey.GetByFingerprint(ctx, fingerprint)
 	if nil != err {
-		return "", err
+		return "", "", err
 	} else if sshKey == nil {
+		labels := getSharedSSHPublicKeyLabels(owner.IdentityLabels(sshPublicKeyRole))
+
+		if "" != owner.ReferenceLabel() {
+			labels[owner.ReferenceLabel()] = "true"
+		}
+
 		opts := hcloud.SSHKeyCreateOpts{
 			Name:      fmt.Sprintf("infrastructure-ssh-%s", fingerprint),
 			PublicKey: string(publicKey),
 			Labels:    labels,
 		}
 
-		sshKey, _, err := client.SSHKey.Create(ctx, opts)
+		_, _, err := client.SSHKey.Create(ctx, opts)
 		if nil != err {
-			return "", err
+			return "", "", err
+		}
+	} else if isSharedSSHPublicKey(owner, sshKey.Labels) && "" != owner.ReferenceLabel() {
+		sshKey, err = updateSSHPublicKeyReference(ctx, client, owner, sshKey, true)
+		if nil != err {
+			return "", "", err
+		} else if sshKey == nil {
+			return "", "", fmt.Errorf("SSH key %q has been deleted concurrently", fingerprint)
 		}
-
-		resultData := ctx.Value(controller.CtxWrapDataKey("MethodData")).(*controller.InfrastructureReconcileMethodData)
-		resultData.SSHKeyID = sshKey.ID
 	}
 
-	return fingerprint, nil
+	return fingerprint, previousFingerprint, nil
 }
 
-// EnsureSSHPublicKeyDeleted removes any previously created SSH public key resource identified by the given fingerprint.
+// EnsureSSHPublicKeyDeleted releases the SSH public key resource identified by the given fingerprint. It is only
+// removed if no other shoot references it anymore.
 //
 // PARAMETERS
-// ctx         context.Context  Execution context
-// client      *hcloud.Client   HCloud client
-// fingerprint string           SSH fingerprint
-func EnsureSSHPublicKeyDeleted(ctx context.Context, client *hcloud.Client, fingerprint string) error {
+// ctx         context.Context           Execution context
+// client      *hcloud.Client            HCloud client
+// owner       *controller.ResourceOwner Resource owner
+// fingerprint string                    SSH fingerprint
+// isUsed      IsSSHPublicKeyUsedFunc    Function checking if a SSH public key is used by another shoot
+func EnsureSSHPublicKeyDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, fingerprint string, isUsed IsSSHPublicKeyUsedFunc) error {
 	if "" != fingerprint {
 		sshKey, _, err := client.SSHKey.GetByFingerprint(ctx, fingerprint)
 		if nil != err {
 			return err
 		} else if sshKey != nil {
+			if !isSharedSSHPublicKey(owner, sshKey.Labels) {
+				owner.RefuseDeletion("SSH key", sshKey.Name, sshKey.ID)
+				return nil
+			}
+
+			sshKey, err = updateSSHPublicKeyReference(ctx, client, owner, sshKey, false)
+			if nil != err || sshKey == nil {
+				return err
+			}
+
+			if controller.HasReferences(getSharedSSHPublicKeyLabels(sshKey.Labels)) {
+				return nil
+			}
+
+			// Shoots of this seed may use the SSH public key without having referenced it yet.
+			if nil != isUsed {
+				used, err := isUsed(ctx, fingerprint)
+				if nil != err || used {
+					return err
+				}
+			}
+
 			_, err := client.SSHKey.Delete(ctx, sshKey)
 			if nil != err {
 				return err
@@ -111,3 +180,98 @@ func EnsureSSHPublicKeyDeleted(ctx context.Context, client *hcloud.Client, finge
 
 	return nil
 }
+
+// updateSSHPublicKeyReference adds or removes the reference label of the shoot to or from the given SSH public key.
+// SSH public keys are updated by all shoots using them without concurrency control. The labels are read again after
+// each update and the update is retried if it has been overwritten concurrently. The SSH public key updated is
+// returned or nil if it has been deleted concurrently.
+//
+// PARAMETERS
+// ctx         context.Context           Execution context
+// client      *hcloud.Client            HCloud client
+// owner       *controller.ResourceOwner Resource owner
+// sshKey      *hcloud.SSHKey            HCloud SSH public key
+// isReference bool                      True to add the reference label, false to remove it
+func updateSSHPublicKeyReference(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, sshKey *hcloud.SSHKey, isReference bool) (*hcloud.SSHKey, error) {
+	referenceLabel := owner.ReferenceLabel()
+
+	for i := 0; i < sshPublicKeyUpdateRetries; i++ {
+		labels := getSharedSSHPublicKeyLabels(sshKey.Labels)
+
+		if "" != referenceLabel {
+			if isReference {
+				labels[referenceLabel] = "true"
+			} else {
+				delete(labels, referenceLabel)
+			}
+		}
+
+		if maps.Equal(labels, sshKey.Labels) {
+			return sshKey, nil
+		}
+
+		_, _, err := client.SSHKey.Update(ctx, sshKey, hcloud.SSHKeyUpdateOpts{Labels: labels})
+		if nil != err {
+			if hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+				return nil, nil
+			}
+
+			return nil, err
+		}
+
+		sshKey, _, err = client.SSHKey.GetByID(ctx, sshKey.ID)
+		if nil != err || sshKey == nil {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("Failed to update the references of SSH key %q changed concurrently", sshKey.Name)
+}
+
+// isSSHKeypairRotationInProgress returns true if a SSH keypair rotation of the shoot has been initiated but not
+// completed yet.
+//
+// PARAMETERS
+// cluster *extensionscontroller.Cluster Cluster struct
+func isSSHKeypairRotationInProgress(cluster *extensionscontroller.Cluster) bool {
+	if nil == cluster || nil == cluster.Shoot {
+		return false
+	}
+
+	return v1beta1helper.IsShootSSHKeypairRotationInitiationTimeAfterLastCompletionTime(cluster.Shoot.Status.Credentials)
+}
+
+// getSharedSSHPublicKeyLabels returns a copy of the given SSH public key labels. The shoot specific labels set by
+// earlier versions are replaced with a reference label for the shoot given.
+//
+// PARAMETERS
+// labels map[string]string SSH public key labels
+func getSharedSSHPublicKeyLabels(labels map[string]string) map[string]string {
+	sharedLabels := map[string]string{}
+
+	for key, value := range labels {
+		sharedLabels[key] = value
+	}
+
+	if clusterID, ok := sharedLabels[controller.LabelClusterID]; ok {
+		if "" != clusterID {
+			sharedLabels[controller.LabelReferencePrefix+clusterID] = "true"
+		}
+
+		delete(sharedLabels, controller.LabelClusterID)
+	}
+
+	delete(sharedLabels, "cluster.gardener.cloud/name")
+
+	return sharedLabels
+}
+
+// isSharedSSHPublicKey returns true if the given labels identify a SSH public key managed by this garden. The
+// cluster ID is not checked as SSH public keys are shared between shoots.
+//
+// PARAMETERS
+// owner  *controller.ResourceOwner Resource owner
+// labels map[string]string         SSH public key labels
+func isSharedSSHPublicKey(owner *controller.ResourceOwner, labels map[string]string) bool {
+	return owner.IsManagerOf(getSharedSSHPublicKeyLabels(labels), sshPublicKeyRole)
+}
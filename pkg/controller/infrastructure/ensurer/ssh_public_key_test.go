@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure infrastructure changes to be applied
+package ensurer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+const testSSHFingerprint = "b7:2f:30:a0:2f:6c:58:6c:21:04:58:61:ba:06:3b:2f"
+
+var _ = Describe("SSH public key", func() {
+	var (
+		mockTestEnv mock.MockTestEnv
+		owner       *controller.ResourceOwner
+		labels      map[string]string
+		updates     []map[string]interface{}
+		deleted     bool
+		onUpdate    func()
+	)
+
+	writeSSHKey := func(res http.ResponseWriter, format string) {
+		data, err := json.Marshal(labels)
+		Expect(err).NotTo(HaveOccurred())
+
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+		res.WriteHeader(http.StatusOK)
+
+		_, _ = fmt.Fprintf(res, format, fmt.Sprintf(`{"id": 1, "name": "infrastructure-ssh-test", "fingerprint": %q, "public_key": "ssh-ed25519 test", "labels": %s, "created": "2016-01-30T23:50:00+00:00"}`, testSSHFingerprint, data))
+	}
+
+	BeforeEach(func() {
+		mockTestEnv = mock.NewMockTestEnv()
+		owner = controller.NewResourceOwner("shoot-uid", "", nil, nil)
+		labels = map[string]string{controller.LabelRole: sshPublicKeyRole}
+		updates = nil
+		deleted = false
+		onUpdate = nil
+
+		mockTestEnv.Mux.HandleFunc("/ssh_keys", func(res http.ResponseWriter, req *http.Request) {
+			writeSSHKey(res, `{"ssh_keys": [%s]}`)
+		})
+
+		mockTestEnv.Mux.HandleFunc("/ssh_keys/1", func(res http.ResponseWriter, req *http.Request) {
+			switch req.Method {
+			case http.MethodDelete:
+				deleted = true
+				res.WriteHeader(http.StatusNoContent)
+
+				return
+			case http.MethodPut:
+				update := decodeRequestBody(req)
+				updates = append(updates, update)
+
+				labels = map[string]string{}
+
+				for key, value := range update["labels"].(map[string]interface{}) {
+					labels[key] = value.(string)
+				}
+
+				if nil != onUpdate {
+					onUpdate()
+				}
+			}
+
+			writeSSHKey(res, `{"ssh_key": %s}`)
+		})
+	})
+
+	AfterEach(func() {
+		mockTestEnv.Teardown()
+	})
+
+	Describe("#updateSSHPublicKeyReference", func() {
+		It("should retry an update overwritten concurrently", func() {
+			sshKey, _, err := mockTestEnv.HcloudClient.SSHKey.GetByID(context.TODO(), 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			onUpdate = func() {
+				// Another shoot writes the labels read before the first update.
+				onUpdate = nil
+				labels = map[string]string{controller.LabelRole: sshPublicKeyRole, controller.LabelReferencePrefix + "other-uid": "true"}
+			}
+
+			sshKey, err = updateSSHPublicKeyReference(context.TODO(), mockTestEnv.HcloudClient, owner, sshKey, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updates).To(HaveLen(2))
+			Expect(sshKey.Labels).To(HaveKey(controller.LabelReferencePrefix + "other-uid"))
+			Expect(sshKey.Labels).To(HaveKey(owner.ReferenceLabel()))
+		})
+	})
+
+	Describe("#EnsureSSHPublicKeyDeleted", func() {
+		BeforeEach(func() {
+			labels[owner.ReferenceLabel()] = "true"
+		})
+
+		It("should delete a SSH public key not referenced anymore", func() {
+			err := EnsureSSHPublicKeyDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, testSSHFingerprint, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).NotTo(HaveKey(owner.ReferenceLabel()))
+			Expect(deleted).To(BeTrue())
+		})
+
+		It("should keep a SSH public key referenced by another shoot", func() {
+			labels[controller.LabelReferencePrefix+"other-uid"] = "true"
+
+			err := EnsureSSHPublicKeyDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, testSSHFingerprint, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).NotTo(HaveKey(owner.ReferenceLabel()))
+			Expect(deleted).To(BeFalse())
+		})
+
+		It("should keep a SSH public key used by another infrastructure", func() {
+			var checkedFingerprint string
+
+			isUsed := func(_ context.Context, fingerprint string) (bool, error) {
+				checkedFingerprint = fingerprint
+				return true, nil
+			}
+
+			err := EnsureSSHPublicKeyDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, testSSHFingerprint, isUsed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedFingerprint).To(Equal(testSSHFingerprint))
+			Expect(deleted).To(BeFalse())
+		})
+
+		It("should refuse to delete a SSH public key of another garden", func() {
+			labels[controller.LabelGardenID] = "other-garden"
+
+			err := EnsureSSHPublicKeyDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, testSSHFingerprint, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updates).To(BeEmpty())
+			Expect(deleted).To(BeFalse())
+		})
+	})
+})
@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -40,6 +41,7 @@ import (
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/v1alpha1"
 )
 
@@ -49,15 +51,19 @@ type delegateFactory struct {
 	restConfig   *rest.Config
 	scheme       *runtime.Scheme
 	gardenReader client.Reader
+	recorder     record.EventRecorder
+	gardenID     string
 }
 
 // NewActuator creates a new Actuator that updates the status of the handled WorkerPoolConfigs.
-func NewActuator(mgr manager.Manager, gardenCluster cluster.Cluster) (worker.Actuator, error) {
+func NewActuator(mgr manager.Manager, gardenCluster cluster.Cluster, gardenID string) (worker.Actuator, error) {
 	delegateFactory := &delegateFactory{
 		logger:     log.Log.WithName("worker-actuator"),
 		seedClient: mgr.GetClient(),
 		restConfig: mgr.GetConfig(),
 		scheme:     mgr.GetScheme(),
+		recorder:   mgr.GetEventRecorderFor(hcloud.Name + "-worker-controller"),
+		gardenID:   gardenID,
 	}
 
 	return genericactuator.NewActuator(
@@ -97,6 +103,8 @@ func (d *delegateFactory) WorkerDelegate(ctx context.Context, worker *extensions
 
 		worker,
 		cluster,
+		d.gardenID,
+		d.recorder,
 	)
 }
 
@@ -111,6 +119,8 @@ type workerDelegate struct {
 	cloudProfileConfig *apis.CloudProfileConfig
 	cluster            *extensionscontroller.Cluster
 	worker             *extensionsv1alpha1.Worker
+	gardenID           string
+	recorder           record.EventRecorder
 
 	machineClasses     []map[string]interface{}
 	machineDeployments worker.MachineDeployments
@@ -127,6 +137,8 @@ type workerDelegate struct {
 // serverVersion    string                        Kubernetes version
 // worker           *extensionsv1alpha1.Worker    Worker struct
 // cluster          *extensionscontroller.Cluster Cluster struct
+// gardenID         string                        Garden ID
+// recorder         record.EventRecorder          Event recorder
 func NewWorkerDelegate(
 	client client.Client,
 	scheme *runtime.Scheme,
@@ -136,6 +148,8 @@ func NewWorkerDelegate(
 
 	worker *extensionsv1alpha1.Worker,
 	cluster *extensionscontroller.Cluster,
+	gardenID string,
+	recorder record.EventRecorder,
 ) (genericactuator.WorkerDelegate, error) {
 	cloudProfileConfig, err := controller.GetCloudProfileConfigFromControllerCluster(cluster)
 	if err != nil {
@@ -166,10 +180,34 @@ func NewWorkerDelegate(
 		cloudProfileConfig: cloudProfileConfig,
 		cluster:            cluster,
 		worker:             worker,
+		gardenID:           gardenID,
+		recorder:           recorder,
 		hclient:            hclient,
 	}, nil
 }
 
+// getResourceOwner returns the owner of the HCloud resources of the worker adding the labels of the
+// InfrastructureConfig.
+func (w *workerDelegate) getResourceOwner() *controller.ResourceOwner {
+	owner := controller.NewResourceOwner(string(w.cluster.Shoot.GetUID()), w.gardenID, w.recorder, w.worker)
+	return owner.WithLabels(w.getInfrastructureLabels())
+}
+
+// getInfrastructureLabels returns the user-defined labels of the InfrastructureConfig. Invalid configurations are
+// reported by the infrastructure controller and result in no labels.
+func (w *workerDelegate) getInfrastructureLabels() map[string]string {
+	if nil == w.cluster || nil == w.cluster.Shoot || nil == w.cluster.Shoot.Spec.Provider.InfrastructureConfig {
+		return nil
+	}
+
+	infraConfig, err := transcoder.DecodeInfrastructureConfigFromCluster(w.cluster)
+	if nil != err {
+		return nil
+	}
+
+	return infraConfig.Labels
+}
+
 // updateProviderStatus updates the worker provider status.
 //
 // PARAMETERS
@@ -56,17 +56,31 @@ func (w *workerDelegate) PreReconcileHook(ctx context.Context) error {
 		}
 	}
 
-	placementGroupIDs, err := ensurer.EnsurePlacementGroups(ctx, w.hclient, w.worker)
+	workerStatus, err := transcoder.DecodeWorkerStatusFromWorker(w.worker)
 	if err != nil {
-		return err
+		return fmt.Errorf("unable to decode the worker provider status: %w", err)
 	}
 
-	workerStatus, err := transcoder.DecodeWorkerStatusFromWorker(w.worker)
+	placementGroupIDs, err := ensurer.EnsurePlacementGroups(ctx, w.hclient, w.getResourceOwner(), w.worker, workerStatus.PlacementGroupIDs)
 	if err != nil {
-		return fmt.Errorf("unable to decode the worker provider status: %w", err)
+		return err
 	}
 
 	workerStatus.PlacementGroupIDs = placementGroupIDs
+	workerStatus.ReservedPrimaryIPs = nil
+
+	infraStatus, err := transcoder.DecodeInfrastructureStatusFromWorker(w.worker)
+	if err != nil {
+		return err
+	}
+
+	// Worker nodes egressing through the NAT gateway do not get public IPs assigned.
+	if "" == infraStatus.NATGatewayID {
+		workerStatus.ReservedPrimaryIPs, err = ensurer.EnsureReservedPrimaryIPs(ctx, w.hclient, w.getResourceOwner(), w.worker)
+		if err != nil {
+			return err
+		}
+	}
 
 	updateErr := w.updateProviderStatus(ctx, workerStatus)
 	if updateErr != nil {
@@ -79,9 +93,15 @@ func (w *workerDelegate) PreReconcileHook(ctx context.Context) error {
 // PostReconcileHook is a hook called at the end of the worker reconciliation flow.
 //
 // PARAMETERS
-// _ context.Context Execution context
-func (w *workerDelegate) PostReconcileHook(_ context.Context) error {
-	return nil
+// ctx context.Context Execution context
+func (w *workerDelegate) PostReconcileHook(ctx context.Context) error {
+	workerStatus, err := transcoder.DecodeWorkerStatusFromWorker(w.worker)
+	if err != nil {
+		return err
+	}
+
+	// Reserved primary IPs of removed worker pools are released once the machines using them are gone.
+	return ensurer.ReleaseUnusedReservedPrimaryIPs(ctx, w.hclient, w.getResourceOwner(), w.worker.Namespace, workerStatus.ReservedPrimaryIPs)
 }
 
 // PreDeleteHook is a hook called at the beginning of the worker deletion flow.
@@ -104,6 +124,13 @@ func (w *workerDelegate) PostDeleteHook(ctx context.Context) error {
 		return err
 	}
 
+	if deleteAllPlacementGroups {
+		err = ensurer.EnsureReservedPrimaryIPsDeleted(ctx, w.hclient, w.getResourceOwner(), w.worker.Namespace)
+		if err != nil {
+			return err
+		}
+	}
+
 	for _, worker := range w.worker.Spec.Pools {
 		// if there is no placementgroup in the workerstatus for current pool,
 		// mark it for deletion
@@ -116,7 +143,7 @@ func (w *workerDelegate) PostDeleteHook(ctx context.Context) error {
 		}
 
 		if deletePlacementGroup {
-			err := ensurer.EnsurePlacementGroupDeleted(ctx, w.hclient, workerStatus.PlacementGroupIDs[name])
+			err := ensurer.EnsurePlacementGroupDeleted(ctx, w.hclient, w.getResourceOwner(), workerStatus.PlacementGroupIDs[name])
 			if err != nil {
 				return err
 			}
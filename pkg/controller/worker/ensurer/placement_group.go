@@ -24,20 +24,24 @@ import (
 	"github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 )
 
+// placementGroupRole is the role label value of placement groups created.
+const placementGroupRole = "placement-group-v1"
+
 // EnsurePlacementGroups verifies that the placement groups requested are available.
 //
 // PARAMETERS
-// ctx          context.Context  Execution context
-// client       *hcloud.Client   HCloud client
-// workerConfig *v1alpha1.Worker Worker config
-func EnsurePlacementGroups(ctx context.Context, client *hcloud.Client, workerConfig *v1alpha1.Worker) (map[string]int64, error) {
+// ctx                       context.Context           Execution context
+// client                    *hcloud.Client            HCloud client
+// owner                     *controller.ResourceOwner Resource owner
+// workerConfig              *v1alpha1.Worker          Worker config
+// previousPlacementGroupIDs map[string]int64          Placement group IDs of the last reconciliation
+func EnsurePlacementGroups(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, workerConfig *v1alpha1.Worker, previousPlacementGroupIDs map[string]int64) (map[string]int64, error) {
 	placementGroupIDs := map[string]int64{}
 
-	labels := map[string]string{"hcloud.provider.extensions.gardener.cloud/role": "placement-group-v1"}
-
 	for _, worker := range workerConfig.Spec.Pools {
 		if worker.ProviderConfig == nil {
 			continue
@@ -53,14 +57,17 @@ func EnsurePlacementGroups(ctx context.Context, client *hcloud.Client, workerCon
 		}
 
 		name := fmt.Sprintf("%s-%s", workerConfig.Namespace, worker.Name)
+		poolOwner := owner.WithLabels(workerProviderConfig.Labels)
 
 		placementGroup, _, err := client.PlacementGroup.GetByName(ctx, name)
 		if nil != err {
 			return placementGroupIDs, err
+		} else if placementGroup != nil && !owner.IsOwnerOf(placementGroup.Labels, placementGroupRole) && !isAdoptablePlacementGroup(owner, placementGroup, previousPlacementGroupIDs[name]) {
+			return placementGroupIDs, fmt.Errorf("Placement group %q (%d) is not owned by this shoot", placementGroup.Name, placementGroup.ID)
 		} else if placementGroup == nil {
 			opts := hcloud.PlacementGroupCreateOpts{
 				Name:   name,
-				Labels: labels,
+				Labels: poolOwner.Labels(placementGroupRole),
 				Type:   hcloud.PlacementGroupTypeSpread,
 			}
 
@@ -70,6 +77,11 @@ func EnsurePlacementGroups(ctx context.Context, client *hcloud.Client, workerCon
 			}
 
 			placementGroup = placementGroupResult.PlacementGroup
+		} else if labels, changed := poolOwner.MergeLabels(placementGroup.Labels, placementGroupRole); changed {
+			_, _, err = client.PlacementGroup.Update(ctx, placementGroup, hcloud.PlacementGroupUpdateOpts{Labels: labels})
+			if nil != err {
+				return placementGroupIDs, err
+			}
 		}
 
 		placementGroupIDs[name] = placementGroup.ID
@@ -78,18 +90,24 @@ func EnsurePlacementGroups(ctx context.Context, client *hcloud.Client, workerCon
 	return placementGroupIDs, nil
 }
 
-// EnsurePlacementGroupDeleted removes any previously created placement group identified by the given fingerprint.
+// EnsurePlacementGroupDeleted removes any previously created placement group identified by the given ID.
 //
 // PARAMETERS
-// ctx         context.Context  Execution context
-// client      *hcloud.Client   HCloud client
-// fingerprint string           SSH fingerprint
-func EnsurePlacementGroupDeleted(ctx context.Context, client *hcloud.Client, placementGroupID int64) error {
+// ctx              context.Context           Execution context
+// client           *hcloud.Client            HCloud client
+// owner            *controller.ResourceOwner Resource owner
+// placementGroupID int64                     Placement group ID
+func EnsurePlacementGroupDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, placementGroupID int64) error {
 	if placementGroupID != 0 {
 		placementGroup, _, err := client.PlacementGroup.GetByID(ctx, placementGroupID)
 		if nil != err {
 			return err
 		} else if placementGroup != nil {
+			if !owner.IsOwnerOf(placementGroup.Labels, placementGroupRole) && !isAdoptablePlacementGroup(owner, placementGroup, placementGroupID) {
+				owner.RefuseDeletion("placement group", placementGroup.Name, placementGroup.ID)
+				return nil
+			}
+
 			_, err := client.PlacementGroup.Delete(ctx, placementGroup)
 			if nil != err {
 				return err
@@ -99,3 +117,14 @@ func EnsurePlacementGroupDeleted(ctx context.Context, client *hcloud.Client, pla
 
 	return nil
 }
+
+// isAdoptablePlacementGroup returns true if the given placement group has been created by an earlier version and is
+// referenced by the worker status with the ID given.
+//
+// PARAMETERS
+// owner            *controller.ResourceOwner Resource owner
+// placementGroup   *hcloud.PlacementGroup    HCloud placement group
+// placementGroupID int64                     Placement group ID of the worker status
+func isAdoptablePlacementGroup(owner *controller.ResourceOwner, placementGroup *hcloud.PlacementGroup, placementGroupID int64) bool {
+	return placementGroup.ID == placementGroupID && owner.IsAdoptable(placementGroup.Labels, placementGroupRole)
+}
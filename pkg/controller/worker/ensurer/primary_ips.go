@@ -0,0 +1,322 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure worker changes to be applied
+package ensurer
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gardener/gardener/extensions/pkg/controller/worker"
+	"github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	hcloudextension "github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
+)
+
+const (
+	// reservedPrimaryIPRole is the role label value of reserved primary IPs created.
+	reservedPrimaryIPRole = "reserved-primary-ip-v1"
+	// labelWorkerPool is the label containing the worker pool name of a reserved primary IP.
+	labelWorkerPool = "hcloud.provider.extensions.gardener.cloud/worker-pool"
+)
+
+// EnsureReservedPrimaryIPs verifies that the primary IPs reserved for the worker pools requested are available.
+// Reserved primary IPs are not deleted together with the servers they are assigned to.
+//
+// PARAMETERS
+// ctx          context.Context           Execution context
+// client       *hcloud.Client            HCloud client
+// owner        *controller.ResourceOwner Resource owner
+// workerConfig *v1alpha1.Worker          Worker config
+func EnsureReservedPrimaryIPs(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, workerConfig *v1alpha1.Worker) (map[string]apis.WorkerStatusReservedPrimaryIPs, error) {
+	reservedPrimaryIPs := map[string]apis.WorkerStatusReservedPrimaryIPs{}
+
+	existingIPs, err := getReservedPrimaryIPs(ctx, client, owner, workerConfig.Namespace)
+	if nil != err {
+		return reservedPrimaryIPs, err
+	}
+
+	for _, worker := range workerConfig.Spec.Pools {
+		if worker.ProviderConfig == nil {
+			continue
+		}
+
+		workerProviderConfig, err := transcoder.DecodeWorkerConfigFromRawExtension(worker.ProviderConfig)
+		if err != nil {
+			return reservedPrimaryIPs, err
+		}
+
+		if nil == workerProviderConfig.ReservedPrimaryIPs {
+			continue
+		}
+
+		// Reserved primary IPs are not created unless the machine classes are able to assign them.
+		if !hcloudextension.ExtendedMachineClassFields {
+			return reservedPrimaryIPs, fmt.Errorf("Reserved primary IPs of worker pool %s require extendedMachineClassFields to be enabled", worker.Name)
+		}
+
+		poolOwner := owner.WithLabels(workerProviderConfig.Labels)
+
+		for zoneIndex, zone := range worker.Zones {
+			count := getReservedPrimaryIPCount(worker, workerProviderConfig.ReservedPrimaryIPs, int32(zoneIndex))
+			name := fmt.Sprintf("%s-%s-%s", workerConfig.Namespace, worker.Name, zone)
+			primaryIPs := apis.WorkerStatusReservedPrimaryIPs{}
+
+			if workerProviderConfig.ReservedPrimaryIPs.IPv4 {
+				primaryIPs.IPv4, err = ensureReservedPrimaryIPsOfType(ctx, client, poolOwner, existingIPs, name, worker.Name, zone, hcloud.PrimaryIPTypeIPv4, count)
+				if nil != err {
+					return reservedPrimaryIPs, err
+				}
+			}
+
+			if workerProviderConfig.ReservedPrimaryIPs.IPv6 {
+				primaryIPs.IPv6, err = ensureReservedPrimaryIPsOfType(ctx, client, poolOwner, existingIPs, name, worker.Name, zone, hcloud.PrimaryIPTypeIPv6, count)
+				if nil != err {
+					return reservedPrimaryIPs, err
+				}
+			}
+
+			reservedPrimaryIPs[name] = primaryIPs
+		}
+	}
+
+	return reservedPrimaryIPs, nil
+}
+
+// ReleaseUnusedReservedPrimaryIPs removes reserved primary IPs not part of the given ones. Primary IPs still
+// assigned to a server are kept until a later reconciliation.
+//
+// PARAMETERS
+// ctx                context.Context                                Execution context
+// client             *hcloud.Client                                 HCloud client
+// owner              *controller.ResourceOwner                      Resource owner
+// namespace          string                                         Shoot namespace
+// reservedPrimaryIPs map[string]apis.WorkerStatusReservedPrimaryIPs Reserved primary IPs to keep
+func ReleaseUnusedReservedPrimaryIPs(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string, reservedPrimaryIPs map[string]apis.WorkerStatusReservedPrimaryIPs) error {
+	return deleteReservedPrimaryIPs(ctx, client, owner, namespace, reservedPrimaryIPs, false)
+}
+
+// EnsureReservedPrimaryIPsDeleted removes all reserved primary IPs of the shoot.
+//
+// PARAMETERS
+// ctx       context.Context           Execution context
+// client    *hcloud.Client            HCloud client
+// owner     *controller.ResourceOwner Resource owner
+// namespace string                    Shoot namespace
+func EnsureReservedPrimaryIPsDeleted(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string) error {
+	return deleteReservedPrimaryIPs(ctx, client, owner, namespace, nil, true)
+}
+
+// deleteReservedPrimaryIPs removes reserved primary IPs not part of the given ones.
+//
+// PARAMETERS
+// ctx                context.Context                                Execution context
+// client             *hcloud.Client                                 HCloud client
+// owner              *controller.ResourceOwner                      Resource owner
+// namespace          string                                         Shoot namespace
+// reservedPrimaryIPs map[string]apis.WorkerStatusReservedPrimaryIPs Reserved primary IPs to keep
+// failIfAssigned     bool                                           True to fail for primary IPs still assigned
+func deleteReservedPrimaryIPs(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string, reservedPrimaryIPs map[string]apis.WorkerStatusReservedPrimaryIPs, failIfAssigned bool) error {
+	existingIPs, err := getReservedPrimaryIPs(ctx, client, owner, namespace)
+	if nil != err {
+		return err
+	}
+
+	keep := map[int64]bool{}
+
+	for _, primaryIPs := range reservedPrimaryIPs {
+		for _, id := range slices.Concat(primaryIPs.IPv4, primaryIPs.IPv6) {
+			keep[id] = true
+		}
+	}
+
+	var errs []error
+
+	for _, primaryIP := range existingIPs {
+		if keep[primaryIP.ID] {
+			continue
+		}
+
+		if 0 != primaryIP.AssigneeID {
+			if failIfAssigned {
+				errs = append(errs, fmt.Errorf("Failed to delete primary IP %q (%d): still assigned to %d", primaryIP.Name, primaryIP.ID, primaryIP.AssigneeID))
+			}
+
+			continue
+		}
+
+		_, err := client.PrimaryIP.Delete(ctx, primaryIP)
+		if nil != err && !hcloud.IsError(err, hcloud.ErrorCodeNotFound) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ensureReservedPrimaryIPsOfType verifies that the given number of primary IPs of the given type is reserved for
+// the machine deployment.
+//
+// PARAMETERS
+// ctx         context.Context           Execution context
+// client      *hcloud.Client            HCloud client
+// owner       *controller.ResourceOwner Resource owner
+// existingIPs []*hcloud.PrimaryIP       Reserved primary IPs of the shoot
+// name        string                    Machine deployment name
+// poolName    string                    Worker pool name
+// zone        string                    Worker pool zone
+// ipType      hcloud.PrimaryIPType      Primary IP type
+// count       int                       Number of primary IPs to reserve
+func ensureReservedPrimaryIPsOfType(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, existingIPs []*hcloud.PrimaryIP, name, poolName, zone string, ipType hcloud.PrimaryIPType, count int) ([]int64, error) {
+	namePrefix := fmt.Sprintf("%s-%s-", name, ipType)
+
+	var (
+		ids      []int64
+		usedName = map[string]bool{}
+	)
+
+	for _, primaryIP := range existingIPs {
+		if len(ids) >= count {
+			break
+		}
+
+		if primaryIP.Type != ipType || primaryIP.Labels[labelWorkerPool] != poolName || !strings.HasPrefix(primaryIP.Name, namePrefix) {
+			continue
+		}
+
+		opts := hcloud.PrimaryIPUpdateOpts{}
+
+		// Servers created by the machine controller may request primary IPs to be deleted together with them.
+		if primaryIP.AutoDelete {
+			opts.AutoDelete = hcloud.Ptr(false)
+		}
+
+		if labels, changed := owner.MergeLabels(primaryIP.Labels, reservedPrimaryIPRole); changed {
+			opts.Labels = &labels
+		}
+
+		if nil != opts.AutoDelete || nil != opts.Labels {
+			_, _, err := client.PrimaryIP.Update(ctx, primaryIP, opts)
+			if nil != err {
+				return nil, err
+			}
+		}
+
+		ids = append(ids, primaryIP.ID)
+		usedName[primaryIP.Name] = true
+	}
+
+	for index := 0; len(ids) < count; index++ {
+		primaryIPName := fmt.Sprintf("%s%d", namePrefix, index)
+		if usedName[primaryIPName] {
+			continue
+		}
+
+		labels := owner.Labels(reservedPrimaryIPRole)
+		labels[labelWorkerPool] = poolName
+
+		opts := hcloud.PrimaryIPCreateOpts{
+			Name:         primaryIPName,
+			Type:         ipType,
+			AssigneeType: "server",
+			AutoDelete:   hcloud.Ptr(false),
+			Location:     apis.GetLocationFromZone(zone),
+			Labels:       labels,
+		}
+
+		result, _, err := client.PrimaryIP.Create(ctx, opts)
+		if nil != err {
+			return nil, err
+		}
+
+		ids = append(ids, result.PrimaryIP.ID)
+		usedName[primaryIPName] = true
+	}
+
+	slices.Sort(ids)
+
+	return ids, nil
+}
+
+// getReservedPrimaryIPCount returns the number of primary IPs to reserve for the given zone and IP family. The pool
+// maximum and maximum surge are distributed over the zones the same way as for the machine deployments.
+//
+// PARAMETERS
+// pool               v1alpha1.WorkerPool                  Worker pool
+// reservedPrimaryIPs *apis.WorkerConfigReservedPrimaryIPs Reserved primary IPs configuration
+// zoneIndex          int32                                Index of the zone in the worker pool
+func getReservedPrimaryIPCount(pool v1alpha1.WorkerPool, reservedPrimaryIPs *apis.WorkerConfigReservedPrimaryIPs, zoneIndex int32) int {
+	if nil != reservedPrimaryIPs.Count {
+		return int(*reservedPrimaryIPs.Count)
+	}
+
+	zoneSize := int32(len(pool.Zones))
+	maximum := worker.DistributeOverZones(zoneIndex, pool.Maximum, zoneSize)
+	zoneMaxSurge := worker.DistributePositiveIntOrPercent(zoneIndex, pool.MaxSurge, zoneSize, pool.Maximum)
+
+	maxSurge, err := intstr.GetScaledValueFromIntOrPercent(&zoneMaxSurge, int(maximum), true)
+	if nil != err {
+		maxSurge = 0
+	}
+
+	return int(maximum) + maxSurge
+}
+
+// getReservedPrimaryIPs returns all reserved primary IPs owned by the shoot.
+//
+// PARAMETERS
+// ctx       context.Context           Execution context
+// client    *hcloud.Client            HCloud client
+// owner     *controller.ResourceOwner Resource owner
+// namespace string                    Shoot namespace
+func getReservedPrimaryIPs(ctx context.Context, client *hcloud.Client, owner *controller.ResourceOwner, namespace string) ([]*hcloud.PrimaryIP, error) {
+	labelSelector := fmt.Sprintf("%s=%s", controller.LabelRole, reservedPrimaryIPRole)
+
+	if "" != owner.ClusterID {
+		labelSelector = fmt.Sprintf("%s,%s=%s", labelSelector, controller.LabelClusterID, owner.ClusterID)
+	}
+
+	primaryIPs, err := client.PrimaryIP.AllWithOpts(ctx, hcloud.PrimaryIPListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: labelSelector},
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	var result []*hcloud.PrimaryIP
+
+	for _, primaryIP := range primaryIPs {
+		if strings.HasPrefix(primaryIP.Name, namespace+"-") && owner.IsOwnerOf(primaryIP.Labels, reservedPrimaryIPRole) {
+			result = append(result, primaryIP)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b *hcloud.PrimaryIP) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,293 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ensurer provides functions used to ensure worker changes to be applied
+package ensurer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	hcloudextension "github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/controller"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
+)
+
+// testPrimaryIP is a primary IP returned by the mocked HCloud API.
+type testPrimaryIP struct {
+	ID         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	IP         string            `json:"ip"`
+	AssigneeID int64             `json:"assignee_id,omitempty"`
+	AutoDelete bool              `json:"auto_delete"`
+	Labels     map[string]string `json:"labels"`
+	Created    string            `json:"created"`
+}
+
+var _ = Describe("Reserved primary IPs", func() {
+	var (
+		mockTestEnv  mock.MockTestEnv
+		owner        *controller.ResourceOwner
+		primaryIPs   []*testPrimaryIP
+		createdNames []string
+		updates      map[int64]map[string]interface{}
+		deletedIDs   []int64
+	)
+
+	newPrimaryIP := func(id int64, name string, assigneeID int64) *testPrimaryIP {
+		labels := owner.Labels(reservedPrimaryIPRole)
+		labels[labelWorkerPool] = "pool"
+
+		return &testPrimaryIP{
+			ID:         id,
+			Name:       name,
+			Type:       "ipv4",
+			IP:         fmt.Sprintf("192.0.2.%d", id),
+			AssigneeID: assigneeID,
+			Labels:     labels,
+			Created:    "2016-01-30T23:50:00+00:00",
+		}
+	}
+
+	writeResponse := func(res http.ResponseWriter, status int, data interface{}) {
+		body, err := json.Marshal(data)
+		Expect(err).NotTo(HaveOccurred())
+
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+		res.WriteHeader(status)
+
+		_, _ = res.Write(body)
+	}
+
+	decodeRequestBody := func(req *http.Request) map[string]interface{} {
+		body, err := io.ReadAll(req.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		var data map[string]interface{}
+		Expect(json.Unmarshal(body, &data)).To(Succeed())
+
+		return data
+	}
+
+	newWorker := func(providerConfig string) *v1alpha1.Worker {
+		worker := &v1alpha1.Worker{}
+		worker.Namespace = "shoot--foobar--hcloud"
+		worker.Spec.Pools = []v1alpha1.WorkerPool{{
+			Name:           "pool",
+			Maximum:        2,
+			Zones:          []string{"hel1-dc2"},
+			ProviderConfig: &runtime.RawExtension{Raw: []byte(providerConfig)},
+		}}
+
+		return worker
+	}
+
+	BeforeEach(func() {
+		mockTestEnv = mock.NewMockTestEnv()
+		owner = controller.NewResourceOwner("shoot-uid", "", nil, nil)
+		primaryIPs = nil
+		createdNames = nil
+		updates = map[int64]map[string]interface{}{}
+		deletedIDs = nil
+
+		mockTestEnv.Mux.HandleFunc("/primary_ips", func(res http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPost {
+				data := decodeRequestBody(req)
+				Expect(data["auto_delete"]).To(BeFalse())
+
+				labels := map[string]string{}
+				for key, value := range data["labels"].(map[string]interface{}) {
+					labels[key] = value.(string)
+				}
+
+				primaryIP := newPrimaryIP(int64(100+len(createdNames)), data["name"].(string), 0)
+				primaryIP.Type = data["type"].(string)
+				primaryIP.Labels = labels
+
+				createdNames = append(createdNames, primaryIP.Name)
+				primaryIPs = append(primaryIPs, primaryIP)
+
+				writeResponse(res, http.StatusCreated, map[string]interface{}{"primary_ip": primaryIP})
+
+				return
+			}
+
+			Expect(req.URL.Query().Get("label_selector")).To(ContainSubstring(controller.LabelClusterID + "=shoot-uid"))
+
+			writeResponse(res, http.StatusOK, map[string]interface{}{"primary_ips": primaryIPs})
+		})
+
+		mockTestEnv.Mux.HandleFunc("/primary_ips/", func(res http.ResponseWriter, req *http.Request) {
+			var id int64
+			_, err := fmt.Sscanf(strings.TrimPrefix(req.URL.Path, "/primary_ips/"), "%d", &id)
+			Expect(err).NotTo(HaveOccurred())
+
+			switch req.Method {
+			case http.MethodDelete:
+				deletedIDs = append(deletedIDs, id)
+				res.WriteHeader(http.StatusNoContent)
+			case http.MethodPut:
+				updates[id] = decodeRequestBody(req)
+
+				for _, primaryIP := range primaryIPs {
+					if primaryIP.ID == id {
+						writeResponse(res, http.StatusOK, map[string]interface{}{"primary_ip": primaryIP})
+					}
+				}
+			default:
+				Fail("Unexpected request " + req.Method + " " + req.URL.Path)
+			}
+		})
+	})
+
+	AfterEach(func() {
+		mockTestEnv.Teardown()
+	})
+
+	Describe("#EnsureReservedPrimaryIPs", func() {
+		BeforeEach(func() {
+			hcloudextension.ExtendedMachineClassFields = true
+		})
+
+		AfterEach(func() {
+			hcloudextension.ExtendedMachineClassFields = false
+		})
+
+		It("should reserve the configured number of primary IPs per zone", func() {
+			worker := newWorker(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","reservedPrimaryIPs":{"ipv4":true,"count":2}}`)
+
+			reservedPrimaryIPs, err := EnsureReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, worker)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdNames).To(Equal([]string{"shoot--foobar--hcloud-pool-hel1-dc2-ipv4-0", "shoot--foobar--hcloud-pool-hel1-dc2-ipv4-1"}))
+			Expect(reservedPrimaryIPs).To(Equal(map[string]apis.WorkerStatusReservedPrimaryIPs{
+				"shoot--foobar--hcloud-pool-hel1-dc2": {IPv4: []int64{100, 101}},
+			}))
+
+			for _, primaryIP := range primaryIPs {
+				Expect(primaryIP.Labels).To(HaveKeyWithValue(labelWorkerPool, "pool"))
+				Expect(primaryIP.Labels).To(HaveKeyWithValue(controller.LabelRole, reservedPrimaryIPRole))
+			}
+		})
+
+		It("should default the number of primary IPs to the pool maximum plus the maximum surge", func() {
+			worker := newWorker(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","reservedPrimaryIPs":{"ipv6":true}}`)
+			worker.Spec.Pools[0].MaxSurge.IntVal = 1
+
+			reservedPrimaryIPs, err := EnsureReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, worker)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdNames).To(HaveLen(3))
+			Expect(createdNames[0]).To(Equal("shoot--foobar--hcloud-pool-hel1-dc2-ipv6-0"))
+			Expect(reservedPrimaryIPs["shoot--foobar--hcloud-pool-hel1-dc2"].IPv6).To(HaveLen(3))
+		})
+
+		It("should distribute the pool maximum and the maximum surge over the zones", func() {
+			worker := newWorker(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","reservedPrimaryIPs":{"ipv4":true}}`)
+			worker.Spec.Pools[0].Maximum = 3
+			worker.Spec.Pools[0].MaxSurge.IntVal = 1
+			worker.Spec.Pools[0].Zones = []string{"hel1-dc2", "fsn1-dc14"}
+
+			reservedPrimaryIPs, err := EnsureReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, worker)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdNames).To(HaveLen(4))
+			Expect(reservedPrimaryIPs["shoot--foobar--hcloud-pool-hel1-dc2"].IPv4).To(HaveLen(3))
+			Expect(reservedPrimaryIPs["shoot--foobar--hcloud-pool-fsn1-dc14"].IPv4).To(HaveLen(1))
+		})
+
+		It("should keep existing primary IPs and disable their automatic deletion", func() {
+			existingIP := newPrimaryIP(42, "shoot--foobar--hcloud-pool-hel1-dc2-ipv4-1", 7)
+			existingIP.AutoDelete = true
+			primaryIPs = []*testPrimaryIP{existingIP}
+
+			worker := newWorker(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","reservedPrimaryIPs":{"ipv4":true,"count":2}}`)
+
+			reservedPrimaryIPs, err := EnsureReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, worker)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(createdNames).To(Equal([]string{"shoot--foobar--hcloud-pool-hel1-dc2-ipv4-0"}))
+			Expect(updates).To(HaveKey(int64(42)))
+			Expect(updates[42]["auto_delete"]).To(BeFalse())
+			Expect(reservedPrimaryIPs["shoot--foobar--hcloud-pool-hel1-dc2"].IPv4).To(Equal([]int64{42, 100}))
+		})
+
+		It("should ignore worker pools without reserved primary IPs", func() {
+			worker := newWorker(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig"}`)
+
+			reservedPrimaryIPs, err := EnsureReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, worker)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reservedPrimaryIPs).To(BeEmpty())
+			Expect(createdNames).To(BeEmpty())
+		})
+
+		It("should fail without creating primary IPs unless extended machine class fields are enabled", func() {
+			hcloudextension.ExtendedMachineClassFields = false
+			worker := newWorker(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","reservedPrimaryIPs":{"ipv4":true,"count":2}}`)
+
+			_, err := EnsureReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, worker)
+			Expect(err).To(HaveOccurred())
+			Expect(createdNames).To(BeEmpty())
+		})
+	})
+
+	Describe("#ReleaseUnusedReservedPrimaryIPs", func() {
+		It("should only delete unassigned primary IPs no longer reserved", func() {
+			primaryIPs = []*testPrimaryIP{
+				newPrimaryIP(41, "shoot--foobar--hcloud-pool-hel1-dc2-ipv4-0", 0),
+				newPrimaryIP(42, "shoot--foobar--hcloud-removed-hel1-dc2-ipv4-0", 0),
+				newPrimaryIP(43, "shoot--foobar--hcloud-removed-hel1-dc2-ipv4-1", 7),
+				newPrimaryIP(44, "shoot--other--hcloud-removed-hel1-dc2-ipv4-0", 0),
+			}
+
+			err := ReleaseUnusedReservedPrimaryIPs(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud", map[string]apis.WorkerStatusReservedPrimaryIPs{
+				"shoot--foobar--hcloud-pool-hel1-dc2": {IPv4: []int64{41}},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedIDs).To(Equal([]int64{42}))
+		})
+	})
+
+	Describe("#EnsureReservedPrimaryIPsDeleted", func() {
+		It("should fail for primary IPs still assigned to a server", func() {
+			primaryIPs = []*testPrimaryIP{
+				newPrimaryIP(41, "shoot--foobar--hcloud-pool-hel1-dc2-ipv4-0", 0),
+				newPrimaryIP(42, "shoot--foobar--hcloud-pool-hel1-dc2-ipv4-1", 7),
+			}
+
+			err := EnsureReservedPrimaryIPsDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("still assigned to 7"))
+			Expect(deletedIDs).To(Equal([]int64{41}))
+		})
+
+		It("should keep primary IPs not owned by the shoot", func() {
+			foreignIP := newPrimaryIP(41, "shoot--foobar--hcloud-pool-hel1-dc2-ipv4-0", 0)
+			foreignIP.Labels[controller.LabelClusterID] = "other-uid"
+			primaryIPs = []*testPrimaryIP{foreignIP}
+
+			Expect(EnsureReservedPrimaryIPsDeleted(context.TODO(), mockTestEnv.HcloudClient, owner, "shoot--foobar--hcloud")).To(Succeed())
+			Expect(deletedIDs).To(BeEmpty())
+		})
+	})
+})
@@ -20,6 +20,7 @@ package worker
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
 
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
@@ -133,12 +134,26 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 		return err
 	}
 
-	sshFingerprint := infraStatus.SSHFingerprint
+	var (
+		sshFingerprint  string
+		sshFingerprints []string
+	)
 
-	if "" == sshFingerprint {
-		sshFingerprint, err = apis.GetSSHFingerprint(w.worker.Spec.SSHPublicKey)
-		if err != nil {
-			return err
+	if nil == w.cluster || apis.IsSSHAccessEnabled(w.cluster.Shoot) {
+		sshFingerprint = infraStatus.SSHFingerprint
+
+		if "" == sshFingerprint {
+			sshFingerprint, err = apis.GetSSHFingerprint(w.worker.Spec.SSHPublicKey)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Machines are created with the key replaced by a SSH keypair rotation as well until the rotation has been completed.
+		sshFingerprints = []string{sshFingerprint}
+
+		if "" != infraStatus.PreviousSSHFingerprint && infraStatus.PreviousSSHFingerprint != sshFingerprint {
+			sshFingerprints = append(sshFingerprints, infraStatus.PreviousSSHFingerprint)
 		}
 	}
 
@@ -146,6 +161,22 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 		return fmt.Errorf("missing pool")
 	}
 
+	networkName := fmt.Sprintf("%s-workers", w.worker.Namespace)
+
+	tags := map[string]string{
+		"mcm.gardener.cloud/cluster": w.worker.Namespace,
+		"mcm.gardener.cloud/role":    "node",
+	}
+
+	// Servers are labeled with the cluster ID to be found by a forced deletion of the infrastructure.
+	if nil != w.cluster && nil != w.cluster.Shoot && "" != w.cluster.Shoot.GetUID() {
+		tags["cluster.gardener.cloud/id"] = string(w.cluster.Shoot.GetUID())
+	}
+
+	if nil != infraStatus.NetworkIDs && "" != infraStatus.NetworkIDs.WorkersName {
+		networkName = infraStatus.NetworkIDs.WorkersName
+	}
+
 	for _, pool := range w.worker.Spec.Pools {
 		workerPoolHash, err := worker.WorkerPoolHash(pool, w.cluster, nil, nil)
 		if err != nil {
@@ -167,7 +198,22 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 			return err
 		}
 
-		for _, zone := range pool.Zones {
+		workerConfig, err := transcoder.DecodeWorkerConfigFromRawExtension(pool.ProviderConfig)
+		if err != nil {
+			return err
+		}
+
+		// User-defined labels must not override the tags identifying the servers of the cluster.
+		poolTags := map[string]string{}
+
+		maps.Copy(poolTags, w.getInfrastructureLabels())
+		maps.Copy(poolTags, workerConfig.Labels)
+		maps.Copy(poolTags, tags)
+
+		zoneLen := int32(len(pool.Zones))
+
+		for zoneIndex, zone := range pool.Zones {
+			zoneIdx := int32(zoneIndex)
 
 			secretMap := map[string]interface{}{
 				"userData": string(userData),
@@ -178,16 +224,12 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 			}
 
 			machineClassSpec := map[string]interface{}{
-				"cluster":        w.worker.Namespace,
-				"zone":           zone,
-				"imageName":      string(imageName),
-				"sshFingerprint": sshFingerprint,
-				"machineType":    string(pool.MachineType),
-				"networkName":    fmt.Sprintf("%s-workers", w.worker.Namespace),
-				"tags": map[string]string{
-					"mcm.gardener.cloud/cluster": w.worker.Namespace,
-					"mcm.gardener.cloud/role":    "node",
-				},
+				"cluster":     w.worker.Namespace,
+				"zone":        zone,
+				"imageName":   string(imageName),
+				"machineType": string(pool.MachineType),
+				"networkName": networkName,
+				"tags":        poolTags,
 				"credentialsSecretRef": map[string]interface{}{
 					"name":      w.worker.Spec.SecretRef.Name,
 					"namespace": w.worker.Spec.SecretRef.Namespace,
@@ -195,6 +237,15 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 				"secret": secretMap,
 			}
 
+			if "" != sshFingerprint {
+				machineClassSpec["sshFingerprint"] = sshFingerprint
+
+				// Previous SSH keys are only deployed with images supporting multiple fingerprints.
+				if hcloud.ExtendedMachineClassFields {
+					machineClassSpec["sshFingerprints"] = sshFingerprints
+				}
+			}
+
 			placementGroupName := fmt.Sprintf("%s-%s", w.worker.Namespace, pool.Name)
 			if placementGroupID, ok := workerStatus.PlacementGroupIDs[placementGroupName]; ok {
 				machineClassSpec["placementGroupID"] = placementGroupID
@@ -204,23 +255,65 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 				machineClassSpec["floatingPoolName"] = infraStatus.FloatingPoolName
 			}
 
+			// Machines are attached to the workers subnet of the network zone of their location. Otherwise HCloud
+			// assigns an IP of a subnet in the network zone of the server.
+			if nil != infraStatus.NetworkIDs && hcloud.ExtendedMachineClassFields {
+				networkZone := apis.GetNetworkZone(w.worker.Spec.Region, zone)
+
+				if networkSubnet, ok := infraStatus.NetworkIDs.WorkersSubnets[string(networkZone)]; ok {
+					machineClassSpec["networkSubnet"] = networkSubnet
+				}
+			}
+
+			deploymentName := fmt.Sprintf("%s-%s-%s", w.worker.Namespace, pool.Name, zone)
+
+			// Worker nodes egress through the NAT gateway and must not get public IPs assigned.
+			if "" != infraStatus.NATGatewayID && hcloud.ExtendedMachineClassFields {
+				machineClassSpec["publicNet"] = map[string]interface{}{
+					"enableIPv4": false,
+					"enableIPv6": false,
+				}
+			} else if reservedPrimaryIPs, ok := workerStatus.ReservedPrimaryIPs[deploymentName]; ok && hcloud.ExtendedMachineClassFields {
+				// The machine-controller-manager-provider-hcloud image deployed must support the primary IP ID fields
+				// to assign reserved primary IPs to new servers.
+				publicNet := map[string]interface{}{
+					"enableIPv4": true,
+					"enableIPv6": true,
+				}
+
+				if len(reservedPrimaryIPs.IPv4) > 0 {
+					publicNet["primaryIPv4IDs"] = reservedPrimaryIPs.IPv4
+				}
+
+				if len(reservedPrimaryIPs.IPv6) > 0 {
+					publicNet["primaryIPv6IDs"] = reservedPrimaryIPs.IPv6
+				}
+
+				machineClassSpec["publicNet"] = publicNet
+			} else if nil != w.cluster && apis.IsIPFamilyEnabled(w.cluster.Shoot, corev1beta1.IPFamilyIPv6) {
+				// Worker nodes of IPv6 and dual-stack shoots require a public IPv6 address for pod and node traffic.
+				machineClassSpec["publicNet"] = map[string]interface{}{
+					"enableIPv4": true,
+					"enableIPv6": true,
+				}
+			}
+
 			if values.MachineTypeOptions != nil {
 				if len(values.MachineTypeOptions.ExtraConfig) > 0 {
 					machineClassSpec["extraConfig"] = values.MachineTypeOptions.ExtraConfig
 				}
 			}
 
-			deploymentName := fmt.Sprintf("%s-%s-%s", w.worker.Namespace, pool.Name, zone)
 			className := fmt.Sprintf("%s-%s", deploymentName, workerPoolHash)
 
 			machineDeployments = append(machineDeployments, worker.MachineDeployment{
 				Name:                 deploymentName,
 				ClassName:            className,
 				SecretName:           className,
-				Minimum:              pool.Minimum,
-				Maximum:              pool.Maximum,
-				MaxSurge:             pool.MaxSurge,
-				MaxUnavailable:       pool.MaxUnavailable,
+				Minimum:              worker.DistributeOverZones(zoneIdx, pool.Minimum, zoneLen),
+				Maximum:              worker.DistributeOverZones(zoneIdx, pool.Maximum, zoneLen),
+				MaxSurge:             worker.DistributePositiveIntOrPercent(zoneIdx, pool.MaxSurge, zoneLen, pool.Maximum),
+				MaxUnavailable:       worker.DistributePositiveIntOrPercent(zoneIdx, pool.MaxUnavailable, zoneLen, pool.Minimum),
 				Labels:               pool.Labels,
 				Annotations:          pool.Annotations,
 				Taints:               pool.Taints,
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/worker/genericactuator"
@@ -37,6 +38,7 @@ import (
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/23technologies/gardener-extension-provider-hcloud/charts"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
 	hcloudv1alpha1 "github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/v1alpha1"
@@ -62,7 +64,7 @@ func newWorkerDelegate(
 		decodedCluster = newDecodedCluster
 	}
 
-	workerDelegate, err := NewWorkerDelegate(client, scheme, seedChartApplier, serverVersion, worker, decodedCluster)
+	workerDelegate, err := NewWorkerDelegate(client, scheme, seedChartApplier, serverVersion, worker, decodedCluster, "garden", nil)
 	if nil != err {
 		return nil, err
 	}
@@ -70,6 +72,87 @@ func newWorkerDelegate(
 	return workerDelegate, nil
 }
 
+// newClusterWithoutSSHAccess creates a new cluster of a shoot disabling SSH access to the worker nodes.
+func newClusterWithoutSSHAccess() *v1alpha1.Cluster {
+	cluster := mock.NewCluster()
+	cluster.Spec.Shoot.Raw = []byte(strings.Replace(mock.TestClusterShoot, `"provider": {`, `"provider": {"workersSettings": {"sshAccess": {"enabled": false}},`, 1))
+
+	return cluster
+}
+
+// newWorkerWithReservedPrimaryIPs creates a new worker with primary IPs reserved for the machine deployment.
+func newWorkerWithReservedPrimaryIPs() *v1alpha1.Worker {
+	worker := mock.NewWorker()
+	worker.Status.ProviderStatus = &runtime.RawExtension{
+		Raw: []byte(fmt.Sprintf(`{
+			"apiVersion": "hcloud.provider.extensions.gardener.cloud/v1alpha1",
+			"kind": "WorkerStatus",
+			"reservedPrimaryIPs": {"%s-%s-%s": {"ipv4": [42, 43]}}
+		}`, mock.TestNamespace, mock.TestWorkerPoolName, mock.TestZone)),
+	}
+
+	return worker
+}
+
+// newClusterWithLabels creates a new cluster of a shoot with user-defined labels in the InfrastructureConfig.
+func newClusterWithLabels() *v1alpha1.Cluster {
+	cluster := mock.NewCluster()
+	cluster.Spec.Shoot.Raw = []byte(strings.Replace(mock.TestClusterShoot, `"provider": {`, `"provider": {"infrastructureConfig": {"apiVersion": "hcloud.provider.extensions.gardener.cloud/v1alpha1", "kind": "InfrastructureConfig", "labels": {"environment": "test", "team": "a"}},`, 1))
+
+	return cluster
+}
+
+// newWorkerWithLabels creates a new worker with user-defined labels in the worker config of the pool.
+func newWorkerWithLabels() *v1alpha1.Worker {
+	worker := mock.NewWorker()
+	worker.Spec.Pools[0].ProviderConfig = &runtime.RawExtension{
+		Raw: []byte(`{"apiVersion": "hcloud.provider.extensions.gardener.cloud/v1alpha1", "kind": "WorkerConfig", "labels": {"team": "b", "mcm.gardener.cloud/role": "other"}}`),
+	}
+
+	return worker
+}
+
+// newWorkerWithWorkersSubnets creates a new worker of a shoot with workers subnets in multiple network zones.
+func newWorkerWithWorkersSubnets() *v1alpha1.Worker {
+	worker := mock.NewWorker()
+	worker.Spec.InfrastructureProviderStatus = &runtime.RawExtension{
+		Raw: []byte(fmt.Sprintf(`{
+			"apiVersion": "hcloud.provider.extensions.gardener.cloud/v1alpha1",
+			"kind": "InfrastructureStatus",
+			"sshFingerprint": %q,
+			"floatingPoolName": "MY-FLOATING-POOL",
+			"networkIDs": {"workers": "42", "workersSubnets": {"eu-central": "10.250.0.0/21", "us-east": "10.250.8.0/21"}}
+		}`, mock.TestSSHFingerprint)),
+	}
+
+	return worker
+}
+
+// newWorkerWithNATGateway creates a new worker of a shoot egressing through a NAT gateway.
+func newWorkerWithNATGateway() *v1alpha1.Worker {
+	worker := mock.NewWorker()
+	worker.Spec.InfrastructureProviderStatus = &runtime.RawExtension{
+		Raw: []byte(fmt.Sprintf(`{
+			"apiVersion": "hcloud.provider.extensions.gardener.cloud/v1alpha1",
+			"kind": "InfrastructureStatus",
+			"sshFingerprint": %q,
+			"floatingPoolName": "MY-FLOATING-POOL",
+			"natGatewayID": "44",
+			"natGatewayIP": "10.250.0.2"
+		}`, mock.TestSSHFingerprint)),
+	}
+
+	return worker
+}
+
+// newDualStackCluster creates a new cluster of a dual-stack shoot.
+func newDualStackCluster() *v1alpha1.Cluster {
+	cluster := mock.NewCluster()
+	cluster.Spec.Shoot.Raw = []byte(strings.Replace(mock.TestClusterShoot, `"region": "hel1",`, `"region": "hel1", "networking": {"ipFamilies": ["IPv4", "IPv6"]},`, 1))
+
+	return cluster
+}
+
 var (
 	mockTestEnv mock.MockTestEnv
 	scheme      *runtime.Scheme
@@ -93,6 +176,7 @@ var _ = AfterSuite(func() {
 var _ = Describe("Machines", func() {
 	Describe("#DeployMachineClasses", func() {
 		type setup struct {
+			extendedMachineClassFields bool
 		}
 
 		type action struct {
@@ -119,6 +203,9 @@ var _ = Describe("Machines", func() {
 				chartApplier := mockkubernetes.NewMockChartApplier(mockTestEnv.MockController)
 				ctx := context.TODO()
 
+				hcloud.ExtendedMachineClassFields = data.setup.extendedMachineClassFields
+				defer func() { hcloud.ExtendedMachineClassFields = false }()
+
 				mockTestEnv.Client.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(
 					func(_ context.Context, objectKey k8sclient.ObjectKey, secret *corev1.Secret, _ ...k8sclient.GetOption) error {
 						Expect(objectKey.Namespace).To(Equal(mock.TestNamespace))
@@ -195,6 +282,228 @@ var _ = Describe("Machines", func() {
 				},
 			}),
 
+			Entry("should deploy machine classes without SSH fingerprint if SSH access is disabled", &data{
+				setup: setup{},
+				action: action{
+					newClusterWithoutSSHAccess(),
+					mock.NewWorker(),
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					machineClasses: []map[string]interface{}{
+						{
+							"name": machineClassName,
+							"credentialsSecretRef": map[string]interface{}{
+								"name":      "secret",
+								"namespace": "test-namespace"},
+							"cluster":          mock.TestNamespace,
+							"zone":             mock.TestZone,
+							"imageName":        fmt.Sprintf("%s-%s", mock.TestWorkerMachineImageName, mock.TestWorkerMachineImageVersion),
+							"machineType":      mock.TestWorkerMachineType,
+							"floatingPoolName": mock.TestFloatingPoolName,
+							"networkName":      fmt.Sprintf("%s-workers", mock.TestNamespace),
+							"tags": map[string]string{
+								"mcm.gardener.cloud/cluster": mock.TestNamespace,
+								"mcm.gardener.cloud/role":    "node",
+							},
+							"secret": map[string]interface{}{
+								"hcloudToken": []byte("dummy-token"),
+								"userData":    mock.TestWorkerUserData,
+							},
+						},
+					},
+				},
+			}),
+
+			Entry("should deploy machine classes using reserved primary IPs", &data{
+				setup: setup{extendedMachineClassFields: true},
+				action: action{
+					mock.NewCluster(),
+					newWorkerWithReservedPrimaryIPs(),
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					machineClasses: []map[string]interface{}{
+						{
+							"name": machineClassName,
+							"credentialsSecretRef": map[string]interface{}{
+								"name":      "secret",
+								"namespace": "test-namespace"},
+							"cluster":          mock.TestNamespace,
+							"zone":             mock.TestZone,
+							"imageName":        fmt.Sprintf("%s-%s", mock.TestWorkerMachineImageName, mock.TestWorkerMachineImageVersion),
+							"sshFingerprint":   mock.TestSSHFingerprint,
+							"sshFingerprints":  []string{mock.TestSSHFingerprint},
+							"machineType":      mock.TestWorkerMachineType,
+							"floatingPoolName": mock.TestFloatingPoolName,
+							"networkName":      fmt.Sprintf("%s-workers", mock.TestNamespace),
+							"publicNet": map[string]interface{}{
+								"enableIPv4":     true,
+								"enableIPv6":     true,
+								"primaryIPv4IDs": []int64{42, 43},
+							},
+							"tags": map[string]string{
+								"mcm.gardener.cloud/cluster": mock.TestNamespace,
+								"mcm.gardener.cloud/role":    "node",
+							},
+							"secret": map[string]interface{}{
+								"hcloudToken": []byte("dummy-token"),
+								"userData":    mock.TestWorkerUserData,
+							},
+						},
+					},
+				},
+			}),
+
+			Entry("should deploy machine classes with public IPv6 addresses for dual-stack shoots", &data{
+				setup: setup{},
+				action: action{
+					newDualStackCluster(),
+					mock.NewWorker(),
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					machineClasses: []map[string]interface{}{
+						{
+							"name": machineClassName,
+							"credentialsSecretRef": map[string]interface{}{
+								"name":      "secret",
+								"namespace": "test-namespace"},
+							"cluster":          mock.TestNamespace,
+							"zone":             mock.TestZone,
+							"imageName":        fmt.Sprintf("%s-%s", mock.TestWorkerMachineImageName, mock.TestWorkerMachineImageVersion),
+							"sshFingerprint":   mock.TestSSHFingerprint,
+							"machineType":      mock.TestWorkerMachineType,
+							"floatingPoolName": mock.TestFloatingPoolName,
+							"networkName":      fmt.Sprintf("%s-workers", mock.TestNamespace),
+							"publicNet": map[string]interface{}{
+								"enableIPv4": true,
+								"enableIPv6": true,
+							},
+							"tags": map[string]string{
+								"mcm.gardener.cloud/cluster": mock.TestNamespace,
+								"mcm.gardener.cloud/role":    "node",
+							},
+							"secret": map[string]interface{}{
+								"hcloudToken": []byte("dummy-token"),
+								"userData":    mock.TestWorkerUserData,
+							},
+						},
+					},
+				},
+			}),
+
+			Entry("should deploy machine classes attached to the workers subnet of the network zone", &data{
+				setup: setup{extendedMachineClassFields: true},
+				action: action{
+					mock.NewCluster(),
+					newWorkerWithWorkersSubnets(),
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					machineClasses: []map[string]interface{}{
+						{
+							"name": machineClassName,
+							"credentialsSecretRef": map[string]interface{}{
+								"name":      "secret",
+								"namespace": "test-namespace"},
+							"cluster":          mock.TestNamespace,
+							"zone":             mock.TestZone,
+							"imageName":        fmt.Sprintf("%s-%s", mock.TestWorkerMachineImageName, mock.TestWorkerMachineImageVersion),
+							"sshFingerprint":   mock.TestSSHFingerprint,
+							"sshFingerprints":  []string{mock.TestSSHFingerprint},
+							"machineType":      mock.TestWorkerMachineType,
+							"floatingPoolName": mock.TestFloatingPoolName,
+							"networkName":      fmt.Sprintf("%s-workers", mock.TestNamespace),
+							"networkSubnet":    "10.250.0.0/21",
+							"tags": map[string]string{
+								"mcm.gardener.cloud/cluster": mock.TestNamespace,
+								"mcm.gardener.cloud/role":    "node",
+							},
+							"secret": map[string]interface{}{
+								"hcloudToken": []byte("dummy-token"),
+								"userData":    mock.TestWorkerUserData,
+							},
+						},
+					},
+				},
+			}),
+
+			Entry("should deploy machine classes without public IPs for shoots using a NAT gateway", &data{
+				setup: setup{extendedMachineClassFields: true},
+				action: action{
+					mock.NewCluster(),
+					newWorkerWithNATGateway(),
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					machineClasses: []map[string]interface{}{
+						{
+							"name": machineClassName,
+							"credentialsSecretRef": map[string]interface{}{
+								"name":      "secret",
+								"namespace": "test-namespace"},
+							"cluster":          mock.TestNamespace,
+							"zone":             mock.TestZone,
+							"imageName":        fmt.Sprintf("%s-%s", mock.TestWorkerMachineImageName, mock.TestWorkerMachineImageVersion),
+							"sshFingerprint":   mock.TestSSHFingerprint,
+							"sshFingerprints":  []string{mock.TestSSHFingerprint},
+							"machineType":      mock.TestWorkerMachineType,
+							"floatingPoolName": mock.TestFloatingPoolName,
+							"networkName":      fmt.Sprintf("%s-workers", mock.TestNamespace),
+							"publicNet": map[string]interface{}{
+								"enableIPv4": false,
+								"enableIPv6": false,
+							},
+							"tags": map[string]string{
+								"mcm.gardener.cloud/cluster": mock.TestNamespace,
+								"mcm.gardener.cloud/role":    "node",
+							},
+							"secret": map[string]interface{}{
+								"hcloudToken": []byte("dummy-token"),
+								"userData":    mock.TestWorkerUserData,
+							},
+						},
+					},
+				},
+			}),
+
+			Entry("should deploy machine classes tagged with the user-defined labels", &data{
+				setup: setup{},
+				action: action{
+					newClusterWithLabels(),
+					newWorkerWithLabels(),
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+					machineClasses: []map[string]interface{}{
+						{
+							"name": fmt.Sprintf("%s-%s-%s-%s", mock.TestNamespace, mock.TestWorkerPoolName, mock.TestZone, "e5c4e"),
+							"credentialsSecretRef": map[string]interface{}{
+								"name":      "secret",
+								"namespace": "test-namespace"},
+							"cluster":          mock.TestNamespace,
+							"zone":             mock.TestZone,
+							"imageName":        fmt.Sprintf("%s-%s", mock.TestWorkerMachineImageName, mock.TestWorkerMachineImageVersion),
+							"sshFingerprint":   mock.TestSSHFingerprint,
+							"machineType":      mock.TestWorkerMachineType,
+							"floatingPoolName": mock.TestFloatingPoolName,
+							"networkName":      fmt.Sprintf("%s-workers", mock.TestNamespace),
+							"tags": map[string]string{
+								"environment":                "test",
+								"mcm.gardener.cloud/cluster": mock.TestNamespace,
+								"mcm.gardener.cloud/role":    "node",
+								"team":                       "b",
+							},
+							"secret": map[string]interface{}{
+								"hcloudToken": []byte("dummy-token"),
+								"userData":    mock.TestWorkerUserData,
+							},
+						},
+					},
+				},
+			}),
+
 			Entry("should not generate machine classes because of missing zones", &data{
 				setup: setup{},
 				action: action{
@@ -46,6 +46,8 @@ type AddOptions struct {
 	// GardenCluster is the garden cluster object.
 	GardenCluster  cluster.Cluster
 	ExtensionClass extensionsv1alpha1.ExtensionClass
+	// GardenId is the Gardener garden identity
+	GardenId string
 }
 
 // AddToManagerWithOptions adds a controller with the given Options to the given manager.
@@ -64,7 +66,7 @@ func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts AddO
 		return err
 	}
 
-	actuator, err := NewActuator(mgr, opts.GardenCluster)
+	actuator, err := NewActuator(mgr, opts.GardenCluster, opts.GardenId)
 	if err != nil {
 		return err
 	}
@@ -39,9 +39,17 @@ type ControllerConfiguration struct {
 	ClientConnection *config.ClientConnectionConfiguration `json:"clientConnection,omitempty"`
 	// ETCD is the etcd configuration.
 	ETCD *ETCD `json:"etcd"`
+	// ExtendedMachineClassFields enables machine class fields requiring a machine-controller-manager-provider-hcloud
+	// image newer than the one of charts/images.yaml, e.g. to assign reserved primary IPs to worker nodes.
+	// +optional
+	ExtendedMachineClassFields bool `json:"extendedMachineClassFields,omitempty"`
 	// HealthCheckConfig is the config for the health check controller
 	// +optional
 	HealthCheckConfig *extensionconfig.HealthCheckConfig `json:"healthCheckConfig,omitempty"`
+	// InfrastructureDriftCheckConfig is the config for the infrastructure drift health check. It defaults to a
+	// longer sync period than the other health checks as each check requests the HCloud API.
+	// +optional
+	InfrastructureDriftCheckConfig *extensionconfig.HealthCheckConfig `json:"infrastructureDriftCheckConfig,omitempty"`
 	// HealthProbeBindAddress is the TCP address that the controller should bind to
 	// for serving health probes
 	// It can be set to "0" to disable the health probes listener.
@@ -52,6 +60,10 @@ type ControllerConfiguration struct {
 	// It can be set to "0" to disable the metrics serving.
 	// +optional
 	MetricsBindAddress string `json:"metricsBindAddress,omitempty"`
+	// RepairInfrastructureDrift triggers a reconciliation of infrastructures whose HCloud resources have been
+	// deleted or changed outside of Gardener.
+	// +optional
+	RepairInfrastructureDrift bool `json:"repairInfrastructureDrift,omitempty"`
 }
 
 // ETCD is an etcd configuration.
@@ -64,9 +64,12 @@ func autoConvert_v1alpha1_ControllerConfiguration_To_config_ControllerConfigurat
 	out.GardenId = in.GardenId
 	out.ClientConnection = (*componentbaseconfig.ClientConnectionConfiguration)(unsafe.Pointer(in.ClientConnection))
 	out.ETCD = (*config.ETCD)(unsafe.Pointer(in.ETCD))
+	out.ExtendedMachineClassFields = in.ExtendedMachineClassFields
 	out.HealthCheckConfig = (*apisconfig.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
+	out.InfrastructureDriftCheckConfig = (*apisconfig.HealthCheckConfig)(unsafe.Pointer(in.InfrastructureDriftCheckConfig))
 	out.HealthProbeBindAddress = in.HealthProbeBindAddress
 	out.MetricsBindAddress = in.MetricsBindAddress
+	out.RepairInfrastructureDrift = in.RepairInfrastructureDrift
 	return nil
 }
 
@@ -79,9 +82,12 @@ func autoConvert_config_ControllerConfiguration_To_v1alpha1_ControllerConfigurat
 	out.GardenId = in.GardenId
 	out.ClientConnection = (*componentbaseconfig.ClientConnectionConfiguration)(unsafe.Pointer(in.ClientConnection))
 	out.ETCD = (*ETCD)(unsafe.Pointer(in.ETCD))
+	out.ExtendedMachineClassFields = in.ExtendedMachineClassFields
 	out.HealthCheckConfig = (*apisconfig.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
+	out.InfrastructureDriftCheckConfig = (*apisconfig.HealthCheckConfig)(unsafe.Pointer(in.InfrastructureDriftCheckConfig))
 	out.HealthProbeBindAddress = in.HealthProbeBindAddress
 	out.MetricsBindAddress = in.MetricsBindAddress
+	out.RepairInfrastructureDrift = in.RepairInfrastructureDrift
 	return nil
 }
 
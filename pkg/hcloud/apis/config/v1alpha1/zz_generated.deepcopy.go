@@ -34,6 +34,11 @@ func (in *ControllerConfiguration) DeepCopyInto(out *ControllerConfiguration) {
 		*out = new(apisconfig.HealthCheckConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InfrastructureDriftCheckConfig != nil {
+		in, out := &in.InfrastructureDriftCheckConfig, &out.InfrastructureDriftCheckConfig
+		*out = new(apisconfig.HealthCheckConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -17,8 +17,14 @@ limitations under the License.
 // Package controller provides functions to access controller specifications
 package controller
 
-type InfrastructureReconcileMethodData struct {
-	NetworkID         int64
-	PlacementGroupIDs []int64
-	SSHKeyID          int64
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestController(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Controller APIs Suite")
 }
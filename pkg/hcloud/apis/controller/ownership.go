@@ -0,0 +1,252 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller provides functions to access controller specifications
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// LabelClusterID is the label containing the UID of the shoot owning a HCloud resource.
+	LabelClusterID = "cluster.gardener.cloud/id"
+	// LabelGardenID is the label containing the ID of the garden owning a HCloud resource.
+	LabelGardenID = "hcloud.provider.extensions.gardener.cloud/garden-id"
+	// LabelRole is the label containing the role of a HCloud resource.
+	LabelRole = "hcloud.provider.extensions.gardener.cloud/role"
+	// LabelReferencePrefix is the label prefix marking a shared HCloud resource as used by the shoot UID appended.
+	LabelReferencePrefix = "hcloud.provider.extensions.gardener.cloud/user-"
+	// LabelUserLabelPrefix is the label prefix marking a user-defined label as added by this extension with a hash of
+	// its key appended. It allows removing user-defined labels dropped from the config later on.
+	LabelUserLabelPrefix = "hcloud.provider.extensions.gardener.cloud/label-"
+	// EventReasonDeletionRefused is the event reason used if the deletion of a foreign HCloud resource is refused.
+	EventReasonDeletionRefused = "DeletionRefused"
+)
+
+// ResourceOwner identifies the shoot owning HCloud resources.
+type ResourceOwner struct {
+	ClusterID string
+	GardenID  string
+
+	userLabels map[string]string
+	recorder   record.EventRecorder
+	object     runtime.Object
+}
+
+// NewResourceOwner returns a new ResourceOwner instance.
+//
+// PARAMETERS
+// clusterID string               Shoot UID
+// gardenID  string               Garden ID
+// recorder  record.EventRecorder Event recorder used to report refused deletions
+// object    runtime.Object       Object events are recorded for
+func NewResourceOwner(clusterID, gardenID string, recorder record.EventRecorder, object runtime.Object) *ResourceOwner {
+	return &ResourceOwner{
+		ClusterID: clusterID,
+		GardenID:  gardenID,
+		recorder:  recorder,
+		object:    object,
+	}
+}
+
+// WithLabels returns a copy of the resource owner adding the given user-defined labels to the labels of HCloud
+// resources. Labels given override the user-defined labels added before.
+//
+// PARAMETERS
+// labels map[string]string User-defined labels
+func (o *ResourceOwner) WithLabels(labels map[string]string) *ResourceOwner {
+	owner := *o
+	owner.userLabels = map[string]string{}
+
+	maps.Copy(owner.userLabels, o.userLabels)
+	maps.Copy(owner.userLabels, labels)
+
+	return &owner
+}
+
+// Labels returns the user-defined labels and the labels identifying a HCloud resource with the given role as owned.
+//
+// PARAMETERS
+// role string HCloud resource role
+func (o *ResourceOwner) Labels(role string) map[string]string {
+	labels := o.IdentityLabels(role)
+
+	for key, value := range o.userLabels {
+		if _, ok := labels[key]; ok {
+			continue
+		}
+
+		labels[key] = value
+		labels[getUserLabelMarker(key)] = "true"
+	}
+
+	return labels
+}
+
+// IdentityLabels returns the labels identifying a HCloud resource with the given role as owned. User-defined labels
+// are omitted as they are specific to the shoot, e.g. for resources shared between shoots.
+//
+// PARAMETERS
+// role string HCloud resource role
+func (o *ResourceOwner) IdentityLabels(role string) map[string]string {
+	labels := map[string]string{
+		LabelClusterID: o.ClusterID,
+		LabelRole:      role,
+	}
+
+	if "" != o.GardenID {
+		labels[LabelGardenID] = o.GardenID
+	}
+
+	return labels
+}
+
+// MergeLabels returns the given HCloud resource labels with the labels of the given role added and true if a label
+// has been added, changed or removed. User-defined labels added before but dropped from the config are removed while
+// labels not set by this extension are kept.
+//
+// PARAMETERS
+// labels map[string]string HCloud resource labels
+// role   string            HCloud resource role
+func (o *ResourceOwner) MergeLabels(labels map[string]string, role string) (map[string]string, bool) {
+	mergedLabels := map[string]string{}
+
+	for key, value := range labels {
+		if strings.HasPrefix(key, LabelUserLabelPrefix) {
+			continue
+		}
+
+		if _, ok := o.userLabels[key]; !ok {
+			if _, ok := labels[getUserLabelMarker(key)]; ok {
+				continue
+			}
+		}
+
+		mergedLabels[key] = value
+	}
+
+	maps.Copy(mergedLabels, o.Labels(role))
+
+	return mergedLabels, !maps.Equal(labels, mergedLabels)
+}
+
+// getUserLabelMarker returns the label marking the user-defined label with the given key as added by this extension.
+//
+// PARAMETERS
+// key string User-defined label key
+func getUserLabelMarker(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return LabelUserLabelPrefix + hex.EncodeToString(hash[:8])
+}
+
+// IsOwnerOf returns true if the given labels identify a HCloud resource as owned by the shoot. The cluster ID is
+// required while the garden ID is only compared if set. The role must match if given.
+//
+// PARAMETERS
+// labels map[string]string HCloud resource labels
+// role   string            HCloud resource role expected
+func (o *ResourceOwner) IsOwnerOf(labels map[string]string, role string) bool {
+	if clusterID, ok := labels[LabelClusterID]; !ok || clusterID != o.ClusterID {
+		return false
+	}
+
+	return o.IsManagerOf(labels, role)
+}
+
+// IsManagerOf returns true if the given labels identify a HCloud resource as managed by this garden without checking
+// the cluster ID, e.g. for resources shared between shoots. The role must match if given.
+//
+// PARAMETERS
+// labels map[string]string HCloud resource labels
+// role   string            HCloud resource role expected
+func (o *ResourceOwner) IsManagerOf(labels map[string]string, role string) bool {
+	if "" != role && labels[LabelRole] != role {
+		return false
+	}
+
+	if gardenID, ok := labels[LabelGardenID]; ok && gardenID != o.GardenID {
+		return false
+	}
+
+	return true
+}
+
+// IsAdoptable returns true if the given labels identify a HCloud resource with the given role created by an earlier
+// version only labeling resources with their role. Callers must only adopt such resources if they are referenced by
+// the status of the shoot, e.g. by their ID, and add the owner labels afterwards.
+//
+// PARAMETERS
+// labels map[string]string HCloud resource labels
+// role   string            HCloud resource role expected
+func (o *ResourceOwner) IsAdoptable(labels map[string]string, role string) bool {
+	if "" == role || labels[LabelRole] != role {
+		return false
+	}
+
+	if _, ok := labels[LabelClusterID]; ok {
+		return false
+	}
+
+	_, ok := labels[LabelGardenID]
+
+	return !ok
+}
+
+// ReferenceLabel returns the label marking a shared HCloud resource as used by this shoot. An empty string is
+// returned if the shoot UID is unknown.
+func (o *ResourceOwner) ReferenceLabel() string {
+	if "" == o.ClusterID {
+		return ""
+	}
+
+	return LabelReferencePrefix + o.ClusterID
+}
+
+// HasReferences returns true if the given labels mark a shared HCloud resource as used by any shoot.
+//
+// PARAMETERS
+// labels map[string]string HCloud resource labels
+func HasReferences(labels map[string]string) bool {
+	for key := range labels {
+		if strings.HasPrefix(key, LabelReferencePrefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RefuseDeletion records an event for a HCloud resource not deleted as it is not owned.
+//
+// PARAMETERS
+// kind string HCloud resource kind
+// name string HCloud resource name
+// id   int64  HCloud resource ID
+func (o *ResourceOwner) RefuseDeletion(kind, name string, id int64) {
+	if nil == o.recorder || nil == o.object {
+		return
+	}
+
+	o.recorder.Eventf(o.object, corev1.EventTypeWarning, EventReasonDeletionRefused, "Refused to delete %s %q (%d) not owned by this shoot", kind, name, id)
+}
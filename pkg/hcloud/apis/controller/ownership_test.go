@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller provides functions to access controller specifications
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+var _ = Describe("Ownership", func() {
+	Describe("#IsOwnerOf", func() {
+		owner := NewResourceOwner("shoot-uid", "garden", nil, nil)
+
+		DescribeTable("##table",
+			func(labels map[string]string, role string, expected bool) {
+				Expect(owner.IsOwnerOf(labels, role)).To(Equal(expected))
+			},
+
+			Entry("labels created", owner.Labels("test-v1"), "test-v1", true),
+			Entry("labels of an earlier version", map[string]string{LabelRole: "test-v1"}, "test-v1", false),
+			Entry("labels without garden ID", map[string]string{LabelClusterID: "shoot-uid", LabelRole: "test-v1"}, "test-v1", true),
+			Entry("role not checked", map[string]string{LabelClusterID: "shoot-uid"}, "", true),
+			Entry("no labels", nil, "", false),
+			Entry("role mismatch", owner.Labels("other-v1"), "test-v1", false),
+			Entry("cluster ID mismatch", map[string]string{LabelClusterID: "other-uid", LabelRole: "test-v1"}, "test-v1", false),
+			Entry("garden ID mismatch", map[string]string{LabelClusterID: "shoot-uid", LabelGardenID: "other", LabelRole: "test-v1"}, "test-v1", false),
+		)
+	})
+
+	Describe("#IsManagerOf", func() {
+		owner := NewResourceOwner("shoot-uid", "garden", nil, nil)
+
+		DescribeTable("##table",
+			func(labels map[string]string, role string, expected bool) {
+				Expect(owner.IsManagerOf(labels, role)).To(Equal(expected))
+			},
+
+			Entry("labels of another shoot", map[string]string{LabelClusterID: "other-uid", LabelGardenID: "garden", LabelRole: "test-v1"}, "test-v1", true),
+			Entry("role mismatch", map[string]string{LabelGardenID: "garden", LabelRole: "other-v1"}, "test-v1", false),
+			Entry("garden ID mismatch", map[string]string{LabelGardenID: "other", LabelRole: "test-v1"}, "test-v1", false),
+		)
+	})
+
+	Describe("#IsAdoptable", func() {
+		owner := NewResourceOwner("shoot-uid", "garden", nil, nil)
+
+		DescribeTable("##table",
+			func(labels map[string]string, role string, expected bool) {
+				Expect(owner.IsAdoptable(labels, role)).To(Equal(expected))
+			},
+
+			Entry("labels of an earlier version", map[string]string{LabelRole: "test-v1"}, "test-v1", true),
+			Entry("no labels", nil, "test-v1", false),
+			Entry("role mismatch", map[string]string{LabelRole: "other-v1"}, "test-v1", false),
+			Entry("labels of another shoot", map[string]string{LabelClusterID: "other-uid", LabelRole: "test-v1"}, "test-v1", false),
+			Entry("labels of another garden", map[string]string{LabelGardenID: "other", LabelRole: "test-v1"}, "test-v1", false),
+		)
+	})
+
+	Describe("#Labels", func() {
+		owner := NewResourceOwner("shoot-uid", "garden", nil, nil).WithLabels(map[string]string{"team": "a", LabelClusterID: "other-uid"}).WithLabels(map[string]string{"team": "b"})
+
+		It("should add user-defined labels without overriding the owner labels", func() {
+			Expect(owner.Labels("test-v1")).To(Equal(map[string]string{
+				"team":                     "b",
+				getUserLabelMarker("team"): "true",
+				LabelClusterID:             "shoot-uid",
+				LabelGardenID:              "garden",
+				LabelRole:                  "test-v1",
+			}))
+		})
+
+		It("should omit user-defined labels from the identity labels", func() {
+			Expect(owner.IdentityLabels("test-v1")).NotTo(HaveKey("team"))
+		})
+	})
+
+	Describe("#MergeLabels", func() {
+		owner := NewResourceOwner("shoot-uid", "garden", nil, nil).WithLabels(map[string]string{"team": "a"})
+
+		It("should keep foreign labels and report changes", func() {
+			labels, changed := owner.MergeLabels(map[string]string{"foreign": "true", LabelRole: "test-v1"}, "test-v1")
+			Expect(changed).To(BeTrue())
+			Expect(labels).To(HaveKeyWithValue("foreign", "true"))
+			Expect(labels).To(HaveKeyWithValue("team", "a"))
+		})
+
+		It("should report unchanged labels", func() {
+			_, changed := owner.MergeLabels(owner.Labels("test-v1"), "test-v1")
+			Expect(changed).To(BeFalse())
+		})
+
+		It("should remove user-defined labels dropped from the config only", func() {
+			previousOwner := NewResourceOwner("shoot-uid", "garden", nil, nil).WithLabels(map[string]string{"team": "a", "dropped": "true"})
+
+			previousLabels := previousOwner.Labels("test-v1")
+			previousLabels["foreign"] = "true"
+
+			labels, changed := owner.MergeLabels(previousLabels, "test-v1")
+			Expect(changed).To(BeTrue())
+			Expect(labels).To(HaveKeyWithValue("foreign", "true"))
+			Expect(labels).To(HaveKeyWithValue("team", "a"))
+			Expect(labels).NotTo(HaveKey("dropped"))
+			Expect(labels).NotTo(HaveKey(getUserLabelMarker("dropped")))
+			Expect(labels).To(HaveKey(getUserLabelMarker("team")))
+		})
+	})
+
+	Describe("#ReferenceLabel", func() {
+		It("should return the label containing the shoot UID", func() {
+			owner := NewResourceOwner("shoot-uid", "garden", nil, nil)
+			Expect(owner.ReferenceLabel()).To(Equal(LabelReferencePrefix + "shoot-uid"))
+		})
+
+		It("should return an empty label for an unknown shoot UID", func() {
+			owner := NewResourceOwner("", "garden", nil, nil)
+			Expect(owner.ReferenceLabel()).To(BeEmpty())
+		})
+	})
+
+	Describe("#HasReferences", func() {
+		DescribeTable("##table",
+			func(labels map[string]string, expected bool) {
+				Expect(HasReferences(labels)).To(Equal(expected))
+			},
+
+			Entry("no labels", nil, false),
+			Entry("owner labels only", map[string]string{LabelClusterID: "shoot-uid", LabelRole: "test-v1"}, false),
+			Entry("reference label", map[string]string{LabelReferencePrefix + "shoot-uid": "true", LabelRole: "test-v1"}, true),
+		)
+	})
+
+	Describe("#RefuseDeletion", func() {
+		It("should record a warning event", func() {
+			recorder := record.NewFakeRecorder(1)
+			owner := NewResourceOwner("shoot-uid", "garden", recorder, &corev1.Secret{})
+
+			owner.RefuseDeletion("network", "test-workers", 42)
+
+			Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " " + EventReasonDeletionRefused)))
+		})
+	})
+})
@@ -102,6 +102,129 @@ func ManipulateInfrastructure(infrastructure *v1alpha1.Infrastructure, data map[
 	return infrastructure
 }
 
+// SetupFirewallsEndpointOnMux configures a "/firewalls" endpoint on the mux given.
+//
+// PARAMETERS
+// mux *http.ServeMux Mux to add handler to
+func SetupFirewallsEndpointOnMux(mux *http.ServeMux) {
+	mux.HandleFunc("/firewalls", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+		if req.Method == http.MethodPost {
+			res.WriteHeader(http.StatusCreated)
+
+			_, _ = res.Write([]byte(`
+{
+	"firewall": {
+		"id": 42,
+		"name": "Simulated firewall",
+		"labels": {},
+		"created": "2016-01-30T23:50:00+00:00",
+		"rules": [],
+		"applied_to": []
+	},
+	"actions": []
+}
+			`))
+
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+
+		_, _ = res.Write([]byte(`
+{
+	"firewalls": []
+}
+		`))
+	})
+}
+
+// SetupFirewallEndpointOnMux configures a "/firewalls/42" endpoint on the mux given.
+//
+// PARAMETERS
+// mux *http.ServeMux Mux to add handler to
+func SetupFirewallEndpointOnMux(mux *http.ServeMux) {
+	mux.HandleFunc("/firewalls/42", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+		res.WriteHeader(http.StatusOK)
+
+		_, _ = res.Write([]byte(`
+{
+	"firewall": {
+		"id": 42,
+		"name": "Simulated firewall",
+		"labels": {"cluster.gardener.cloud/id": "", "hcloud.provider.extensions.gardener.cloud/role": "infrastructure-firewall-v1"},
+		"created": "2016-01-30T23:50:00+00:00",
+		"rules": [],
+		"applied_to": []
+	}
+}
+		`))
+	})
+
+	mux.HandleFunc("/firewalls/42/actions/", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+		res.WriteHeader(http.StatusCreated)
+
+		_, _ = res.Write([]byte(`
+{
+	"actions": []
+}
+		`))
+	})
+}
+
+// SetupFloatingIPsEndpointOnMux configures a "/floating_ips" endpoint on the mux given.
+//
+// PARAMETERS
+// mux *http.ServeMux Mux to add handler to
+func SetupFloatingIPsEndpointOnMux(mux *http.ServeMux) {
+	mux.HandleFunc("/floating_ips", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+		if req.Method == http.MethodPost {
+			res.WriteHeader(http.StatusCreated)
+
+			_, _ = res.Write([]byte(`
+{
+	"floating_ip": {
+		"id": 43,
+		"name": "Simulated floating IP",
+		"description": null,
+		"ip": "131.232.99.1",
+		"type": "ipv4",
+		"server": null,
+		"dns_ptr": [],
+		"home_location": {
+			"id": 1,
+			"name": "hel1",
+			"network_zone": "eu-central"
+		},
+		"blocked": false,
+		"protection": {"delete": false},
+		"labels": {},
+		"created": "2016-01-30T23:50:00+00:00"
+	},
+	"action": null
+}
+			`))
+
+			return
+		}
+
+		res.WriteHeader(http.StatusOK)
+
+		_, _ = res.Write([]byte(`
+{
+	"floating_ips": []
+}
+		`))
+	})
+}
+
 // SetupLocationsEndpointOnMux configures a "/locations" endpoint on the mux given.
 //
 // PARAMETERS
@@ -139,6 +262,28 @@ func SetupNetworksEndpointOnMux(mux *http.ServeMux) {
 	mux.HandleFunc("/networks", func(res http.ResponseWriter, req *http.Request) {
 		res.Header().Add("Content-Type", "application/json; charset=utf-8")
 
+		if req.Method == http.MethodPost {
+			res.WriteHeader(http.StatusCreated)
+
+			_, _ = res.Write([]byte(`
+{
+	"network": {
+		"id": 42,
+		"name": "Simulated network",
+		"ip_range": "10.250.0.0/19",
+		"subnets": [],
+		"routes": [],
+		"servers": [],
+		"load_balancers": [],
+		"labels": {},
+		"created": "2016-01-30T23:50:00+00:00"
+	}
+}
+			`))
+
+			return
+		}
+
 		res.WriteHeader(http.StatusOK)
 
 		queryParams := req.URL.Query()
@@ -171,6 +316,39 @@ func SetupNetworksEndpointOnMux(mux *http.ServeMux) {
 	})
 }
 
+// SetupNetworkEndpointOnMux configures a "/networks/42" endpoint on the mux given.
+//
+// PARAMETERS
+// mux *http.ServeMux Mux to add handler to
+func SetupNetworkEndpointOnMux(mux *http.ServeMux) {
+	mux.HandleFunc("/networks/42", func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Add("Content-Type", "application/json; charset=utf-8")
+
+		res.WriteHeader(http.StatusOK)
+
+		_, _ = res.Write([]byte(`
+{
+	"network": {
+		"id": 42,
+		"name": "Simulated network",
+		"ip_range": "10.250.0.0/19",
+		"subnets": [
+			{"type": "cloud", "ip_range": "10.250.0.0/19", "network_zone": "eu-central", "gateway": "10.250.0.1"}
+		],
+		"routes": [
+			{"destination": "10.96.0.0/24", "gateway": "10.250.0.2"},
+			{"destination": "10.96.1.0/24", "gateway": "10.250.0.3"}
+		],
+		"servers": [],
+		"load_balancers": [],
+		"labels": {"cluster.gardener.cloud/id": "", "hcloud.provider.extensions.gardener.cloud/role": "workers-network-v1"},
+		"created": "2016-01-30T23:50:00+00:00"
+	}
+}
+		`))
+	})
+}
+
 // SetupPlacementGroupsEndpointOnMux configures a "/placement_groups" endpoint on the mux given.
 //
 // PARAMETERS
@@ -18,9 +18,11 @@ limitations under the License.
 package transcoder
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/gardener/gardener/extensions/pkg/controller"
+	webhookcontext "github.com/gardener/gardener/extensions/pkg/webhook/context"
 	"github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -65,6 +67,17 @@ func DecodeInfrastructureConfigFromCluster(cluster *controller.Cluster) (*apis.I
 	return infraConfig, nil
 }
 
+// DecodeInfrastructureConfigFromGardenContext extracts the InfrastructureConfig
+// from the shoot of the given GardenContext.
+func DecodeInfrastructureConfigFromGardenContext(ctx context.Context, webhookcontext webhookcontext.GardenContext) (*apis.InfrastructureConfig, error) {
+	cluster, err := webhookcontext.GetCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeInfrastructureConfigFromCluster(cluster)
+}
+
 // DecodeInfrastructureConfigFromInfrastructure extracts the
 // InfrastructureConfig from the ProviderConfig section of the given
 // Infrastructure.
@@ -101,3 +114,44 @@ func DecodeInfrastructureStatusFromInfrastructure(infra *v1alpha1.Infrastructure
 
 	return infraStatus, nil
 }
+
+// DecodeInfrastructureState extracts the InfrastructureState from the
+// given RawExtension.
+func DecodeInfrastructureState(state *runtime.RawExtension) (*apis.InfrastructureState, error) {
+	infraState := &apis.InfrastructureState{}
+
+	if state == nil || state.Raw == nil {
+		return infraState, nil
+	}
+
+	if _, _, err := decoder.Decode(state.Raw, nil, infraState); err != nil {
+		return nil, fmt.Errorf("could not decode infrastructureState: %w", err)
+	}
+
+	return infraState, nil
+}
+
+func DecodeInfrastructureStateFromInfrastructure(infra *v1alpha1.Infrastructure) (*apis.InfrastructureState, error) {
+	infraState, err := DecodeInfrastructureState(infra.Status.State)
+	if err != nil {
+		return nil, err
+	}
+
+	return infraState, nil
+}
+
+// DecodeLatestInfrastructureStatusFromInfrastructure extracts the InfrastructureStatus persisted last. The state is
+// updated after each step of a reconciliation and takes precedence over the provider status only updated after a
+// successful one.
+func DecodeLatestInfrastructureStatusFromInfrastructure(infra *v1alpha1.Infrastructure) (*apis.InfrastructureStatus, error) {
+	infraState, err := DecodeInfrastructureStateFromInfrastructure(infra)
+	if err != nil {
+		return nil, err
+	}
+
+	if infraState.ProviderStatus != nil {
+		return infraState.ProviderStatus, nil
+	}
+
+	return DecodeInfrastructureStatusFromInfrastructure(infra)
+}
@@ -46,6 +46,10 @@ type CloudControllerManagerConfig struct {
 	// FeatureGates contains information about enabled feature gates.
 	// +optional
 	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+	// NativeRouting disables the HCloud network routes created for the pod CIDR of each node. Pod traffic has to be
+	// routed by the CNI instead, e.g. in overlay mode.
+	// +optional
+	NativeRouting *bool `json:"nativeRouting,omitempty"`
 }
 
 // CPLoadBalancerClass provides the name of a load balancer
@@ -34,6 +34,18 @@ type InfrastructureConfig struct {
 	// Networks is the HCloud specific network configuration
 	// +optional
 	Networks *InfrastructureConfigNetworks `json:"networks,omitempty"`
+	// Firewall is the HCloud specific firewall configuration applied to the worker nodes
+	// +optional
+	Firewall *InfrastructureConfigFirewall `json:"firewall,omitempty"`
+	// NATGateway is the configuration of a NAT gateway server used by worker nodes without public IPs
+	// +optional
+	NATGateway *InfrastructureConfigNATGateway `json:"natGateway,omitempty"`
+	// FloatingIPs is the configuration of the floating IPs managed in the pool named by FloatingPoolName
+	// +optional
+	FloatingIPs *InfrastructureConfigFloatingIPs `json:"floatingIPs,omitempty"`
+	// Labels are added to the HCloud resources of the shoot in addition to the labels identifying the shoot.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // Networks holds information about the Kubernetes and infrastructure networks.
@@ -42,6 +54,41 @@ type InfrastructureConfigNetworks struct {
 	WorkersConfiguration *InfrastructureConfigNetwork `json:"workersConfiguration"`
 	// Workers is a CIDRs of a worker subnet (private) to create (used for the VMs).
 	Workers string `json:"workers,omitempty"`
+	// Existing references an existing HCloud network the worker subnet is added to instead of creating a new network.
+	// +optional
+	Existing *InfrastructureConfigExistingNetwork `json:"existing,omitempty"`
+	// Shared references a network managed by the extension and shared between shoots the worker subnet is added to.
+	// +optional
+	Shared *InfrastructureConfigSharedNetwork `json:"shared,omitempty"`
+	// VSwitch is a struct of a vSwitch subnet configuration to connect Hetzner Robot servers with the workers network.
+	// +optional
+	VSwitch *InfrastructureConfigVSwitch `json:"vSwitch,omitempty"`
+}
+
+// InfrastructureConfigExistingNetwork references an existing HCloud network by ID or name.
+type InfrastructureConfigExistingNetwork struct {
+	// ID is the HCloud network ID.
+	// +optional
+	ID int64 `json:"id,omitempty"`
+	// Name is the HCloud network name.
+	// +optional
+	Name string `json:"name,omitempty"`
+}
+
+// InfrastructureConfigSharedNetwork identifies a network managed by the extension and shared between shoots.
+type InfrastructureConfigSharedNetwork struct {
+	// Name is the name identifying the shared network.
+	Name string `json:"name"`
+	// Cidr is the IP range of the shared network. It must contain the workers CIDR of all shoots using it.
+	Cidr string `json:"cidr"`
+}
+
+// InfrastructureConfigVSwitch holds information about a vSwitch subnet of the workers network.
+type InfrastructureConfigVSwitch struct {
+	// ID is the Hetzner Robot vSwitch ID.
+	ID int64 `json:"id"`
+	// Cidr is the CIDR of the vSwitch subnet to create. It must be part of the workers network IP range.
+	Cidr string `json:"cidr"`
 }
 
 // InfrastructureConfig holds information about the Kubernetes and infrastructure network.
@@ -52,6 +99,55 @@ type InfrastructureConfigNetwork struct {
 	Zone hcloud.NetworkZone `json:"zone,omitempty"`
 }
 
+// InfrastructureConfigNATGateway holds information about the NAT gateway server of the workers network.
+type InfrastructureConfigNATGateway struct {
+	// ServerType is the HCloud server type of the NAT gateway server.
+	// +optional
+	ServerType string `json:"serverType,omitempty"`
+	// ImageName is the HCloud image name of the NAT gateway server.
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+}
+
+// InfrastructureConfigFloatingIPs holds information about the floating IPs of the floating pool.
+type InfrastructureConfigFloatingIPs struct {
+	// Count is the number of floating IPs in the pool.
+	Count int `json:"count"`
+	// IPFamily is the IP family of the floating IPs ("ipv4" or "ipv6"). Defaults to "ipv4".
+	IPFamily hcloud.FloatingIPType `json:"ipFamily,omitempty"`
+}
+
+// InfrastructureConfigFirewall holds information about the firewall applied to the worker nodes.
+type InfrastructureConfigFirewall struct {
+	// NodePortRange is the service node port range of the kube-apiserver (e.g. "30000-32767") opened to any source.
+	// Defaults to the Kubernetes default range.
+	// +optional
+	NodePortRange string `json:"nodePortRange,omitempty"`
+	// Rules is a list of firewall rules applied in addition to the generated default rules.
+	// +optional
+	Rules []InfrastructureConfigFirewallRule `json:"rules,omitempty"`
+}
+
+// InfrastructureConfigFirewallRule holds information about a single firewall rule.
+type InfrastructureConfigFirewallRule struct {
+	// Direction is the traffic direction the rule applies to ("in" or "out").
+	Direction hcloud.FirewallRuleDirection `json:"direction"`
+	// Protocol is the protocol the rule applies to ("tcp", "udp", "icmp", "esp" or "gre").
+	Protocol hcloud.FirewallRuleProtocol `json:"protocol"`
+	// Port is the port or port range (e.g. "80" or "30000-32767") the rule applies to. Required for "tcp" and "udp".
+	// +optional
+	Port string `json:"port,omitempty"`
+	// SourceIPs is a list of CIDRs the rule applies to for inbound traffic.
+	// +optional
+	SourceIPs []string `json:"sourceIPs,omitempty"`
+	// DestinationIPs is a list of CIDRs the rule applies to for outbound traffic.
+	// +optional
+	DestinationIPs []string `json:"destinationIPs,omitempty"`
+	// Description is an optional description of the rule.
+	// +optional
+	Description string `json:"description,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // InfrastructureStatus contains information about created infrastructure resources.
@@ -59,9 +155,11 @@ type InfrastructureStatus struct {
 	metav1.TypeMeta `json:",inline"`
 	// SSHFingerprint contains the SSH fingerprint.
 	SSHFingerprint string `json:"sshFingerprint"`
+	// PreviousSSHFingerprint contains the SSH fingerprint replaced by a SSH keypair rotation still in progress.
+	PreviousSSHFingerprint string `json:"previousSSHFingerprint,omitempty"`
 
 	// PlacementGroupIDs contains the placement group IDs.
-	PlacementGroupIDs map[string]string `json:"placementGroupID,omitempty"`
+	PlacementGroupIDs map[string]string `json:"placementGroupIDs,omitempty"`
 	// PlacementGroupID contains the placement group ID.
 	PlacementGroupID string `json:"placementGroupID,omitempty"`
 	// FloatingPoolName contains the FloatingPoolName name in which LoadBalancer FIPs should be created.
@@ -70,10 +168,64 @@ type InfrastructureStatus struct {
 	// Networks is the HCloud specific network configuration
 	// +optional
 	NetworkIDs *InfrastructureConfigNetworkIDs `json:"networkIDs,omitempty"`
+	// FirewallID contains the HCloud firewall ID applied to the worker nodes.
+	// +optional
+	FirewallID string `json:"firewallID,omitempty"`
+	// NATGatewayID contains the HCloud server ID of the NAT gateway.
+	// +optional
+	NATGatewayID string `json:"natGatewayID,omitempty"`
+	// NATGatewayIP contains the private IP of the NAT gateway in the workers network.
+	// +optional
+	NATGatewayIP string `json:"natGatewayIP,omitempty"`
+	// FloatingIPIDs contains the HCloud floating IP IDs of the floating pool.
+	// +optional
+	FloatingIPIDs []string `json:"floatingIPIDs,omitempty"`
+	// NetworkRoutes contains the route usage of the workers network.
+	// +optional
+	NetworkRoutes *InfrastructureStatusNetworkRoutes `json:"networkRoutes,omitempty"`
+}
+
+// InfrastructureStatusNetworkRoutes contains the route usage of the workers network.
+type InfrastructureStatusNetworkRoutes struct {
+	// Used is the number of routes of the workers network.
+	Used int `json:"used"`
+	// Limit is the maximum number of routes of an HCloud network.
+	Limit int `json:"limit"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// InfrastructureState contains information about infrastructure resources persisted for control plane migration.
+type InfrastructureState struct {
+	metav1.TypeMeta `json:",inline"`
+	// ProviderStatus contains the infrastructure status of the resources to be adopted on restore.
+	// +optional
+	ProviderStatus *InfrastructureStatus `json:"providerStatus,omitempty"`
 }
 
 // Networks holds information about the Kubernetes and infrastructure networks.
 type InfrastructureConfigNetworkIDs struct {
 	// Workers is the HCloud network ID created.
 	Workers string `json:"workers"`
+	// WorkersName is the HCloud network name used for the worker nodes.
+	// +optional
+	WorkersName string `json:"workersName,omitempty"`
+	// WorkersExisting is true if the HCloud network has not been created by the extension.
+	// +optional
+	WorkersExisting bool `json:"workersExisting,omitempty"`
+	// WorkersShared is true if the HCloud network is shared with other shoots.
+	// +optional
+	WorkersShared bool `json:"workersShared,omitempty"`
+	// WorkersSubnet contains the CIDR of the subnet added by the extension to an existing HCloud network.
+	// +optional
+	WorkersSubnet string `json:"workersSubnet,omitempty"`
+	// WorkersSubnets contains the IP ranges covered by the cloud subnets of the workers network by HCloud network zone.
+	// +optional
+	WorkersSubnets map[string]string `json:"workersSubnets,omitempty"`
+	// VSwitch is the Hetzner Robot vSwitch ID connected to the workers network.
+	// +optional
+	VSwitch string `json:"vSwitch,omitempty"`
+	// VSwitchSubnet contains the CIDR of the vSwitch subnet of the workers network.
+	// +optional
+	VSwitchSubnet string `json:"vSwitchSubnet,omitempty"`
 }
@@ -35,6 +35,16 @@ type WorkerStatus struct {
 	// +optional
 	MachineImages     []MachineImage   `json:"machineImages,omitempty"`
 	PlacementGroupIDs map[string]int64 `json:"placementGroupIds,omitempty"`
+	// ReservedPrimaryIPs contains the IDs of the primary IPs reserved for each machine deployment.
+	ReservedPrimaryIPs map[string]WorkerStatusReservedPrimaryIPs `json:"reservedPrimaryIPs,omitempty"`
+}
+
+// WorkerStatusReservedPrimaryIPs contains the IDs of the primary IPs reserved for a machine deployment.
+type WorkerStatusReservedPrimaryIPs struct {
+	// IPv4 contains the IDs of the reserved IPv4 primary IPs.
+	IPv4 []int64 `json:"ipv4,omitempty"`
+	// IPv6 contains the IDs of the reserved IPv6 primary IPs.
+	IPv6 []int64 `json:"ipv6,omitempty"`
 }
 
 // MachineImage is a mapping from logical names and versions to provider-specific machine image data.
@@ -54,4 +64,21 @@ type WorkerConfig struct {
 	// type of the placementgroup for current worker pool. Note that hetzner currently only supports type "spread"
 	// moreover a placementgroup cannot hold more than 10 machines on hetzner
 	PlacementGroupType string `json:"placementGroupType"`
+	// ReservedPrimaryIPs is the configuration of the primary IPs reserved for the servers of the worker pool.
+	ReservedPrimaryIPs *WorkerConfigReservedPrimaryIPs `json:"reservedPrimaryIPs,omitempty"`
+	// Labels are added to the HCloud resources of the worker pool, overriding the labels of the InfrastructureConfig.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// WorkerConfigReservedPrimaryIPs holds information about the primary IPs reserved for the servers of a worker pool.
+// Reserved primary IPs are kept across machine rolls and only deleted with the worker pool.
+type WorkerConfigReservedPrimaryIPs struct {
+	// IPv4 reserves IPv4 primary IPs.
+	IPv4 bool `json:"ipv4,omitempty"`
+	// IPv6 reserves IPv6 primary IPs.
+	IPv6 bool `json:"ipv6,omitempty"`
+	// Count is the number of primary IPs reserved per zone and IP family. Defaults to the share of the zone in the
+	// pool maximum plus the maximum surge.
+	Count *int32 `json:"count,omitempty"`
 }
@@ -22,18 +22,199 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"net"
+	"slices"
 	"strings"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 )
 
-// GetRegionFromZone returns the region for a given zone string
+// NetworkRouteLimit is the maximum number of routes of an HCloud network.
+const NetworkRouteLimit = 100
+
+// privateIPv4Cidrs are the RFC 1918 ranges HCloud networks and their subnets must be part of.
+var privateIPv4Cidrs = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+// networkZoneLocations maps the HCloud network zones to the locations they contain.
+var networkZoneLocations = map[hcloud.NetworkZone][]string{
+	hcloud.NetworkZoneEUCentral:   {"fsn1", "hel1", "nbg1"},
+	hcloud.NetworkZoneUSEast:      {"ash"},
+	hcloud.NetworkZoneUSWest:      {"hil"},
+	hcloud.NetworkZoneAPSouthEast: {"sin"},
+}
+
+// GetLocationFromZone returns the HCloud location for a given zone string. Zones are either locations (e.g. "fsn1") or datacenters (e.g. "fsn1-dc14").
 //
 // PARAMETERS
 // zone string Zone
-func GetRegionFromZone(zone string) string {
+func GetLocationFromZone(zone string) string {
 	zoneData := strings.SplitN(zone, "-", 2)
 	return zoneData[0]
 }
 
+// IsNetworkZoneRegion returns true if the region given is an HCloud network zone spanning multiple locations instead of a single location.
+//
+// PARAMETERS
+// region string Region
+func IsNetworkZoneRegion(region string) bool {
+	_, ok := networkZoneLocations[hcloud.NetworkZone(region)]
+	return ok
+}
+
+// GetNetworkZoneFromLocation returns the HCloud network zone the location given is part of. An empty string is returned for unknown locations.
+//
+// PARAMETERS
+// location string Location
+func GetNetworkZoneFromLocation(location string) hcloud.NetworkZone {
+	for networkZone, locations := range networkZoneLocations {
+		if slices.Contains(locations, location) {
+			return networkZone
+		}
+	}
+
+	return ""
+}
+
+// GetLocation returns the HCloud location for the region and zone given. The region is only used if it is a location itself and no zone is given.
+//
+// PARAMETERS
+// region string Region
+// zone   string Zone
+func GetLocation(region, zone string) string {
+	if "" != zone {
+		return GetLocationFromZone(zone)
+	}
+
+	if IsNetworkZoneRegion(region) {
+		return ""
+	}
+
+	return region
+}
+
+// GetNetworkZone returns the HCloud network zone for the region and zone given.
+//
+// PARAMETERS
+// region string Region
+// zone   string Zone
+func GetNetworkZone(region, zone string) hcloud.NetworkZone {
+	if IsNetworkZoneRegion(region) {
+		return hcloud.NetworkZone(region)
+	}
+
+	return GetNetworkZoneFromLocation(GetLocation(region, zone))
+}
+
+// IsSSHAccessEnabled returns true if SSH access to the worker nodes of the shoot given is not disabled.
+//
+// PARAMETERS
+// shoot *gardencorev1beta1.Shoot Shoot struct
+func IsSSHAccessEnabled(shoot *gardencorev1beta1.Shoot) bool {
+	if nil == shoot || nil == shoot.Spec.Provider.WorkersSettings || nil == shoot.Spec.Provider.WorkersSettings.SSHAccess {
+		return true
+	}
+
+	return shoot.Spec.Provider.WorkersSettings.SSHAccess.Enabled
+}
+
+// GetIPFamilies returns the IP families of the shoot given. IPv4 is returned if no IP family is configured.
+//
+// PARAMETERS
+// shoot *gardencorev1beta1.Shoot Shoot struct
+func GetIPFamilies(shoot *gardencorev1beta1.Shoot) []gardencorev1beta1.IPFamily {
+	if nil == shoot || nil == shoot.Spec.Networking || 0 == len(shoot.Spec.Networking.IPFamilies) {
+		return []gardencorev1beta1.IPFamily{gardencorev1beta1.IPFamilyIPv4}
+	}
+
+	return shoot.Spec.Networking.IPFamilies
+}
+
+// IsIPFamilyEnabled returns true if the IP family given is used by the shoot given.
+//
+// PARAMETERS
+// shoot    *gardencorev1beta1.Shoot   Shoot struct
+// ipFamily gardencorev1beta1.IPFamily IP family
+func IsIPFamilyEnabled(shoot *gardencorev1beta1.Shoot, ipFamily gardencorev1beta1.IPFamily) bool {
+	return slices.Contains(GetIPFamilies(shoot), ipFamily)
+}
+
+// IsNetworkRoutingEnabled returns true if the cloud-controller-manager creates an HCloud network route for the pod
+// CIDR of each node.
+//
+// PARAMETERS
+// cpConfig *ControlPlaneConfig Control plane config
+func IsNetworkRoutingEnabled(cpConfig *ControlPlaneConfig) bool {
+	if nil == cpConfig || nil == cpConfig.CloudControllerManager || nil == cpConfig.CloudControllerManager.NativeRouting {
+		return true
+	}
+
+	return !*cpConfig.CloudControllerManager.NativeRouting
+}
+
+// IsPrivateIPRange returns true if the IP range given is fully contained in one of the RFC 1918 IPv4 ranges.
+//
+// PARAMETERS
+// ipRange *net.IPNet IP range to check
+func IsPrivateIPRange(ipRange *net.IPNet) bool {
+	ones, bits := ipRange.Mask.Size()
+
+	for _, cidr := range privateIPv4Cidrs {
+		_, privateRange, _ := net.ParseCIDR(cidr)
+		privateOnes, privateBits := privateRange.Mask.Size()
+
+		if bits == privateBits && ones >= privateOnes && privateRange.Contains(ipRange.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetNetworkIPRange returns the IP range of a workers network created for the workers CIDR given. HCloud requires
+// all subnets to be part of the network IP range, so it is enlarged to the smallest range also containing the
+// vSwitch CIDR if given.
+//
+// PARAMETERS
+// workersRange *net.IPNet Workers CIDR
+// vSwitchCidr  string     VSwitch CIDR
+func GetNetworkIPRange(workersRange *net.IPNet, vSwitchCidr string) (*net.IPNet, error) {
+	if "" == vSwitchCidr {
+		return workersRange, nil
+	}
+
+	_, vSwitchRange, err := net.ParseCIDR(vSwitchCidr)
+	if nil != err {
+		return nil, err
+	}
+
+	ones, bits := workersRange.Mask.Size()
+	vSwitchOnes, vSwitchBits := vSwitchRange.Mask.Size()
+
+	if bits != vSwitchBits {
+		return nil, fmt.Errorf("VSwitch CIDR %q and workers CIDR %q are of different IP families", vSwitchRange.String(), workersRange.String())
+	}
+
+	ones = min(ones, vSwitchOnes)
+
+	for ; ones > 0; ones-- {
+		mask := net.CIDRMask(ones, bits)
+
+		if workersRange.IP.Mask(mask).Equal(vSwitchRange.IP.Mask(mask)) {
+			break
+		}
+	}
+
+	ipRange := &net.IPNet{IP: workersRange.IP.Mask(net.CIDRMask(ones, bits)), Mask: net.CIDRMask(ones, bits)}
+
+	if !IsPrivateIPRange(ipRange) {
+		return nil, fmt.Errorf("VSwitch CIDR %q and workers CIDR %q are not part of the same private IP range", vSwitchRange.String(), workersRange.String())
+	}
+
+	return ipRange, nil
+}
+
 // GetSSHFingerprint returns the calculated fingerprint for an SSH public key.
 //
 // PARAMETERS
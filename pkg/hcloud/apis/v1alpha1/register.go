@@ -60,6 +60,7 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&CloudProfileConfig{},
 		&InfrastructureConfig{},
 		&InfrastructureStatus{},
+		&InfrastructureState{},
 		&ControlPlaneConfig{},
 		&WorkerStatus{},
 		&WorkerConfig{},
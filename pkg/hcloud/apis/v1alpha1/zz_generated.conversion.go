@@ -85,6 +85,56 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigExistingNetwork)(nil), (*apis.InfrastructureConfigExistingNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigExistingNetwork_To_apis_InfrastructureConfigExistingNetwork(a.(*InfrastructureConfigExistingNetwork), b.(*apis.InfrastructureConfigExistingNetwork), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigExistingNetwork)(nil), (*InfrastructureConfigExistingNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigExistingNetwork_To_v1alpha1_InfrastructureConfigExistingNetwork(a.(*apis.InfrastructureConfigExistingNetwork), b.(*InfrastructureConfigExistingNetwork), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigFirewall)(nil), (*apis.InfrastructureConfigFirewall)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigFirewall_To_apis_InfrastructureConfigFirewall(a.(*InfrastructureConfigFirewall), b.(*apis.InfrastructureConfigFirewall), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigFirewall)(nil), (*InfrastructureConfigFirewall)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigFirewall_To_v1alpha1_InfrastructureConfigFirewall(a.(*apis.InfrastructureConfigFirewall), b.(*InfrastructureConfigFirewall), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigFirewallRule)(nil), (*apis.InfrastructureConfigFirewallRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigFirewallRule_To_apis_InfrastructureConfigFirewallRule(a.(*InfrastructureConfigFirewallRule), b.(*apis.InfrastructureConfigFirewallRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigFirewallRule)(nil), (*InfrastructureConfigFirewallRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigFirewallRule_To_v1alpha1_InfrastructureConfigFirewallRule(a.(*apis.InfrastructureConfigFirewallRule), b.(*InfrastructureConfigFirewallRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigFloatingIPs)(nil), (*apis.InfrastructureConfigFloatingIPs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigFloatingIPs_To_apis_InfrastructureConfigFloatingIPs(a.(*InfrastructureConfigFloatingIPs), b.(*apis.InfrastructureConfigFloatingIPs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigFloatingIPs)(nil), (*InfrastructureConfigFloatingIPs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigFloatingIPs_To_v1alpha1_InfrastructureConfigFloatingIPs(a.(*apis.InfrastructureConfigFloatingIPs), b.(*InfrastructureConfigFloatingIPs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigNATGateway)(nil), (*apis.InfrastructureConfigNATGateway)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigNATGateway_To_apis_InfrastructureConfigNATGateway(a.(*InfrastructureConfigNATGateway), b.(*apis.InfrastructureConfigNATGateway), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigNATGateway)(nil), (*InfrastructureConfigNATGateway)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigNATGateway_To_v1alpha1_InfrastructureConfigNATGateway(a.(*apis.InfrastructureConfigNATGateway), b.(*InfrastructureConfigNATGateway), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigNetwork)(nil), (*apis.InfrastructureConfigNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_InfrastructureConfigNetwork_To_apis_InfrastructureConfigNetwork(a.(*InfrastructureConfigNetwork), b.(*apis.InfrastructureConfigNetwork), scope)
 	}); err != nil {
@@ -115,6 +165,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigSharedNetwork)(nil), (*apis.InfrastructureConfigSharedNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigSharedNetwork_To_apis_InfrastructureConfigSharedNetwork(a.(*InfrastructureConfigSharedNetwork), b.(*apis.InfrastructureConfigSharedNetwork), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigSharedNetwork)(nil), (*InfrastructureConfigSharedNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigSharedNetwork_To_v1alpha1_InfrastructureConfigSharedNetwork(a.(*apis.InfrastructureConfigSharedNetwork), b.(*InfrastructureConfigSharedNetwork), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureConfigVSwitch)(nil), (*apis.InfrastructureConfigVSwitch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureConfigVSwitch_To_apis_InfrastructureConfigVSwitch(a.(*InfrastructureConfigVSwitch), b.(*apis.InfrastructureConfigVSwitch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureConfigVSwitch)(nil), (*InfrastructureConfigVSwitch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureConfigVSwitch_To_v1alpha1_InfrastructureConfigVSwitch(a.(*apis.InfrastructureConfigVSwitch), b.(*InfrastructureConfigVSwitch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureState)(nil), (*apis.InfrastructureState)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureState_To_apis_InfrastructureState(a.(*InfrastructureState), b.(*apis.InfrastructureState), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureState)(nil), (*InfrastructureState)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureState_To_v1alpha1_InfrastructureState(a.(*apis.InfrastructureState), b.(*InfrastructureState), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*InfrastructureStatus)(nil), (*apis.InfrastructureStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_InfrastructureStatus_To_apis_InfrastructureStatus(a.(*InfrastructureStatus), b.(*apis.InfrastructureStatus), scope)
 	}); err != nil {
@@ -125,6 +205,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*InfrastructureStatusNetworkRoutes)(nil), (*apis.InfrastructureStatusNetworkRoutes)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InfrastructureStatusNetworkRoutes_To_apis_InfrastructureStatusNetworkRoutes(a.(*InfrastructureStatusNetworkRoutes), b.(*apis.InfrastructureStatusNetworkRoutes), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.InfrastructureStatusNetworkRoutes)(nil), (*InfrastructureStatusNetworkRoutes)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_InfrastructureStatusNetworkRoutes_To_v1alpha1_InfrastructureStatusNetworkRoutes(a.(*apis.InfrastructureStatusNetworkRoutes), b.(*InfrastructureStatusNetworkRoutes), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*MachineImage)(nil), (*apis.MachineImage)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_MachineImage_To_apis_MachineImage(a.(*MachineImage), b.(*apis.MachineImage), scope)
 	}); err != nil {
@@ -185,6 +275,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*WorkerConfigReservedPrimaryIPs)(nil), (*apis.WorkerConfigReservedPrimaryIPs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkerConfigReservedPrimaryIPs_To_apis_WorkerConfigReservedPrimaryIPs(a.(*WorkerConfigReservedPrimaryIPs), b.(*apis.WorkerConfigReservedPrimaryIPs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.WorkerConfigReservedPrimaryIPs)(nil), (*WorkerConfigReservedPrimaryIPs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_WorkerConfigReservedPrimaryIPs_To_v1alpha1_WorkerConfigReservedPrimaryIPs(a.(*apis.WorkerConfigReservedPrimaryIPs), b.(*WorkerConfigReservedPrimaryIPs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*WorkerStatus)(nil), (*apis.WorkerStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_WorkerStatus_To_apis_WorkerStatus(a.(*WorkerStatus), b.(*apis.WorkerStatus), scope)
 	}); err != nil {
@@ -195,6 +295,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*WorkerStatusReservedPrimaryIPs)(nil), (*apis.WorkerStatusReservedPrimaryIPs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkerStatusReservedPrimaryIPs_To_apis_WorkerStatusReservedPrimaryIPs(a.(*WorkerStatusReservedPrimaryIPs), b.(*apis.WorkerStatusReservedPrimaryIPs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*apis.WorkerStatusReservedPrimaryIPs)(nil), (*WorkerStatusReservedPrimaryIPs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_apis_WorkerStatusReservedPrimaryIPs_To_v1alpha1_WorkerStatusReservedPrimaryIPs(a.(*apis.WorkerStatusReservedPrimaryIPs), b.(*WorkerStatusReservedPrimaryIPs), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -226,6 +336,7 @@ func Convert_apis_CPLoadBalancerClass_To_v1alpha1_CPLoadBalancerClass(in *apis.C
 
 func autoConvert_v1alpha1_CloudControllerManagerConfig_To_apis_CloudControllerManagerConfig(in *CloudControllerManagerConfig, out *apis.CloudControllerManagerConfig, s conversion.Scope) error {
 	out.FeatureGates = *(*map[string]bool)(unsafe.Pointer(&in.FeatureGates))
+	out.NativeRouting = (*bool)(unsafe.Pointer(in.NativeRouting))
 	return nil
 }
 
@@ -236,6 +347,7 @@ func Convert_v1alpha1_CloudControllerManagerConfig_To_apis_CloudControllerManage
 
 func autoConvert_apis_CloudControllerManagerConfig_To_v1alpha1_CloudControllerManagerConfig(in *apis.CloudControllerManagerConfig, out *CloudControllerManagerConfig, s conversion.Scope) error {
 	out.FeatureGates = *(*map[string]bool)(unsafe.Pointer(&in.FeatureGates))
+	out.NativeRouting = (*bool)(unsafe.Pointer(in.NativeRouting))
 	return nil
 }
 
@@ -323,6 +435,10 @@ func Convert_apis_DockerDaemonOptions_To_v1alpha1_DockerDaemonOptions(in *apis.D
 func autoConvert_v1alpha1_InfrastructureConfig_To_apis_InfrastructureConfig(in *InfrastructureConfig, out *apis.InfrastructureConfig, s conversion.Scope) error {
 	out.FloatingPoolName = in.FloatingPoolName
 	out.Networks = (*apis.InfrastructureConfigNetworks)(unsafe.Pointer(in.Networks))
+	out.Firewall = (*apis.InfrastructureConfigFirewall)(unsafe.Pointer(in.Firewall))
+	out.NATGateway = (*apis.InfrastructureConfigNATGateway)(unsafe.Pointer(in.NATGateway))
+	out.FloatingIPs = (*apis.InfrastructureConfigFloatingIPs)(unsafe.Pointer(in.FloatingIPs))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
 	return nil
 }
 
@@ -334,6 +450,10 @@ func Convert_v1alpha1_InfrastructureConfig_To_apis_InfrastructureConfig(in *Infr
 func autoConvert_apis_InfrastructureConfig_To_v1alpha1_InfrastructureConfig(in *apis.InfrastructureConfig, out *InfrastructureConfig, s conversion.Scope) error {
 	out.FloatingPoolName = in.FloatingPoolName
 	out.Networks = (*InfrastructureConfigNetworks)(unsafe.Pointer(in.Networks))
+	out.Firewall = (*InfrastructureConfigFirewall)(unsafe.Pointer(in.Firewall))
+	out.NATGateway = (*InfrastructureConfigNATGateway)(unsafe.Pointer(in.NATGateway))
+	out.FloatingIPs = (*InfrastructureConfigFloatingIPs)(unsafe.Pointer(in.FloatingIPs))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
 	return nil
 }
 
@@ -342,6 +462,124 @@ func Convert_apis_InfrastructureConfig_To_v1alpha1_InfrastructureConfig(in *apis
 	return autoConvert_apis_InfrastructureConfig_To_v1alpha1_InfrastructureConfig(in, out, s)
 }
 
+func autoConvert_v1alpha1_InfrastructureConfigExistingNetwork_To_apis_InfrastructureConfigExistingNetwork(in *InfrastructureConfigExistingNetwork, out *apis.InfrastructureConfigExistingNetwork, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigExistingNetwork_To_apis_InfrastructureConfigExistingNetwork is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigExistingNetwork_To_apis_InfrastructureConfigExistingNetwork(in *InfrastructureConfigExistingNetwork, out *apis.InfrastructureConfigExistingNetwork, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigExistingNetwork_To_apis_InfrastructureConfigExistingNetwork(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigExistingNetwork_To_v1alpha1_InfrastructureConfigExistingNetwork(in *apis.InfrastructureConfigExistingNetwork, out *InfrastructureConfigExistingNetwork, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Name = in.Name
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigExistingNetwork_To_v1alpha1_InfrastructureConfigExistingNetwork is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigExistingNetwork_To_v1alpha1_InfrastructureConfigExistingNetwork(in *apis.InfrastructureConfigExistingNetwork, out *InfrastructureConfigExistingNetwork, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigExistingNetwork_To_v1alpha1_InfrastructureConfigExistingNetwork(in, out, s)
+}
+
+func autoConvert_v1alpha1_InfrastructureConfigFirewall_To_apis_InfrastructureConfigFirewall(in *InfrastructureConfigFirewall, out *apis.InfrastructureConfigFirewall, s conversion.Scope) error {
+	out.NodePortRange = in.NodePortRange
+	out.Rules = *(*[]apis.InfrastructureConfigFirewallRule)(unsafe.Pointer(&in.Rules))
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigFirewall_To_apis_InfrastructureConfigFirewall is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigFirewall_To_apis_InfrastructureConfigFirewall(in *InfrastructureConfigFirewall, out *apis.InfrastructureConfigFirewall, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigFirewall_To_apis_InfrastructureConfigFirewall(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigFirewall_To_v1alpha1_InfrastructureConfigFirewall(in *apis.InfrastructureConfigFirewall, out *InfrastructureConfigFirewall, s conversion.Scope) error {
+	out.NodePortRange = in.NodePortRange
+	out.Rules = *(*[]InfrastructureConfigFirewallRule)(unsafe.Pointer(&in.Rules))
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigFirewall_To_v1alpha1_InfrastructureConfigFirewall is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigFirewall_To_v1alpha1_InfrastructureConfigFirewall(in *apis.InfrastructureConfigFirewall, out *InfrastructureConfigFirewall, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigFirewall_To_v1alpha1_InfrastructureConfigFirewall(in, out, s)
+}
+
+func autoConvert_v1alpha1_InfrastructureConfigFirewallRule_To_apis_InfrastructureConfigFirewallRule(in *InfrastructureConfigFirewallRule, out *apis.InfrastructureConfigFirewallRule, s conversion.Scope) error {
+	out.Direction = hcloud.FirewallRuleDirection(in.Direction)
+	out.Protocol = hcloud.FirewallRuleProtocol(in.Protocol)
+	out.Port = in.Port
+	out.SourceIPs = *(*[]string)(unsafe.Pointer(&in.SourceIPs))
+	out.DestinationIPs = *(*[]string)(unsafe.Pointer(&in.DestinationIPs))
+	out.Description = in.Description
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigFirewallRule_To_apis_InfrastructureConfigFirewallRule is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigFirewallRule_To_apis_InfrastructureConfigFirewallRule(in *InfrastructureConfigFirewallRule, out *apis.InfrastructureConfigFirewallRule, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigFirewallRule_To_apis_InfrastructureConfigFirewallRule(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigFirewallRule_To_v1alpha1_InfrastructureConfigFirewallRule(in *apis.InfrastructureConfigFirewallRule, out *InfrastructureConfigFirewallRule, s conversion.Scope) error {
+	out.Direction = hcloud.FirewallRuleDirection(in.Direction)
+	out.Protocol = hcloud.FirewallRuleProtocol(in.Protocol)
+	out.Port = in.Port
+	out.SourceIPs = *(*[]string)(unsafe.Pointer(&in.SourceIPs))
+	out.DestinationIPs = *(*[]string)(unsafe.Pointer(&in.DestinationIPs))
+	out.Description = in.Description
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigFirewallRule_To_v1alpha1_InfrastructureConfigFirewallRule is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigFirewallRule_To_v1alpha1_InfrastructureConfigFirewallRule(in *apis.InfrastructureConfigFirewallRule, out *InfrastructureConfigFirewallRule, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigFirewallRule_To_v1alpha1_InfrastructureConfigFirewallRule(in, out, s)
+}
+
+func autoConvert_v1alpha1_InfrastructureConfigFloatingIPs_To_apis_InfrastructureConfigFloatingIPs(in *InfrastructureConfigFloatingIPs, out *apis.InfrastructureConfigFloatingIPs, s conversion.Scope) error {
+	out.Count = in.Count
+	out.IPFamily = hcloud.FloatingIPType(in.IPFamily)
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigFloatingIPs_To_apis_InfrastructureConfigFloatingIPs is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigFloatingIPs_To_apis_InfrastructureConfigFloatingIPs(in *InfrastructureConfigFloatingIPs, out *apis.InfrastructureConfigFloatingIPs, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigFloatingIPs_To_apis_InfrastructureConfigFloatingIPs(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigFloatingIPs_To_v1alpha1_InfrastructureConfigFloatingIPs(in *apis.InfrastructureConfigFloatingIPs, out *InfrastructureConfigFloatingIPs, s conversion.Scope) error {
+	out.Count = in.Count
+	out.IPFamily = hcloud.FloatingIPType(in.IPFamily)
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigFloatingIPs_To_v1alpha1_InfrastructureConfigFloatingIPs is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigFloatingIPs_To_v1alpha1_InfrastructureConfigFloatingIPs(in *apis.InfrastructureConfigFloatingIPs, out *InfrastructureConfigFloatingIPs, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigFloatingIPs_To_v1alpha1_InfrastructureConfigFloatingIPs(in, out, s)
+}
+
+func autoConvert_v1alpha1_InfrastructureConfigNATGateway_To_apis_InfrastructureConfigNATGateway(in *InfrastructureConfigNATGateway, out *apis.InfrastructureConfigNATGateway, s conversion.Scope) error {
+	out.ServerType = in.ServerType
+	out.ImageName = in.ImageName
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigNATGateway_To_apis_InfrastructureConfigNATGateway is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigNATGateway_To_apis_InfrastructureConfigNATGateway(in *InfrastructureConfigNATGateway, out *apis.InfrastructureConfigNATGateway, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigNATGateway_To_apis_InfrastructureConfigNATGateway(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigNATGateway_To_v1alpha1_InfrastructureConfigNATGateway(in *apis.InfrastructureConfigNATGateway, out *InfrastructureConfigNATGateway, s conversion.Scope) error {
+	out.ServerType = in.ServerType
+	out.ImageName = in.ImageName
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigNATGateway_To_v1alpha1_InfrastructureConfigNATGateway is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigNATGateway_To_v1alpha1_InfrastructureConfigNATGateway(in *apis.InfrastructureConfigNATGateway, out *InfrastructureConfigNATGateway, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigNATGateway_To_v1alpha1_InfrastructureConfigNATGateway(in, out, s)
+}
+
 func autoConvert_v1alpha1_InfrastructureConfigNetwork_To_apis_InfrastructureConfigNetwork(in *InfrastructureConfigNetwork, out *apis.InfrastructureConfigNetwork, s conversion.Scope) error {
 	out.Cidr = in.Cidr
 	out.Zone = hcloud.NetworkZone(in.Zone)
@@ -366,6 +604,13 @@ func Convert_apis_InfrastructureConfigNetwork_To_v1alpha1_InfrastructureConfigNe
 
 func autoConvert_v1alpha1_InfrastructureConfigNetworkIDs_To_apis_InfrastructureConfigNetworkIDs(in *InfrastructureConfigNetworkIDs, out *apis.InfrastructureConfigNetworkIDs, s conversion.Scope) error {
 	out.Workers = in.Workers
+	out.WorkersName = in.WorkersName
+	out.WorkersExisting = in.WorkersExisting
+	out.WorkersShared = in.WorkersShared
+	out.WorkersSubnet = in.WorkersSubnet
+	out.WorkersSubnets = *(*map[string]string)(unsafe.Pointer(&in.WorkersSubnets))
+	out.VSwitch = in.VSwitch
+	out.VSwitchSubnet = in.VSwitchSubnet
 	return nil
 }
 
@@ -376,6 +621,13 @@ func Convert_v1alpha1_InfrastructureConfigNetworkIDs_To_apis_InfrastructureConfi
 
 func autoConvert_apis_InfrastructureConfigNetworkIDs_To_v1alpha1_InfrastructureConfigNetworkIDs(in *apis.InfrastructureConfigNetworkIDs, out *InfrastructureConfigNetworkIDs, s conversion.Scope) error {
 	out.Workers = in.Workers
+	out.WorkersName = in.WorkersName
+	out.WorkersExisting = in.WorkersExisting
+	out.WorkersShared = in.WorkersShared
+	out.WorkersSubnet = in.WorkersSubnet
+	out.WorkersSubnets = *(*map[string]string)(unsafe.Pointer(&in.WorkersSubnets))
+	out.VSwitch = in.VSwitch
+	out.VSwitchSubnet = in.VSwitchSubnet
 	return nil
 }
 
@@ -387,6 +639,9 @@ func Convert_apis_InfrastructureConfigNetworkIDs_To_v1alpha1_InfrastructureConfi
 func autoConvert_v1alpha1_InfrastructureConfigNetworks_To_apis_InfrastructureConfigNetworks(in *InfrastructureConfigNetworks, out *apis.InfrastructureConfigNetworks, s conversion.Scope) error {
 	out.WorkersConfiguration = (*apis.InfrastructureConfigNetwork)(unsafe.Pointer(in.WorkersConfiguration))
 	out.Workers = in.Workers
+	out.Existing = (*apis.InfrastructureConfigExistingNetwork)(unsafe.Pointer(in.Existing))
+	out.Shared = (*apis.InfrastructureConfigSharedNetwork)(unsafe.Pointer(in.Shared))
+	out.VSwitch = (*apis.InfrastructureConfigVSwitch)(unsafe.Pointer(in.VSwitch))
 	return nil
 }
 
@@ -398,6 +653,9 @@ func Convert_v1alpha1_InfrastructureConfigNetworks_To_apis_InfrastructureConfigN
 func autoConvert_apis_InfrastructureConfigNetworks_To_v1alpha1_InfrastructureConfigNetworks(in *apis.InfrastructureConfigNetworks, out *InfrastructureConfigNetworks, s conversion.Scope) error {
 	out.WorkersConfiguration = (*InfrastructureConfigNetwork)(unsafe.Pointer(in.WorkersConfiguration))
 	out.Workers = in.Workers
+	out.Existing = (*InfrastructureConfigExistingNetwork)(unsafe.Pointer(in.Existing))
+	out.Shared = (*InfrastructureConfigSharedNetwork)(unsafe.Pointer(in.Shared))
+	out.VSwitch = (*InfrastructureConfigVSwitch)(unsafe.Pointer(in.VSwitch))
 	return nil
 }
 
@@ -406,12 +664,82 @@ func Convert_apis_InfrastructureConfigNetworks_To_v1alpha1_InfrastructureConfigN
 	return autoConvert_apis_InfrastructureConfigNetworks_To_v1alpha1_InfrastructureConfigNetworks(in, out, s)
 }
 
+func autoConvert_v1alpha1_InfrastructureConfigSharedNetwork_To_apis_InfrastructureConfigSharedNetwork(in *InfrastructureConfigSharedNetwork, out *apis.InfrastructureConfigSharedNetwork, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Cidr = in.Cidr
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigSharedNetwork_To_apis_InfrastructureConfigSharedNetwork is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigSharedNetwork_To_apis_InfrastructureConfigSharedNetwork(in *InfrastructureConfigSharedNetwork, out *apis.InfrastructureConfigSharedNetwork, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigSharedNetwork_To_apis_InfrastructureConfigSharedNetwork(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigSharedNetwork_To_v1alpha1_InfrastructureConfigSharedNetwork(in *apis.InfrastructureConfigSharedNetwork, out *InfrastructureConfigSharedNetwork, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Cidr = in.Cidr
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigSharedNetwork_To_v1alpha1_InfrastructureConfigSharedNetwork is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigSharedNetwork_To_v1alpha1_InfrastructureConfigSharedNetwork(in *apis.InfrastructureConfigSharedNetwork, out *InfrastructureConfigSharedNetwork, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigSharedNetwork_To_v1alpha1_InfrastructureConfigSharedNetwork(in, out, s)
+}
+
+func autoConvert_v1alpha1_InfrastructureConfigVSwitch_To_apis_InfrastructureConfigVSwitch(in *InfrastructureConfigVSwitch, out *apis.InfrastructureConfigVSwitch, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Cidr = in.Cidr
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureConfigVSwitch_To_apis_InfrastructureConfigVSwitch is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureConfigVSwitch_To_apis_InfrastructureConfigVSwitch(in *InfrastructureConfigVSwitch, out *apis.InfrastructureConfigVSwitch, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureConfigVSwitch_To_apis_InfrastructureConfigVSwitch(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureConfigVSwitch_To_v1alpha1_InfrastructureConfigVSwitch(in *apis.InfrastructureConfigVSwitch, out *InfrastructureConfigVSwitch, s conversion.Scope) error {
+	out.ID = in.ID
+	out.Cidr = in.Cidr
+	return nil
+}
+
+// Convert_apis_InfrastructureConfigVSwitch_To_v1alpha1_InfrastructureConfigVSwitch is an autogenerated conversion function.
+func Convert_apis_InfrastructureConfigVSwitch_To_v1alpha1_InfrastructureConfigVSwitch(in *apis.InfrastructureConfigVSwitch, out *InfrastructureConfigVSwitch, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureConfigVSwitch_To_v1alpha1_InfrastructureConfigVSwitch(in, out, s)
+}
+
+func autoConvert_v1alpha1_InfrastructureState_To_apis_InfrastructureState(in *InfrastructureState, out *apis.InfrastructureState, s conversion.Scope) error {
+	out.ProviderStatus = (*apis.InfrastructureStatus)(unsafe.Pointer(in.ProviderStatus))
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureState_To_apis_InfrastructureState is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureState_To_apis_InfrastructureState(in *InfrastructureState, out *apis.InfrastructureState, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureState_To_apis_InfrastructureState(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureState_To_v1alpha1_InfrastructureState(in *apis.InfrastructureState, out *InfrastructureState, s conversion.Scope) error {
+	out.ProviderStatus = (*InfrastructureStatus)(unsafe.Pointer(in.ProviderStatus))
+	return nil
+}
+
+// Convert_apis_InfrastructureState_To_v1alpha1_InfrastructureState is an autogenerated conversion function.
+func Convert_apis_InfrastructureState_To_v1alpha1_InfrastructureState(in *apis.InfrastructureState, out *InfrastructureState, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureState_To_v1alpha1_InfrastructureState(in, out, s)
+}
+
 func autoConvert_v1alpha1_InfrastructureStatus_To_apis_InfrastructureStatus(in *InfrastructureStatus, out *apis.InfrastructureStatus, s conversion.Scope) error {
 	out.SSHFingerprint = in.SSHFingerprint
+	out.PreviousSSHFingerprint = in.PreviousSSHFingerprint
 	out.PlacementGroupIDs = *(*map[string]string)(unsafe.Pointer(&in.PlacementGroupIDs))
 	out.PlacementGroupID = in.PlacementGroupID
 	out.FloatingPoolName = in.FloatingPoolName
 	out.NetworkIDs = (*apis.InfrastructureConfigNetworkIDs)(unsafe.Pointer(in.NetworkIDs))
+	out.FirewallID = in.FirewallID
+	out.NATGatewayID = in.NATGatewayID
+	out.NATGatewayIP = in.NATGatewayIP
+	out.FloatingIPIDs = *(*[]string)(unsafe.Pointer(&in.FloatingIPIDs))
+	out.NetworkRoutes = (*apis.InfrastructureStatusNetworkRoutes)(unsafe.Pointer(in.NetworkRoutes))
 	return nil
 }
 
@@ -422,10 +750,16 @@ func Convert_v1alpha1_InfrastructureStatus_To_apis_InfrastructureStatus(in *Infr
 
 func autoConvert_apis_InfrastructureStatus_To_v1alpha1_InfrastructureStatus(in *apis.InfrastructureStatus, out *InfrastructureStatus, s conversion.Scope) error {
 	out.SSHFingerprint = in.SSHFingerprint
+	out.PreviousSSHFingerprint = in.PreviousSSHFingerprint
 	out.PlacementGroupIDs = *(*map[string]string)(unsafe.Pointer(&in.PlacementGroupIDs))
 	out.PlacementGroupID = in.PlacementGroupID
 	out.FloatingPoolName = in.FloatingPoolName
 	out.NetworkIDs = (*InfrastructureConfigNetworkIDs)(unsafe.Pointer(in.NetworkIDs))
+	out.FirewallID = in.FirewallID
+	out.NATGatewayID = in.NATGatewayID
+	out.NATGatewayIP = in.NATGatewayIP
+	out.FloatingIPIDs = *(*[]string)(unsafe.Pointer(&in.FloatingIPIDs))
+	out.NetworkRoutes = (*InfrastructureStatusNetworkRoutes)(unsafe.Pointer(in.NetworkRoutes))
 	return nil
 }
 
@@ -434,6 +768,28 @@ func Convert_apis_InfrastructureStatus_To_v1alpha1_InfrastructureStatus(in *apis
 	return autoConvert_apis_InfrastructureStatus_To_v1alpha1_InfrastructureStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_InfrastructureStatusNetworkRoutes_To_apis_InfrastructureStatusNetworkRoutes(in *InfrastructureStatusNetworkRoutes, out *apis.InfrastructureStatusNetworkRoutes, s conversion.Scope) error {
+	out.Used = in.Used
+	out.Limit = in.Limit
+	return nil
+}
+
+// Convert_v1alpha1_InfrastructureStatusNetworkRoutes_To_apis_InfrastructureStatusNetworkRoutes is an autogenerated conversion function.
+func Convert_v1alpha1_InfrastructureStatusNetworkRoutes_To_apis_InfrastructureStatusNetworkRoutes(in *InfrastructureStatusNetworkRoutes, out *apis.InfrastructureStatusNetworkRoutes, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InfrastructureStatusNetworkRoutes_To_apis_InfrastructureStatusNetworkRoutes(in, out, s)
+}
+
+func autoConvert_apis_InfrastructureStatusNetworkRoutes_To_v1alpha1_InfrastructureStatusNetworkRoutes(in *apis.InfrastructureStatusNetworkRoutes, out *InfrastructureStatusNetworkRoutes, s conversion.Scope) error {
+	out.Used = in.Used
+	out.Limit = in.Limit
+	return nil
+}
+
+// Convert_apis_InfrastructureStatusNetworkRoutes_To_v1alpha1_InfrastructureStatusNetworkRoutes is an autogenerated conversion function.
+func Convert_apis_InfrastructureStatusNetworkRoutes_To_v1alpha1_InfrastructureStatusNetworkRoutes(in *apis.InfrastructureStatusNetworkRoutes, out *InfrastructureStatusNetworkRoutes, s conversion.Scope) error {
+	return autoConvert_apis_InfrastructureStatusNetworkRoutes_To_v1alpha1_InfrastructureStatusNetworkRoutes(in, out, s)
+}
+
 func autoConvert_v1alpha1_MachineImage_To_apis_MachineImage(in *MachineImage, out *apis.MachineImage, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Version = in.Version
@@ -546,6 +902,8 @@ func Convert_apis_RegionSpec_To_v1alpha1_RegionSpec(in *apis.RegionSpec, out *Re
 
 func autoConvert_v1alpha1_WorkerConfig_To_apis_WorkerConfig(in *WorkerConfig, out *apis.WorkerConfig, s conversion.Scope) error {
 	out.PlacementGroupType = in.PlacementGroupType
+	out.ReservedPrimaryIPs = (*apis.WorkerConfigReservedPrimaryIPs)(unsafe.Pointer(in.ReservedPrimaryIPs))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
 	return nil
 }
 
@@ -556,6 +914,8 @@ func Convert_v1alpha1_WorkerConfig_To_apis_WorkerConfig(in *WorkerConfig, out *a
 
 func autoConvert_apis_WorkerConfig_To_v1alpha1_WorkerConfig(in *apis.WorkerConfig, out *WorkerConfig, s conversion.Scope) error {
 	out.PlacementGroupType = in.PlacementGroupType
+	out.ReservedPrimaryIPs = (*WorkerConfigReservedPrimaryIPs)(unsafe.Pointer(in.ReservedPrimaryIPs))
+	out.Labels = *(*map[string]string)(unsafe.Pointer(&in.Labels))
 	return nil
 }
 
@@ -564,6 +924,30 @@ func Convert_apis_WorkerConfig_To_v1alpha1_WorkerConfig(in *apis.WorkerConfig, o
 	return autoConvert_apis_WorkerConfig_To_v1alpha1_WorkerConfig(in, out, s)
 }
 
+func autoConvert_v1alpha1_WorkerConfigReservedPrimaryIPs_To_apis_WorkerConfigReservedPrimaryIPs(in *WorkerConfigReservedPrimaryIPs, out *apis.WorkerConfigReservedPrimaryIPs, s conversion.Scope) error {
+	out.IPv4 = in.IPv4
+	out.IPv6 = in.IPv6
+	out.Count = (*int32)(unsafe.Pointer(in.Count))
+	return nil
+}
+
+// Convert_v1alpha1_WorkerConfigReservedPrimaryIPs_To_apis_WorkerConfigReservedPrimaryIPs is an autogenerated conversion function.
+func Convert_v1alpha1_WorkerConfigReservedPrimaryIPs_To_apis_WorkerConfigReservedPrimaryIPs(in *WorkerConfigReservedPrimaryIPs, out *apis.WorkerConfigReservedPrimaryIPs, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkerConfigReservedPrimaryIPs_To_apis_WorkerConfigReservedPrimaryIPs(in, out, s)
+}
+
+func autoConvert_apis_WorkerConfigReservedPrimaryIPs_To_v1alpha1_WorkerConfigReservedPrimaryIPs(in *apis.WorkerConfigReservedPrimaryIPs, out *WorkerConfigReservedPrimaryIPs, s conversion.Scope) error {
+	out.IPv4 = in.IPv4
+	out.IPv6 = in.IPv6
+	out.Count = (*int32)(unsafe.Pointer(in.Count))
+	return nil
+}
+
+// Convert_apis_WorkerConfigReservedPrimaryIPs_To_v1alpha1_WorkerConfigReservedPrimaryIPs is an autogenerated conversion function.
+func Convert_apis_WorkerConfigReservedPrimaryIPs_To_v1alpha1_WorkerConfigReservedPrimaryIPs(in *apis.WorkerConfigReservedPrimaryIPs, out *WorkerConfigReservedPrimaryIPs, s conversion.Scope) error {
+	return autoConvert_apis_WorkerConfigReservedPrimaryIPs_To_v1alpha1_WorkerConfigReservedPrimaryIPs(in, out, s)
+}
+
 func autoConvert_v1alpha1_WorkerStatus_To_apis_WorkerStatus(in *WorkerStatus, out *apis.WorkerStatus, s conversion.Scope) error {
 	out.MachineImages = *(*[]apis.MachineImage)(unsafe.Pointer(&in.MachineImages))
 	if in.PlacementGroupIDs != nil {
@@ -575,6 +959,7 @@ func autoConvert_v1alpha1_WorkerStatus_To_apis_WorkerStatus(in *WorkerStatus, ou
 	} else {
 		out.PlacementGroupIDs = nil
 	}
+	out.ReservedPrimaryIPs = *(*map[string]apis.WorkerStatusReservedPrimaryIPs)(unsafe.Pointer(&in.ReservedPrimaryIPs))
 	return nil
 }
 
@@ -594,6 +979,7 @@ func autoConvert_apis_WorkerStatus_To_v1alpha1_WorkerStatus(in *apis.WorkerStatu
 	} else {
 		out.PlacementGroupIDs = nil
 	}
+	out.ReservedPrimaryIPs = *(*map[string]WorkerStatusReservedPrimaryIPs)(unsafe.Pointer(&in.ReservedPrimaryIPs))
 	return nil
 }
 
@@ -601,3 +987,25 @@ func autoConvert_apis_WorkerStatus_To_v1alpha1_WorkerStatus(in *apis.WorkerStatu
 func Convert_apis_WorkerStatus_To_v1alpha1_WorkerStatus(in *apis.WorkerStatus, out *WorkerStatus, s conversion.Scope) error {
 	return autoConvert_apis_WorkerStatus_To_v1alpha1_WorkerStatus(in, out, s)
 }
+
+func autoConvert_v1alpha1_WorkerStatusReservedPrimaryIPs_To_apis_WorkerStatusReservedPrimaryIPs(in *WorkerStatusReservedPrimaryIPs, out *apis.WorkerStatusReservedPrimaryIPs, s conversion.Scope) error {
+	out.IPv4 = *(*[]int64)(unsafe.Pointer(&in.IPv4))
+	out.IPv6 = *(*[]int64)(unsafe.Pointer(&in.IPv6))
+	return nil
+}
+
+// Convert_v1alpha1_WorkerStatusReservedPrimaryIPs_To_apis_WorkerStatusReservedPrimaryIPs is an autogenerated conversion function.
+func Convert_v1alpha1_WorkerStatusReservedPrimaryIPs_To_apis_WorkerStatusReservedPrimaryIPs(in *WorkerStatusReservedPrimaryIPs, out *apis.WorkerStatusReservedPrimaryIPs, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkerStatusReservedPrimaryIPs_To_apis_WorkerStatusReservedPrimaryIPs(in, out, s)
+}
+
+func autoConvert_apis_WorkerStatusReservedPrimaryIPs_To_v1alpha1_WorkerStatusReservedPrimaryIPs(in *apis.WorkerStatusReservedPrimaryIPs, out *WorkerStatusReservedPrimaryIPs, s conversion.Scope) error {
+	out.IPv4 = *(*[]int64)(unsafe.Pointer(&in.IPv4))
+	out.IPv6 = *(*[]int64)(unsafe.Pointer(&in.IPv6))
+	return nil
+}
+
+// Convert_apis_WorkerStatusReservedPrimaryIPs_To_v1alpha1_WorkerStatusReservedPrimaryIPs is an autogenerated conversion function.
+func Convert_apis_WorkerStatusReservedPrimaryIPs_To_v1alpha1_WorkerStatusReservedPrimaryIPs(in *apis.WorkerStatusReservedPrimaryIPs, out *WorkerStatusReservedPrimaryIPs, s conversion.Scope) error {
+	return autoConvert_apis_WorkerStatusReservedPrimaryIPs_To_v1alpha1_WorkerStatusReservedPrimaryIPs(in, out, s)
+}
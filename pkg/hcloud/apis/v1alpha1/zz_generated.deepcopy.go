@@ -54,6 +54,11 @@ func (in *CloudControllerManagerConfig) DeepCopyInto(out *CloudControllerManager
 			(*out)[key] = val
 		}
 	}
+	if in.NativeRouting != nil {
+		in, out := &in.NativeRouting, &out.NativeRouting
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -195,6 +200,28 @@ func (in *InfrastructureConfig) DeepCopyInto(out *InfrastructureConfig) {
 		*out = new(InfrastructureConfigNetworks)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Firewall != nil {
+		in, out := &in.Firewall, &out.Firewall
+		*out = new(InfrastructureConfigFirewall)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NATGateway != nil {
+		in, out := &in.NATGateway, &out.NATGateway
+		*out = new(InfrastructureConfigNATGateway)
+		**out = **in
+	}
+	if in.FloatingIPs != nil {
+		in, out := &in.FloatingIPs, &out.FloatingIPs
+		*out = new(InfrastructureConfigFloatingIPs)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -216,6 +243,103 @@ func (in *InfrastructureConfig) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigExistingNetwork) DeepCopyInto(out *InfrastructureConfigExistingNetwork) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigExistingNetwork.
+func (in *InfrastructureConfigExistingNetwork) DeepCopy() *InfrastructureConfigExistingNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigExistingNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigFirewall) DeepCopyInto(out *InfrastructureConfigFirewall) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]InfrastructureConfigFirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigFirewall.
+func (in *InfrastructureConfigFirewall) DeepCopy() *InfrastructureConfigFirewall {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigFirewall)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigFirewallRule) DeepCopyInto(out *InfrastructureConfigFirewallRule) {
+	*out = *in
+	if in.SourceIPs != nil {
+		in, out := &in.SourceIPs, &out.SourceIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DestinationIPs != nil {
+		in, out := &in.DestinationIPs, &out.DestinationIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigFirewallRule.
+func (in *InfrastructureConfigFirewallRule) DeepCopy() *InfrastructureConfigFirewallRule {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigFirewallRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigFloatingIPs) DeepCopyInto(out *InfrastructureConfigFloatingIPs) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigFloatingIPs.
+func (in *InfrastructureConfigFloatingIPs) DeepCopy() *InfrastructureConfigFloatingIPs {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigFloatingIPs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigNATGateway) DeepCopyInto(out *InfrastructureConfigNATGateway) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigNATGateway.
+func (in *InfrastructureConfigNATGateway) DeepCopy() *InfrastructureConfigNATGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigNATGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfrastructureConfigNetwork) DeepCopyInto(out *InfrastructureConfigNetwork) {
 	*out = *in
@@ -235,6 +359,13 @@ func (in *InfrastructureConfigNetwork) DeepCopy() *InfrastructureConfigNetwork {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfrastructureConfigNetworkIDs) DeepCopyInto(out *InfrastructureConfigNetworkIDs) {
 	*out = *in
+	if in.WorkersSubnets != nil {
+		in, out := &in.WorkersSubnets, &out.WorkersSubnets
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -256,6 +387,21 @@ func (in *InfrastructureConfigNetworks) DeepCopyInto(out *InfrastructureConfigNe
 		*out = new(InfrastructureConfigNetwork)
 		**out = **in
 	}
+	if in.Existing != nil {
+		in, out := &in.Existing, &out.Existing
+		*out = new(InfrastructureConfigExistingNetwork)
+		**out = **in
+	}
+	if in.Shared != nil {
+		in, out := &in.Shared, &out.Shared
+		*out = new(InfrastructureConfigSharedNetwork)
+		**out = **in
+	}
+	if in.VSwitch != nil {
+		in, out := &in.VSwitch, &out.VSwitch
+		*out = new(InfrastructureConfigVSwitch)
+		**out = **in
+	}
 	return
 }
 
@@ -269,6 +415,68 @@ func (in *InfrastructureConfigNetworks) DeepCopy() *InfrastructureConfigNetworks
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigSharedNetwork) DeepCopyInto(out *InfrastructureConfigSharedNetwork) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigSharedNetwork.
+func (in *InfrastructureConfigSharedNetwork) DeepCopy() *InfrastructureConfigSharedNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigSharedNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureConfigVSwitch) DeepCopyInto(out *InfrastructureConfigVSwitch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureConfigVSwitch.
+func (in *InfrastructureConfigVSwitch) DeepCopy() *InfrastructureConfigVSwitch {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureConfigVSwitch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureState) DeepCopyInto(out *InfrastructureState) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.ProviderStatus != nil {
+		in, out := &in.ProviderStatus, &out.ProviderStatus
+		*out = new(InfrastructureStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureState.
+func (in *InfrastructureState) DeepCopy() *InfrastructureState {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InfrastructureState) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfrastructureStatus) DeepCopyInto(out *InfrastructureStatus) {
 	*out = *in
@@ -283,6 +491,16 @@ func (in *InfrastructureStatus) DeepCopyInto(out *InfrastructureStatus) {
 	if in.NetworkIDs != nil {
 		in, out := &in.NetworkIDs, &out.NetworkIDs
 		*out = new(InfrastructureConfigNetworkIDs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FloatingIPIDs != nil {
+		in, out := &in.FloatingIPIDs, &out.FloatingIPIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkRoutes != nil {
+		in, out := &in.NetworkRoutes, &out.NetworkRoutes
+		*out = new(InfrastructureStatusNetworkRoutes)
 		**out = **in
 	}
 	return
@@ -306,6 +524,22 @@ func (in *InfrastructureStatus) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InfrastructureStatusNetworkRoutes) DeepCopyInto(out *InfrastructureStatusNetworkRoutes) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureStatusNetworkRoutes.
+func (in *InfrastructureStatusNetworkRoutes) DeepCopy() *InfrastructureStatusNetworkRoutes {
+	if in == nil {
+		return nil
+	}
+	out := new(InfrastructureStatusNetworkRoutes)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineImage) DeepCopyInto(out *MachineImage) {
 	*out = *in
@@ -409,6 +643,18 @@ func (in *RegionSpec) DeepCopy() *RegionSpec {
 func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.ReservedPrimaryIPs != nil {
+		in, out := &in.ReservedPrimaryIPs, &out.ReservedPrimaryIPs
+		*out = new(WorkerConfigReservedPrimaryIPs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -430,6 +676,27 @@ func (in *WorkerConfig) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerConfigReservedPrimaryIPs) DeepCopyInto(out *WorkerConfigReservedPrimaryIPs) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerConfigReservedPrimaryIPs.
+func (in *WorkerConfigReservedPrimaryIPs) DeepCopy() *WorkerConfigReservedPrimaryIPs {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerConfigReservedPrimaryIPs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerStatus) DeepCopyInto(out *WorkerStatus) {
 	*out = *in
@@ -446,6 +713,13 @@ func (in *WorkerStatus) DeepCopyInto(out *WorkerStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.ReservedPrimaryIPs != nil {
+		in, out := &in.ReservedPrimaryIPs, &out.ReservedPrimaryIPs
+		*out = make(map[string]WorkerStatusReservedPrimaryIPs, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -466,3 +740,29 @@ func (in *WorkerStatus) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerStatusReservedPrimaryIPs) DeepCopyInto(out *WorkerStatusReservedPrimaryIPs) {
+	*out = *in
+	if in.IPv4 != nil {
+		in, out := &in.IPv4, &out.IPv4
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPv6 != nil {
+		in, out := &in.IPv6, &out.IPv6
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerStatusReservedPrimaryIPs.
+func (in *WorkerStatusReservedPrimaryIPs) DeepCopy() *WorkerStatusReservedPrimaryIPs {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerStatusReservedPrimaryIPs)
+	in.DeepCopyInto(out)
+	return out
+}
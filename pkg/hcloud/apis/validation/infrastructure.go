@@ -19,26 +19,233 @@ package validation
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	"github.com/gardener/gardener/pkg/apis/core"
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 )
 
+// reservedHetznerCidr is the range containing the gateway 172.31.1.1 used by Hetzner for the public network of servers.
+const reservedHetznerCidr = "172.31.1.0/24"
+
+// reservedLabelPrefixes are the label key prefixes of the labels identifying the HCloud resources of a shoot.
+var reservedLabelPrefixes = []string{"cluster.gardener.cloud/", "hcloud.provider.extensions.gardener.cloud/", "mcm.gardener.cloud/"}
+
 // ValidateInfrastructureConfig validates infrastructure config
-func ValidateInfrastructureConfig(infraConfig *apis.InfrastructureConfig, nodes *string, pods *string, services *string) field.ErrorList {
+//
+// PARAMETERS
+// infraConfig *apis.InfrastructureConfig Infrastructure config to validate
+// nodes       *string                    Nodes CIDR of the shoot
+// pods        *string                    Pods CIDR of the shoot
+// services    *string                    Services CIDR of the shoot
+// fldPath     *field.Path                Field path of the infrastructure config
+func ValidateInfrastructureConfig(infraConfig *apis.InfrastructureConfig, nodes *string, pods *string, services *string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+
+	allErrs = append(allErrs, ValidateLabels(infraConfig.Labels, fldPath.Child("labels"))...)
+
+	if nil != infraConfig.Firewall {
+		if "" != infraConfig.Firewall.NodePortRange {
+			if _, err := utilnet.ParsePortRange(infraConfig.Firewall.NodePortRange); nil != err {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("firewall", "nodePortRange"), infraConfig.Firewall.NodePortRange, err.Error()))
+			}
+		}
+
+		allErrs = append(allErrs, ValidateFirewallRules(infraConfig.Firewall.Rules, fldPath.Child("firewall", "rules"))...)
+	}
+
+	if nil == infraConfig.Networks {
+		return allErrs
+	}
+
+	networksPath := fldPath.Child("networks")
+	workersCidr, workersPath := getWorkersCidr(infraConfig.Networks, networksPath)
+
+	_, reservedIPNet, _ := net.ParseCIDR(reservedHetznerCidr)
+	shootIPNets := map[string]*net.IPNet{}
+
+	if nil != pods {
+		shootIPNets["pods"] = parseIPv4Cidr(*pods)
+	}
+
+	if nil != services {
+		shootIPNets["services"] = parseIPv4Cidr(*services)
+	}
+
+	var workersIPNet *net.IPNet
+
+	if "" != workersCidr {
+		var errs field.ErrorList
+
+		workersIPNet, errs = validateCidr(workersCidr, shootIPNets, reservedIPNet, workersPath)
+		allErrs = append(allErrs, errs...)
+
+		if nil != workersIPNet && nil != nodes {
+			nodesIPNet := parseIPv4Cidr(*nodes)
+
+			if nil != nodesIPNet && !containsCidr(workersIPNet, nodesIPNet) {
+				allErrs = append(allErrs, field.Invalid(workersPath, workersCidr, fmt.Sprintf("must contain the nodes range %q of the shoot", *nodes)))
+			}
+		}
+	}
+
+	if nil != infraConfig.Networks.Shared {
+		sharedPath := networksPath.Child("shared", "cidr")
+		sharedIPNet, errs := validateCidr(infraConfig.Networks.Shared.Cidr, shootIPNets, reservedIPNet, sharedPath)
+		allErrs = append(allErrs, errs...)
+
+		if nil != sharedIPNet && nil != workersIPNet && !containsCidr(sharedIPNet, workersIPNet) {
+			allErrs = append(allErrs, field.Invalid(workersPath, workersCidr, fmt.Sprintf("must be part of the shared network range %q", infraConfig.Networks.Shared.Cidr)))
+		}
+	}
+
+	if nil != infraConfig.Networks.VSwitch {
+		vSwitchPath := networksPath.Child("vSwitch", "cidr")
+		vSwitchIPNet, errs := validateCidr(infraConfig.Networks.VSwitch.Cidr, shootIPNets, reservedIPNet, vSwitchPath)
+		allErrs = append(allErrs, errs...)
+
+		if nil != vSwitchIPNet && nil != workersIPNet && isOverlapping(vSwitchIPNet, workersIPNet) {
+			allErrs = append(allErrs, field.Invalid(vSwitchPath, infraConfig.Networks.VSwitch.Cidr, fmt.Sprintf("must not overlap with the workers range %q", workersCidr)))
+		}
+
+		// Networks created for the shoot are enlarged to contain the workers and vSwitch subnets.
+		if 0 == len(errs) && nil != vSwitchIPNet && nil != workersIPNet && nil == infraConfig.Networks.Existing {
+			if _, err := apis.GetNetworkIPRange(workersIPNet, infraConfig.Networks.VSwitch.Cidr); nil != err {
+				allErrs = append(allErrs, field.Invalid(vSwitchPath, infraConfig.Networks.VSwitch.Cidr, fmt.Sprintf("must be part of the same private range as the workers range %q", workersCidr)))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateFirewallRules validates the user-defined firewall rules passed to HCloud in addition to the generated ones.
+//
+// PARAMETERS
+// rules   []apis.InfrastructureConfigFirewallRule Firewall rules to validate
+// fldPath *field.Path                             Field path of the firewall rules
+func ValidateFirewallRules(rules []apis.InfrastructureConfigFirewallRule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, rule := range rules {
+		rulePath := fldPath.Index(i)
+
+		switch rule.Direction {
+		case hcloud.FirewallRuleDirectionIn:
+			if 0 == len(rule.SourceIPs) {
+				allErrs = append(allErrs, field.Required(rulePath.Child("sourceIPs"), "must be set for inbound rules"))
+			}
+
+			if len(rule.DestinationIPs) > 0 {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("destinationIPs"), "must not be set for inbound rules"))
+			}
+		case hcloud.FirewallRuleDirectionOut:
+			if 0 == len(rule.DestinationIPs) {
+				allErrs = append(allErrs, field.Required(rulePath.Child("destinationIPs"), "must be set for outbound rules"))
+			}
+
+			if len(rule.SourceIPs) > 0 {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("sourceIPs"), "must not be set for outbound rules"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(rulePath.Child("direction"), rule.Direction, []hcloud.FirewallRuleDirection{hcloud.FirewallRuleDirectionIn, hcloud.FirewallRuleDirectionOut}))
+		}
+
+		switch rule.Protocol {
+		case hcloud.FirewallRuleProtocolTCP, hcloud.FirewallRuleProtocolUDP:
+			if "" == rule.Port {
+				allErrs = append(allErrs, field.Required(rulePath.Child("port"), fmt.Sprintf("must be set for protocol %q", rule.Protocol)))
+			} else if !isValidFirewallPort(rule.Port) {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("port"), rule.Port, "must be a port or port range between 1 and 65535, e.g. \"80\" or \"30000-32767\""))
+			}
+		case hcloud.FirewallRuleProtocolICMP, hcloud.FirewallRuleProtocolESP, hcloud.FirewallRuleProtocolGRE:
+			if "" != rule.Port {
+				allErrs = append(allErrs, field.Forbidden(rulePath.Child("port"), fmt.Sprintf("must not be set for protocol %q", rule.Protocol)))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(rulePath.Child("protocol"), rule.Protocol, []hcloud.FirewallRuleProtocol{hcloud.FirewallRuleProtocolTCP, hcloud.FirewallRuleProtocolUDP, hcloud.FirewallRuleProtocolICMP, hcloud.FirewallRuleProtocolESP, hcloud.FirewallRuleProtocolGRE}))
+		}
+
+		for j, cidr := range rule.SourceIPs {
+			if _, _, err := net.ParseCIDR(cidr); nil != err {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("sourceIPs").Index(j), cidr, err.Error()))
+			}
+		}
+
+		for j, cidr := range rule.DestinationIPs {
+			if _, _, err := net.ParseCIDR(cidr); nil != err {
+				allErrs = append(allErrs, field.Invalid(rulePath.Child("destinationIPs").Index(j), cidr, err.Error()))
+			}
+		}
+	}
+
 	return allErrs
 }
 
-func ValidateInfrastructureConfigUpdate(oldInfraConfig *apis.InfrastructureConfig, infraConfig *apis.InfrastructureConfig) field.ErrorList {
+// ValidateInfrastructureConfigUpdate validates changes of the infrastructure config
+//
+// PARAMETERS
+// oldInfraConfig *apis.InfrastructureConfig Infrastructure config before the update
+// infraConfig    *apis.InfrastructureConfig Infrastructure config to validate
+// fldPath        *field.Path                Field path of the infrastructure config
+func ValidateInfrastructureConfigUpdate(oldInfraConfig *apis.InfrastructureConfig, infraConfig *apis.InfrastructureConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+	networksPath := fldPath.Child("networks")
+
+	if nil == oldInfraConfig.Networks {
+		return allErrs
+	}
+
+	oldWorkersCidr, _ := getWorkersCidr(oldInfraConfig.Networks, networksPath)
+
+	if "" == oldWorkersCidr {
+		return allErrs
+	}
+
+	if nil == infraConfig.Networks {
+		return append(allErrs, field.Forbidden(networksPath, "field must not be removed"))
+	}
+
+	workersCidr, workersPath := getWorkersCidr(infraConfig.Networks, networksPath)
+
+	if oldWorkersCidr != workersCidr {
+		_, oldIPNet, oldErr := net.ParseCIDR(oldWorkersCidr)
+		_, ipNet, err := net.ParseCIDR(workersCidr)
+
+		if nil != infraConfig.Networks.Existing {
+			allErrs = append(allErrs, field.Invalid(workersPath, workersCidr, "field is immutable for existing networks"))
+		} else if nil != oldErr || nil != err || !containsCidr(ipNet, oldIPNet) {
+			allErrs = append(allErrs, field.Invalid(workersPath, workersCidr, fmt.Sprintf("field is immutable except for expanding %q to a larger range containing it", oldWorkersCidr)))
+		}
+	}
+
+	if nil != oldInfraConfig.Networks.WorkersConfiguration && nil != infraConfig.Networks.WorkersConfiguration {
+		allErrs = append(allErrs, apivalidation.ValidateImmutableField(infraConfig.Networks.WorkersConfiguration.Zone, oldInfraConfig.Networks.WorkersConfiguration.Zone, networksPath.Child("workersConfiguration", "zone"))...)
+	}
+
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(infraConfig.Networks.Existing, oldInfraConfig.Networks.Existing, networksPath.Child("existing"))...)
+	allErrs = append(allErrs, apivalidation.ValidateImmutableField(infraConfig.Networks.Shared, oldInfraConfig.Networks.Shared, networksPath.Child("shared"))...)
+
 	return allErrs
 }
 
 // ValidateInfrastructureConfigAgainstCloudProfile validates InfrastructureConfig against CloudProfile
+//
+// PARAMETERS
+// oldInfraConfig *apis.InfrastructureConfig      Infrastructure config before the update or nil
+// infraConfig    *apis.InfrastructureConfig      Infrastructure config to validate
+// shoot          *core.Shoot                     Shoot the infrastructure config belongs to
+// cloudProfile   *gardencorev1beta1.CloudProfile Cloud profile referenced by the shoot
+// fldPath        *field.Path                     Field path of the infrastructure config
 func ValidateInfrastructureConfigAgainstCloudProfile(
 	oldInfraConfig *apis.InfrastructureConfig,
 	infraConfig *apis.InfrastructureConfig,
@@ -47,6 +254,79 @@ func ValidateInfrastructureConfigAgainstCloudProfile(
 	fldPath *field.Path) field.ErrorList {
 
 	allErrs := field.ErrorList{}
+
+	for _, region := range cloudProfile.Spec.Regions {
+		if region.Name == shoot.Spec.Region {
+			return allErrs
+		}
+	}
+
+	return append(allErrs, field.NotFound(field.NewPath("spec", "region"), shoot.Spec.Region))
+}
+
+// SharedNetworkUser describes another shoot using a shared network.
+type SharedNetworkUser struct {
+	// Name is the namespaced name of the shoot.
+	Name string
+	// InfraConfig is the infrastructure config of the shoot.
+	InfraConfig *apis.InfrastructureConfig
+	// Pods is the pods CIDR of the shoot.
+	Pods *string
+	// NetworkRoutes is the number of routes the shoot requires in the shared network.
+	NetworkRoutes int
+}
+
+// ValidateSharedNetworkUsers validates that the workers and pods ranges of a shoot joining a shared network do not
+// overlap with the ones of the other shoots using it.
+//
+// PARAMETERS
+// infraConfig *apis.InfrastructureConfig Infrastructure config to validate
+// pods        *string                    Pods CIDR of the shoot
+// users       []SharedNetworkUser        Other shoots
+// fldPath     *field.Path                Field path of the infrastructure config
+func ValidateSharedNetworkUsers(infraConfig *apis.InfrastructureConfig, pods *string, users []SharedNetworkUser, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if nil == infraConfig.Networks || nil == infraConfig.Networks.Shared {
+		return allErrs
+	}
+
+	shared := infraConfig.Networks.Shared
+	workersCidr, workersPath := getWorkersCidr(infraConfig.Networks, fldPath.Child("networks"))
+	workersIPNet := parseIPv4Cidr(workersCidr)
+
+	var podsIPNet *net.IPNet
+
+	if nil != pods {
+		podsIPNet = parseIPv4Cidr(*pods)
+	}
+
+	for _, user := range users {
+		if nil == user.InfraConfig.Networks || nil == user.InfraConfig.Networks.Shared || user.InfraConfig.Networks.Shared.Name != shared.Name {
+			continue
+		}
+
+		if user.InfraConfig.Networks.Shared.Cidr != shared.Cidr {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("networks", "shared", "cidr"), shared.Cidr, fmt.Sprintf("must match the range %q used by shoot %q", user.InfraConfig.Networks.Shared.Cidr, user.Name)))
+		}
+
+		userWorkersCidr, _ := getWorkersCidr(user.InfraConfig.Networks, nil)
+		userWorkersIPNet := parseIPv4Cidr(userWorkersCidr)
+
+		if nil != workersIPNet && nil != userWorkersIPNet && isOverlapping(workersIPNet, userWorkersIPNet) {
+			allErrs = append(allErrs, field.Invalid(workersPath, workersCidr, fmt.Sprintf("must not overlap with the workers range %q of shoot %q", userWorkersCidr, user.Name)))
+		}
+
+		// Pod routes of all shoots are added to the shared network.
+		if nil != podsIPNet && nil != user.Pods {
+			userPodsIPNet := parseIPv4Cidr(*user.Pods)
+
+			if nil != userPodsIPNet && isOverlapping(podsIPNet, userPodsIPNet) {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "networking", "pods"), *pods, fmt.Sprintf("must not overlap with the pods range %q of shoot %q sharing the network", *user.Pods, user.Name)))
+			}
+		}
+	}
+
 	return allErrs
 }
 
@@ -61,5 +341,172 @@ func ValidateInfrastructureConfigSpec(spec *apis.InfrastructureConfig) []error {
 		allErrs = append(allErrs, fmt.Errorf("networks.workersConfiguration or networks.workers is a required field"))
 	}
 
+	if nil != spec.NATGateway && (nil == spec.Networks || (nil == spec.Networks.WorkersConfiguration && "" == spec.Networks.Workers)) {
+		allErrs = append(allErrs, fmt.Errorf("natGateway requires networks.workersConfiguration or networks.workers"))
+	}
+
+	if nil != spec.FloatingIPs {
+		if "" == spec.FloatingPoolName {
+			allErrs = append(allErrs, fmt.Errorf("floatingIPs requires floatingPoolName"))
+		}
+
+		if spec.FloatingIPs.Count < 0 {
+			allErrs = append(allErrs, fmt.Errorf("floatingIPs.count must not be negative"))
+		}
+
+		if "" != spec.FloatingIPs.IPFamily && hcloud.FloatingIPTypeIPv4 != spec.FloatingIPs.IPFamily && hcloud.FloatingIPTypeIPv6 != spec.FloatingIPs.IPFamily {
+			allErrs = append(allErrs, fmt.Errorf("floatingIPs.ipFamily must be %q or %q", hcloud.FloatingIPTypeIPv4, hcloud.FloatingIPTypeIPv6))
+		}
+	}
+
+	if nil != spec.Networks && nil != spec.Networks.Existing {
+		if (0 == spec.Networks.Existing.ID) == ("" == spec.Networks.Existing.Name) {
+			allErrs = append(allErrs, fmt.Errorf("networks.existing requires exactly one of id or name"))
+		}
+	}
+
+	if nil != spec.Networks && nil != spec.Networks.Shared {
+		if "" == spec.Networks.Shared.Name || "" == spec.Networks.Shared.Cidr {
+			allErrs = append(allErrs, fmt.Errorf("networks.shared requires name and cidr"))
+		}
+
+		if nil != spec.Networks.Existing {
+			allErrs = append(allErrs, fmt.Errorf("networks.shared and networks.existing are mutually exclusive"))
+		}
+
+		// The routes and vSwitch settings of a shared network would affect all shoots using it.
+		if nil != spec.Networks.VSwitch || nil != spec.NATGateway {
+			allErrs = append(allErrs, fmt.Errorf("networks.shared does not support networks.vSwitch or natGateway"))
+		}
+	}
+
+	return allErrs
+}
+
+// getWorkersCidr returns the workers CIDR configured and its field path.
+//
+// PARAMETERS
+// networks     *apis.InfrastructureConfigNetworks Networks configuration
+// networksPath *field.Path                        Field path of the networks configuration
+func getWorkersCidr(networks *apis.InfrastructureConfigNetworks, networksPath *field.Path) (string, *field.Path) {
+	if nil != networks.WorkersConfiguration {
+		return networks.WorkersConfiguration.Cidr, networksPath.Child("workersConfiguration", "cidr")
+	}
+
+	return networks.Workers, networksPath.Child("workers")
+}
+
+// containsCidr returns true if the given range contains the other one completely.
+//
+// PARAMETERS
+// ipNet    *net.IPNet Range to check
+// subIPNet *net.IPNet Range to be contained
+func containsCidr(ipNet, subIPNet *net.IPNet) bool {
+	ones, bits := ipNet.Mask.Size()
+	subOnes, subBits := subIPNet.Mask.Size()
+
+	return bits == subBits && ones <= subOnes && ipNet.Contains(subIPNet.IP)
+}
+
+// isValidFirewallPort returns true if the given port is a single port or a port range between 1 and 65535 as accepted
+// by HCloud firewall rules.
+//
+// PARAMETERS
+// port string Port or port range to check
+func isValidFirewallPort(port string) bool {
+	first, last, isRange := strings.Cut(port, "-")
+
+	firstPort, err := strconv.Atoi(first)
+	if nil != err || firstPort < 1 || firstPort > 65535 {
+		return false
+	}
+
+	if !isRange {
+		return true
+	}
+
+	lastPort, err := strconv.Atoi(last)
+
+	return nil == err && lastPort >= firstPort && lastPort <= 65535
+}
+
+// isOverlapping returns true if the given ranges overlap.
+//
+// PARAMETERS
+// ipNet      *net.IPNet Range to check
+// otherIPNet *net.IPNet Range to check against
+func isOverlapping(ipNet, otherIPNet *net.IPNet) bool {
+	return ipNet.Contains(otherIPNet.IP) || otherIPNet.Contains(ipNet.IP)
+}
+
+// parseIPv4Cidr returns the parsed range if the given CIDR is a valid IPv4 range and nil otherwise.
+//
+// PARAMETERS
+// cidr string CIDR to parse
+func parseIPv4Cidr(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if nil != err || nil == ipNet.IP.To4() {
+		return nil
+	}
+
+	return ipNet
+}
+
+// validateCidr validates that the CIDR given is a canonical private IPv4 range not overlapping with the shoot or reserved ones.
+//
+// PARAMETERS
+// cidr          string                CIDR to validate
+// shootIPNets   map[string]*net.IPNet Pods and services ranges of the shoot
+// reservedIPNet *net.IPNet            Range reserved by Hetzner
+// fldPath       *field.Path           Field path of the CIDR
+func validateCidr(cidr string, shootIPNets map[string]*net.IPNet, reservedIPNet *net.IPNet, fldPath *field.Path) (*net.IPNet, field.ErrorList) {
+	allErrs := field.ErrorList{}
+
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if nil != err {
+		return nil, append(allErrs, field.Invalid(fldPath, cidr, err.Error()))
+	}
+
+	if !ip.Equal(ipNet.IP) {
+		allErrs = append(allErrs, field.Invalid(fldPath, cidr, fmt.Sprintf("must be valid canonical CIDR, e.g. %q", ipNet.String())))
+	}
+
+	// The whole range must be part of a private range, e.g. "10.0.0.0/7" also contains public IPs.
+	if nil == ipNet.IP.To4() || !apis.IsPrivateIPRange(ipNet) {
+		allErrs = append(allErrs, field.Invalid(fldPath, cidr, "must be a private IPv4 range as defined in RFC 1918"))
+	}
+
+	for _, name := range []string{"pods", "services"} {
+		shootIPNet := shootIPNets[name]
+
+		if nil != shootIPNet && isOverlapping(ipNet, shootIPNet) {
+			allErrs = append(allErrs, field.Invalid(fldPath, cidr, fmt.Sprintf("must not overlap with the %s range %q of the shoot", name, shootIPNet.String())))
+		}
+	}
+
+	if isOverlapping(ipNet, reservedIPNet) {
+		allErrs = append(allErrs, field.Invalid(fldPath, cidr, fmt.Sprintf("must not overlap with %q reserved by Hetzner", reservedHetznerCidr)))
+	}
+
+	return ipNet, allErrs
+}
+
+// ValidateLabels validates user-defined labels added to HCloud resources. HCloud labels follow the syntax of
+// Kubernetes labels. Labels identifying the HCloud resources of a shoot can not be overridden.
+//
+// PARAMETERS
+// labels  map[string]string User-defined labels
+// fldPath *field.Path       Field path of the labels
+func ValidateLabels(labels map[string]string, fldPath *field.Path) field.ErrorList {
+	allErrs := metav1validation.ValidateLabels(labels, fldPath)
+
+	for key := range labels {
+		for _, prefix := range reservedLabelPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				allErrs = append(allErrs, field.Invalid(fldPath, key, fmt.Sprintf("must not use the prefix %q reserved for labels identifying the shoot", prefix)))
+			}
+		}
+	}
+
 	return allErrs
 }
@@ -18,8 +18,12 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/mock"
@@ -93,6 +97,330 @@ var _ = Describe("Infrastructure", func() {
 					errToHaveOccurred: false,
 				},
 			}),
+			Entry("natGateway without networks", &data{
+				setup: setup{},
+				action: action{
+					spec: &apis.InfrastructureConfig{
+						NATGateway: &apis.InfrastructureConfigNATGateway{},
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: []error{
+						fmt.Errorf("natGateway requires networks.workersConfiguration or networks.workers"),
+					},
+				},
+			}),
+			Entry("floatingIPs without floatingPoolName", &data{
+				setup: setup{},
+				action: action{
+					spec: &apis.InfrastructureConfig{
+						FloatingIPs: &apis.InfrastructureConfigFloatingIPs{
+							Count: 1,
+						},
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: []error{
+						fmt.Errorf("floatingIPs requires floatingPoolName"),
+					},
+				},
+			}),
+			Entry("floatingIPs with invalid count and IP family", &data{
+				setup: setup{},
+				action: action{
+					spec: &apis.InfrastructureConfig{
+						FloatingPoolName: mock.TestFloatingPoolName,
+						FloatingIPs: &apis.InfrastructureConfigFloatingIPs{
+							Count:    -1,
+							IPFamily: "ipv5",
+						},
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: []error{
+						fmt.Errorf("floatingIPs.count must not be negative"),
+						fmt.Errorf("floatingIPs.ipFamily must be %q or %q", "ipv4", "ipv6"),
+					},
+				},
+			}),
+			Entry("existing network referenced by ID", &data{
+				setup: setup{},
+				action: action{
+					spec: &apis.InfrastructureConfig{
+						Networks: &apis.InfrastructureConfigNetworks{
+							Existing: &apis.InfrastructureConfigExistingNetwork{
+								ID: 42,
+							},
+							Workers: mock.TestInfrastructureWorkersNetworkCidr,
+						},
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: false,
+				},
+			}),
+			Entry("shared network with NAT gateway", &data{
+				setup: setup{},
+				action: action{
+					spec: &apis.InfrastructureConfig{
+						Networks: &apis.InfrastructureConfigNetworks{
+							Shared: &apis.InfrastructureConfigSharedNetwork{
+								Name: "shared",
+								Cidr: "10.0.0.0/8",
+							},
+							Workers: "10.250.0.0/19",
+						},
+						NATGateway: &apis.InfrastructureConfigNATGateway{},
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: []error{
+						fmt.Errorf("networks.shared does not support networks.vSwitch or natGateway"),
+					},
+				},
+			}),
+			Entry("existing network referenced by ID and name", &data{
+				setup: setup{},
+				action: action{
+					spec: &apis.InfrastructureConfig{
+						Networks: &apis.InfrastructureConfigNetworks{
+							Existing: &apis.InfrastructureConfigExistingNetwork{
+								ID:   42,
+								Name: "existing",
+							},
+							Workers: mock.TestInfrastructureWorkersNetworkCidr,
+						},
+					},
+				},
+				expect: expect{
+					errToHaveOccurred: true,
+					errList: []error{
+						fmt.Errorf("networks.existing requires exactly one of id or name"),
+					},
+				},
+			}),
 		)
 	})
+
+	Describe("#ValidateInfrastructureConfig", func() {
+		fldPath := field.NewPath("spec", "provider", "infrastructureConfig")
+
+		DescribeTable("##table",
+			func(workers, vSwitch, nodes string, expectedFields []string) {
+				var (
+					pods     = "100.96.0.0/11"
+					services = "100.64.0.0/13"
+				)
+
+				infraConfig := &apis.InfrastructureConfig{
+					Networks: &apis.InfrastructureConfigNetworks{
+						Workers: workers,
+					},
+				}
+
+				if "" != vSwitch {
+					infraConfig.Networks.VSwitch = &apis.InfrastructureConfigVSwitch{ID: 42, Cidr: vSwitch}
+				}
+
+				errList := ValidateInfrastructureConfig(infraConfig, &nodes, &pods, &services, fldPath)
+
+				var fields []string
+				for _, err := range errList {
+					fields = append(fields, err.Field)
+				}
+
+				Expect(fields).To(Equal(expectedFields))
+			},
+
+			Entry("valid workers range", "10.250.0.0/16", "", "10.250.0.0/19", nil),
+			Entry("valid workers and vSwitch ranges", "10.250.0.0/19", "10.250.64.0/24", "10.250.0.0/19", nil),
+			Entry("invalid workers range", "10.250.0.0", "", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("non-canonical workers range", "10.250.0.1/16", "", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("public workers range", "1.2.3.0/24", "", "1.2.3.0/24", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("workers range exceeding the private range", "10.0.0.0/7", "", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("IPv6 workers range", "fd00::/64", "", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workers", "spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("workers range not containing nodes range", "10.250.0.0/20", "", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("workers range overlapping pods range", "100.96.0.0/16", "", "100.96.0.0/16", []string{"spec.provider.infrastructureConfig.networks.workers", "spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("workers range overlapping Hetzner gateway range", "172.16.0.0/12", "", "172.16.0.0/12", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("vSwitch range overlapping Hetzner gateway range", "10.250.0.0/19", "172.31.1.0/28", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.vSwitch.cidr"}),
+			Entry("vSwitch range outside of the private range of the workers range", "10.250.0.0/19", "192.168.0.0/24", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.vSwitch.cidr"}),
+			Entry("vSwitch range overlapping services range", "10.250.0.0/19", "100.64.1.0/24", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.vSwitch.cidr", "spec.provider.infrastructureConfig.networks.vSwitch.cidr"}),
+		)
+
+		It("should forbid workers ranges outside of the shared network range", func() {
+			nodes := "10.250.0.0/19"
+			infraConfig := &apis.InfrastructureConfig{
+				Networks: &apis.InfrastructureConfigNetworks{
+					Shared:  &apis.InfrastructureConfigSharedNetwork{Name: "shared", Cidr: "10.0.0.0/16"},
+					Workers: "10.250.0.0/19",
+				},
+			}
+
+			errList := ValidateInfrastructureConfig(infraConfig, &nodes, nil, nil, fldPath)
+
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.infrastructureConfig.networks.workers"))
+		})
+
+		It("should forbid invalid firewall node port ranges", func() {
+			infraConfig := &apis.InfrastructureConfig{
+				Firewall: &apis.InfrastructureConfigFirewall{NodePortRange: "30000-"},
+			}
+
+			errList := ValidateInfrastructureConfig(infraConfig, nil, nil, nil, fldPath)
+
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.infrastructureConfig.firewall.nodePortRange"))
+
+			infraConfig.Firewall.NodePortRange = "30000-32767"
+			Expect(ValidateInfrastructureConfig(infraConfig, nil, nil, nil, fldPath)).To(BeEmpty())
+		})
+	})
+
+	Describe("#ValidateFirewallRules", func() {
+		DescribeTable("##table",
+			func(rule apis.InfrastructureConfigFirewallRule, expectedFields []string) {
+				errList := ValidateFirewallRules([]apis.InfrastructureConfigFirewallRule{rule}, field.NewPath("rules"))
+
+				var fields []string
+				for _, err := range errList {
+					fields = append(fields, err.Field)
+				}
+
+				Expect(fields).To(Equal(expectedFields))
+			},
+
+			Entry("valid inbound TCP rule", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "tcp", Port: "80", SourceIPs: []string{"0.0.0.0/0", "::/0"}}, nil),
+			Entry("valid outbound UDP rule with port range", apis.InfrastructureConfigFirewallRule{Direction: "out", Protocol: "udp", Port: "30000-32767", DestinationIPs: []string{"10.0.0.0/8"}}, nil),
+			Entry("valid inbound ICMP rule", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "icmp", SourceIPs: []string{"10.0.0.0/8"}}, nil),
+			Entry("unsupported direction", apis.InfrastructureConfigFirewallRule{Direction: "both", Protocol: "icmp", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].direction"}),
+			Entry("unsupported protocol", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "sctp", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].protocol"}),
+			Entry("TCP rule without port", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "tcp", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].port"}),
+			Entry("port out of range", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "tcp", Port: "65536", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].port"}),
+			Entry("reversed port range", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "udp", Port: "32767-30000", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].port"}),
+			Entry("invalid port", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "tcp", Port: "http", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].port"}),
+			Entry("ICMP rule with port", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "icmp", Port: "80", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].port"}),
+			Entry("inbound rule without source IPs", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "gre", DestinationIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].sourceIPs", "rules[0].destinationIPs"}),
+			Entry("outbound rule without destination IPs", apis.InfrastructureConfigFirewallRule{Direction: "out", Protocol: "esp", SourceIPs: []string{"10.0.0.0/8"}}, []string{"rules[0].destinationIPs", "rules[0].sourceIPs"}),
+			Entry("invalid source IP range", apis.InfrastructureConfigFirewallRule{Direction: "in", Protocol: "icmp", SourceIPs: []string{"10.0.0.0/8", "10.0.0.1"}}, []string{"rules[0].sourceIPs[1]"}),
+			Entry("invalid destination IP range", apis.InfrastructureConfigFirewallRule{Direction: "out", Protocol: "icmp", DestinationIPs: []string{"fd00::/129"}}, []string{"rules[0].destinationIPs[0]"}),
+		)
+
+		It("should be validated as part of the infrastructure config", func() {
+			infraConfig := &apis.InfrastructureConfig{
+				Firewall: &apis.InfrastructureConfigFirewall{
+					Rules: []apis.InfrastructureConfigFirewallRule{{Direction: "in", Protocol: "tcp", Port: "0", SourceIPs: []string{"10.0.0.0/8"}}},
+				},
+			}
+
+			errList := ValidateInfrastructureConfig(infraConfig, nil, nil, nil, field.NewPath("spec", "provider", "infrastructureConfig"))
+
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.infrastructureConfig.firewall.rules[0].port"))
+		})
+	})
+
+	Describe("#ValidateLabels", func() {
+		DescribeTable("##table",
+			func(labels map[string]string, expectedErrors int) {
+				Expect(ValidateLabels(labels, field.NewPath("labels"))).To(HaveLen(expectedErrors))
+			},
+
+			Entry("no labels", nil, 0),
+			Entry("valid labels", map[string]string{"team": "finance", "example.com/cost-center": "4711", "environment": ""}, 0),
+			Entry("invalid key", map[string]string{"cost center": "4711"}, 1),
+			Entry("invalid value", map[string]string{"team": "-finance"}, 1),
+			Entry("value too long", map[string]string{"team": strings.Repeat("a", 64)}, 1),
+			Entry("reserved prefix", map[string]string{"cluster.gardener.cloud/id": "foo"}, 1),
+		)
+	})
+
+	Describe("#ValidateSharedNetworkUsers", func() {
+		newInfraConfig := func(name, cidr, workers string) *apis.InfrastructureConfig {
+			return &apis.InfrastructureConfig{
+				Networks: &apis.InfrastructureConfigNetworks{
+					Shared:  &apis.InfrastructureConfigSharedNetwork{Name: name, Cidr: cidr},
+					Workers: workers,
+				},
+			}
+		}
+
+		DescribeTable("##table",
+			func(user *apis.InfrastructureConfig, userPods string, expectedFields []string) {
+				pods := "100.96.0.0/11"
+				users := []SharedNetworkUser{{Name: "garden-test/other", InfraConfig: user, Pods: &userPods}}
+
+				errList := ValidateSharedNetworkUsers(newInfraConfig("shared", "10.0.0.0/8", "10.250.0.0/19"), &pods, users, field.NewPath("spec", "provider", "infrastructureConfig"))
+
+				var fields []string
+				for _, err := range errList {
+					fields = append(fields, err.Field)
+				}
+
+				Expect(fields).To(Equal(expectedFields))
+			},
+
+			Entry("disjoint shoot", newInfraConfig("shared", "10.0.0.0/8", "10.251.0.0/19"), "100.128.0.0/11", nil),
+			Entry("overlapping shoot using another shared network", newInfraConfig("other", "10.0.0.0/8", "10.250.0.0/19"), "100.96.0.0/11", nil),
+			Entry("overlapping workers range", newInfraConfig("shared", "10.0.0.0/8", "10.250.0.0/16"), "100.128.0.0/11", []string{"spec.provider.infrastructureConfig.networks.workers"}),
+			Entry("overlapping pods range", newInfraConfig("shared", "10.0.0.0/8", "10.251.0.0/19"), "100.96.0.0/11", []string{"spec.networking.pods"}),
+			Entry("different shared network range", newInfraConfig("shared", "10.0.0.0/9", "10.1.0.0/19"), "100.128.0.0/11", []string{"spec.provider.infrastructureConfig.networks.shared.cidr"}),
+		)
+	})
+
+	Describe("#ValidateInfrastructureConfigUpdate", func() {
+		fldPath := field.NewPath("spec", "provider", "infrastructureConfig")
+
+		DescribeTable("##table",
+			func(oldWorkers, workers string, expectedFields []string) {
+				oldInfraConfig := &apis.InfrastructureConfig{
+					Networks: &apis.InfrastructureConfigNetworks{
+						WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: oldWorkers, Zone: "eu-central"},
+					},
+				}
+
+				infraConfig := &apis.InfrastructureConfig{
+					Networks: &apis.InfrastructureConfigNetworks{
+						WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: workers, Zone: "eu-central"},
+					},
+				}
+
+				errList := ValidateInfrastructureConfigUpdate(oldInfraConfig, infraConfig, fldPath)
+
+				var fields []string
+				for _, err := range errList {
+					fields = append(fields, err.Field)
+				}
+
+				Expect(fields).To(Equal(expectedFields))
+			},
+
+			Entry("unchanged workers range", "10.250.0.0/19", "10.250.0.0/19", nil),
+			Entry("expanded workers range", "10.250.0.0/19", "10.250.0.0/16", nil),
+			Entry("shrunk workers range", "10.250.0.0/16", "10.250.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workersConfiguration.cidr"}),
+			Entry("moved workers range", "10.250.0.0/19", "10.251.0.0/19", []string{"spec.provider.infrastructureConfig.networks.workersConfiguration.cidr"}),
+			Entry("removed workers range", "10.250.0.0/19", "", []string{"spec.provider.infrastructureConfig.networks.workersConfiguration.cidr"}),
+		)
+
+		It("should forbid expanding the workers range of an existing network", func() {
+			oldInfraConfig := &apis.InfrastructureConfig{
+				Networks: &apis.InfrastructureConfigNetworks{
+					Existing: &apis.InfrastructureConfigExistingNetwork{Name: "existing"},
+					Workers:  "10.250.0.0/19",
+				},
+			}
+
+			infraConfig := oldInfraConfig.DeepCopy()
+			infraConfig.Networks.Workers = "10.250.0.0/16"
+
+			errList := ValidateInfrastructureConfigUpdate(oldInfraConfig, infraConfig, fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.infrastructureConfig.networks.workers"))
+			Expect(errList[0].Detail).To(Equal("field is immutable for existing networks"))
+		})
+	})
 })
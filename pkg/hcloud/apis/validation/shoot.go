@@ -18,12 +18,91 @@ limitations under the License.
 package validation
 
 import (
+	"net"
+
 	"github.com/gardener/gardener/pkg/apis/core"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 )
 
 // ValidateShootNetworking validates the networking section for a shoot
 func ValidateShootNetworking(networking core.Networking) field.ErrorList {
 	allErrs := field.ErrorList{}
+	fldPath := field.NewPath("spec", "networking")
+
+	ipFamilies := networking.IPFamilies
+	if 0 == len(ipFamilies) {
+		ipFamilies = []core.IPFamily{core.IPFamilyIPv4}
+	}
+
+	knownIPFamilies := map[core.IPFamily]bool{}
+
+	for i, ipFamily := range ipFamilies {
+		if core.IPFamilyIPv4 != ipFamily && core.IPFamilyIPv6 != ipFamily {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("ipFamilies").Index(i), ipFamily, []string{string(core.IPFamilyIPv4), string(core.IPFamilyIPv6)}))
+		} else if knownIPFamilies[ipFamily] {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Child("ipFamilies").Index(i), ipFamily))
+		}
+
+		knownIPFamilies[ipFamily] = true
+	}
+
+	// The pod and service ranges configured belong to the primary IP family of the shoot.
+	allErrs = append(allErrs, validateCidrIPFamily(networking.Pods, ipFamilies[0], fldPath.Child("pods"))...)
+	allErrs = append(allErrs, validateCidrIPFamily(networking.Services, ipFamilies[0], fldPath.Child("services"))...)
+
+	if nil != networking.Nodes {
+		_, _, err := net.ParseCIDR(*networking.Nodes)
+		if nil != err {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("nodes"), *networking.Nodes, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// ValidateInfrastructureConfigIPFamilies validates the infrastructure config against the IP families of a shoot.
+//
+// PARAMETERS
+// infraConfig *apis.InfrastructureConfig Infrastructure config to validate
+// ipFamilies  []core.IPFamily            IP families of the shoot
+// fldPath     *field.Path                Field path of the infrastructure config
+func ValidateInfrastructureConfigIPFamilies(infraConfig *apis.InfrastructureConfig, ipFamilies []core.IPFamily, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, ipFamily := range ipFamilies {
+		if core.IPFamilyIPv6 == ipFamily && nil != infraConfig.NATGateway {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("natGateway"), "worker nodes without public IPs are not supported for IPv6 shoots"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateCidrIPFamily validates that the CIDR given is part of the IP family expected.
+//
+// PARAMETERS
+// cidr     *string       CIDR to validate
+// ipFamily core.IPFamily IP family expected
+// fldPath  *field.Path   Field path of the CIDR
+func validateCidrIPFamily(cidr *string, ipFamily core.IPFamily, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if nil == cidr {
+		return allErrs
+	}
+
+	_, ipNet, err := net.ParseCIDR(*cidr)
+	if nil != err {
+		return append(allErrs, field.Invalid(fldPath, *cidr, err.Error()))
+	}
+
+	isIPv4 := nil != ipNet.IP.To4()
+
+	if (core.IPFamilyIPv4 == ipFamily) != isIPv4 {
+		allErrs = append(allErrs, field.Invalid(fldPath, *cidr, "must be a range of the IP family "+string(ipFamily)))
+	}
+
 	return allErrs
 }
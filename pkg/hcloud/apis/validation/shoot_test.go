@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation contains functions to validate controller specifications
+package validation
+
+import (
+	"github.com/gardener/gardener/pkg/apis/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+)
+
+var _ = Describe("Shoot", func() {
+	Describe("#ValidateShootNetworking", func() {
+		DescribeTable("##table",
+			func(ipFamilies []core.IPFamily, pods, services string, expectedFields []string) {
+				networking := core.Networking{
+					IPFamilies: ipFamilies,
+					Pods:       &pods,
+					Services:   &services,
+				}
+
+				errList := ValidateShootNetworking(networking)
+
+				var fields []string
+				for _, err := range errList {
+					fields = append(fields, err.Field)
+				}
+
+				Expect(fields).To(Equal(expectedFields))
+			},
+
+			Entry("IPv4 without IP families", nil, "100.96.0.0/11", "100.64.0.0/13", nil),
+			Entry("IPv6", []core.IPFamily{core.IPFamilyIPv6}, "2001:db8:1::/48", "2001:db8:2::/108", nil),
+			Entry("dual-stack with IPv4 ranges", []core.IPFamily{core.IPFamilyIPv4, core.IPFamilyIPv6}, "100.96.0.0/11", "100.64.0.0/13", nil),
+			Entry("IPv6 with IPv4 ranges", []core.IPFamily{core.IPFamilyIPv6}, "100.96.0.0/11", "100.64.0.0/13", []string{"spec.networking.pods", "spec.networking.services"}),
+			Entry("IPv4 with IPv6 pod range", []core.IPFamily{core.IPFamilyIPv4}, "2001:db8:1::/48", "100.64.0.0/13", []string{"spec.networking.pods"}),
+			Entry("invalid service range", nil, "100.96.0.0/11", "100.64.0.0", []string{"spec.networking.services"}),
+			Entry("duplicate IP family", []core.IPFamily{core.IPFamilyIPv4, core.IPFamilyIPv4}, "100.96.0.0/11", "100.64.0.0/13", []string{"spec.networking.ipFamilies[1]"}),
+		)
+	})
+
+	Describe("#ValidateInfrastructureConfigIPFamilies", func() {
+		fldPath := field.NewPath("spec", "provider", "infrastructureConfig")
+
+		It("should forbid a NAT gateway for IPv6 shoots", func() {
+			infraConfig := &apis.InfrastructureConfig{NATGateway: &apis.InfrastructureConfigNATGateway{}}
+
+			errList := ValidateInfrastructureConfigIPFamilies(infraConfig, []core.IPFamily{core.IPFamilyIPv4, core.IPFamilyIPv6}, fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.infrastructureConfig.natGateway"))
+		})
+
+		It("should allow a NAT gateway for IPv4 shoots", func() {
+			infraConfig := &apis.InfrastructureConfig{NATGateway: &apis.InfrastructureConfigNATGateway{}}
+
+			Expect(ValidateInfrastructureConfigIPFamilies(infraConfig, nil, fldPath)).To(BeEmpty())
+		})
+	})
+})
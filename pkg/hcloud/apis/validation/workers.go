@@ -18,17 +18,22 @@ limitations under the License.
 package validation
 
 import (
+	"fmt"
+
 	"github.com/gardener/gardener/pkg/apis/core"
 	validationutils "github.com/gardener/gardener/pkg/utils/validation"
+	"github.com/hetznercloud/hcloud-go/v2/hcloud"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 )
 
 // ValidateWorkers validates the workers of a Shoot.
-func ValidateWorkers(workers []core.Worker, fldPath *field.Path) field.ErrorList {
+func ValidateWorkers(workers []core.Worker, infraConfig *apis.InfrastructureConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
 	for i, worker := range workers {
@@ -51,19 +56,122 @@ func ValidateWorkers(workers []core.Worker, fldPath *field.Path) field.ErrorList
 			zones.Insert(zone)
 		}
 
-		providerConfig, _ := transcoder.DecodeWorkerConfigFromRawExtension(worker.ProviderConfig)
+		providerConfig, err := transcoder.DecodeWorkerConfigFromRawExtension(worker.ProviderConfig)
+		if nil != err {
+			allErrs = append(allErrs, field.Invalid(workerFldPath.Child("providerConfig"), string(worker.ProviderConfig.Raw), err.Error()))
+			continue
+		}
+
+		allErrs = append(allErrs, ValidateLabels(providerConfig.Labels, workerFldPath.Child("providerConfig", "labels"))...)
 		if providerConfig.PlacementGroupType == "spread" {
 			if worker.Maximum+worker.MaxSurge.IntVal > 10 {
 				allErrs = append(allErrs, field.Forbidden(workerFldPath.Child("maximum"), "When the workers of this pool should be placed in a placmentgroup, the pool must not be lager than 10 - MaxSurge"))
 			}
 		}
+
+		if nil != providerConfig.ReservedPrimaryIPs {
+			reservedPrimaryIPsFldPath := workerFldPath.Child("providerConfig", "reservedPrimaryIPs")
+
+			if !providerConfig.ReservedPrimaryIPs.IPv4 && !providerConfig.ReservedPrimaryIPs.IPv6 {
+				allErrs = append(allErrs, field.Required(reservedPrimaryIPsFldPath, "at least one of ipv4 or ipv6 must be enabled"))
+			}
+
+			if nil != providerConfig.ReservedPrimaryIPs.Count && *providerConfig.ReservedPrimaryIPs.Count < 0 {
+				allErrs = append(allErrs, field.Invalid(reservedPrimaryIPsFldPath.Child("count"), *providerConfig.ReservedPrimaryIPs.Count, "must not be negative"))
+			}
+
+			if nil != infraConfig && nil != infraConfig.NATGateway {
+				allErrs = append(allErrs, field.Forbidden(reservedPrimaryIPsFldPath, "must not be used with a NAT gateway as worker nodes egressing through it do not get public IPs assigned"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// GetWorkerNetworkRoutes returns the number of HCloud network routes required for the pod CIDRs of a worker pool at
+// its maximum size during a rolling update.
+//
+// PARAMETERS
+// maximum  int32               Maximum number of machines of the worker pool
+// maxSurge *intstr.IntOrString Maximum number of machines created during an update
+func GetWorkerNetworkRoutes(maximum int32, maxSurge *intstr.IntOrString) int {
+	routes := int(maximum)
+
+	if nil != maxSurge {
+		surge, err := intstr.GetScaledValueFromIntOrPercent(maxSurge, int(maximum), true)
+		if nil == err {
+			routes += surge
+		}
+	}
+
+	return routes
+}
+
+// getNetworkRoutes returns the number of HCloud network routes required by the shoot in the workers network.
+//
+// PARAMETERS
+// workers     []core.Worker              Worker pools of the shoot
+// infraConfig *apis.InfrastructureConfig Infrastructure config of the shoot
+func getNetworkRoutes(workers []core.Worker, infraConfig *apis.InfrastructureConfig) int {
+	routes := 0
+	if nil != infraConfig.NATGateway {
+		// The NAT gateway is the default route of the workers network.
+		routes++
+	}
+
+	for _, worker := range workers {
+		routes += GetWorkerNetworkRoutes(worker.Maximum, worker.MaxSurge)
+	}
+
+	return routes
+}
+
+// ValidateNetworkRouteLimit validates that the HCloud network routes required for the pod CIDRs of the summed worker
+// pool maxima and surges do not exceed the route limit of the workers network. Routes of other shoots using the same
+// shared network are taken into account. Updates are only validated if they increase the number of routes required so
+// that existing shoots exceeding the limit can still be changed otherwise.
+//
+// PARAMETERS
+// oldWorkers     []core.Worker              Worker pools before the update or nil on creation
+// workers        []core.Worker              Worker pools to validate
+// oldInfraConfig *apis.InfrastructureConfig Infrastructure config before the update or nil on creation
+// infraConfig    *apis.InfrastructureConfig Infrastructure config to validate
+// users          []SharedNetworkUser        Other shoots
+// fldPath        *field.Path                Field path of the worker pools
+func ValidateNetworkRouteLimit(oldWorkers, workers []core.Worker, oldInfraConfig, infraConfig *apis.InfrastructureConfig, users []SharedNetworkUser, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// A network is only created for the shoot if configured.
+	if nil == infraConfig || nil == infraConfig.Networks {
+		return allErrs
+	}
+
+	routes := getNetworkRoutes(workers, infraConfig)
+
+	if nil != oldInfraConfig && nil != oldInfraConfig.Networks && routes <= getNetworkRoutes(oldWorkers, oldInfraConfig) {
+		return allErrs
+	}
+
+	if nil != infraConfig.Networks.Shared {
+		for _, user := range users {
+			if nil == user.InfraConfig.Networks || nil == user.InfraConfig.Networks.Shared || user.InfraConfig.Networks.Shared.Name != infraConfig.Networks.Shared.Name {
+				continue
+			}
+
+			routes += user.NetworkRoutes
+		}
+	}
+
+	if routes > apis.NetworkRouteLimit {
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("requires %d HCloud network routes exceeding the limit of %d, reduce the worker pool maxima or enable native routing", routes, apis.NetworkRouteLimit)))
 	}
 
 	return allErrs
 }
 
 // ValidateWorkersUpdate validates updates on Workers.
-func ValidateWorkersUpdate(oldWorkers, newWorkers []core.Worker, fldPath *field.Path) field.ErrorList {
+func ValidateWorkersUpdate(oldWorkers, newWorkers []core.Worker, region string, oldInfraConfig, infraConfig *apis.InfrastructureConfig, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for i, newWorker := range newWorkers {
 		for _, oldWorker := range oldWorkers {
@@ -75,5 +183,57 @@ func ValidateWorkersUpdate(oldWorkers, newWorkers []core.Worker, fldPath *field.
 			}
 		}
 	}
+
+	allErrs = append(allErrs, validateNetworkZonesUpdate(oldWorkers, newWorkers, region, oldInfraConfig, infraConfig, fldPath)...)
+
+	return allErrs
+}
+
+// validateNetworkZonesUpdate validates that worker pools are only added in another HCloud network zone together with
+// expanding the workers CIDR if the workers span a single network zone so far. Its subnet covers the whole workers
+// CIDR, leaving no range for the subnet of another network zone.
+func validateNetworkZonesUpdate(oldWorkers, newWorkers []core.Worker, region string, oldInfraConfig, infraConfig *apis.InfrastructureConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if nil == oldInfraConfig || nil == oldInfraConfig.Networks || nil == infraConfig || nil == infraConfig.Networks {
+		return allErrs
+	}
+
+	networksPath := field.NewPath("networks")
+	oldWorkersCidr, _ := getWorkersCidr(oldInfraConfig.Networks, networksPath)
+	workersCidr, _ := getWorkersCidr(infraConfig.Networks, networksPath)
+
+	if "" == oldWorkersCidr || oldWorkersCidr != workersCidr {
+		return allErrs
+	}
+
+	oldNetworkZones := map[hcloud.NetworkZone]bool{}
+
+	if nil != oldInfraConfig.Networks.WorkersConfiguration && "" != oldInfraConfig.Networks.WorkersConfiguration.Zone {
+		oldNetworkZones[oldInfraConfig.Networks.WorkersConfiguration.Zone] = true
+	}
+
+	for _, worker := range oldWorkers {
+		for _, zone := range worker.Zones {
+			if networkZone := apis.GetNetworkZone(region, zone); "" != networkZone {
+				oldNetworkZones[networkZone] = true
+			}
+		}
+	}
+
+	if 1 != len(oldNetworkZones) {
+		return allErrs
+	}
+
+	for i, worker := range newWorkers {
+		for j, zone := range worker.Zones {
+			networkZone := apis.GetNetworkZone(region, zone)
+
+			if "" != networkZone && !oldNetworkZones[networkZone] {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Index(i).Child("zones").Index(j), fmt.Sprintf("network zone %q can only be added together with expanding the workers CIDR %q covered by the subnet of the existing network zone", networkZone, workersCidr)))
+			}
+		}
+	}
+
 	return allErrs
 }
@@ -0,0 +1,197 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation contains functions to validate controller specifications
+package validation
+
+import (
+	"github.com/gardener/gardener/pkg/apis/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+)
+
+var _ = Describe("Workers", func() {
+	Describe("#ValidateWorkers", func() {
+		fldPath := field.NewPath("spec", "provider", "workers")
+
+		It("should forbid invalid labels of the worker config", func() {
+			workers := []core.Worker{{
+				Name:    "a",
+				Minimum: 1,
+				Maximum: 2,
+				Zones:   []string{"hel1-dc2"},
+				ProviderConfig: &runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","labels":{"cost center":"4711"}}`),
+				},
+			}}
+
+			errList := ValidateWorkers(workers, &apis.InfrastructureConfig{}, fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.workers[0].providerConfig.labels"))
+		})
+
+		It("should forbid reserved primary IPs for workers egressing through a NAT gateway", func() {
+			workers := []core.Worker{{
+				Name:    "a",
+				Minimum: 1,
+				Maximum: 2,
+				Zones:   []string{"hel1-dc2"},
+				ProviderConfig: &runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","reservedPrimaryIPs":{"ipv4":true}}`),
+				},
+			}}
+
+			Expect(ValidateWorkers(workers, &apis.InfrastructureConfig{}, fldPath)).To(BeEmpty())
+
+			errList := ValidateWorkers(workers, &apis.InfrastructureConfig{NATGateway: &apis.InfrastructureConfigNATGateway{}}, fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Type).To(Equal(field.ErrorTypeForbidden))
+			Expect(errList[0].Field).To(Equal("spec.provider.workers[0].providerConfig.reservedPrimaryIPs"))
+		})
+	})
+
+	Describe("#ValidateNetworkRouteLimit", func() {
+		fldPath := field.NewPath("spec", "provider", "workers")
+
+		var infraConfig *apis.InfrastructureConfig
+
+		BeforeEach(func() {
+			infraConfig = &apis.InfrastructureConfig{Networks: &apis.InfrastructureConfigNetworks{Workers: "10.250.0.0/19"}}
+		})
+
+		It("should allow worker pool maxima within the route limit", func() {
+			workers := []core.Worker{{Name: "a", Maximum: 60}, {Name: "b", Maximum: 40}}
+
+			Expect(ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, nil, fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid worker pool maxima exceeding the route limit", func() {
+			workers := []core.Worker{{Name: "a", Maximum: 60}, {Name: "b", Maximum: 41}}
+
+			errList := ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, nil, fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Field).To(Equal("spec.provider.workers"))
+		})
+
+		It("should reserve a route for each node surging during a rolling update", func() {
+			maxSurge := intstr.FromString("10%")
+			workers := []core.Worker{{Name: "a", Maximum: 60, MaxSurge: &maxSurge}, {Name: "b", Maximum: 35}}
+
+			Expect(ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, nil, fldPath)).To(HaveLen(1))
+		})
+
+		It("should reserve a route for the NAT gateway", func() {
+			workers := []core.Worker{{Name: "a", Maximum: 100}}
+			infraConfig.NATGateway = &apis.InfrastructureConfigNATGateway{}
+
+			Expect(ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, nil, fldPath)).To(HaveLen(1))
+		})
+
+		It("should ignore shoots without a configured network", func() {
+			workers := []core.Worker{{Name: "a", Maximum: 200}}
+
+			Expect(ValidateNetworkRouteLimit(nil, workers, nil, &apis.InfrastructureConfig{}, nil, fldPath)).To(BeEmpty())
+		})
+
+		It("should count the routes of other shoots using the same shared network", func() {
+			workers := []core.Worker{{Name: "a", Maximum: 60}}
+			infraConfig.Networks.Shared = &apis.InfrastructureConfigSharedNetwork{Name: "shared"}
+
+			users := []SharedNetworkUser{
+				{Name: "garden-other/same", InfraConfig: &apis.InfrastructureConfig{Networks: &apis.InfrastructureConfigNetworks{Shared: &apis.InfrastructureConfigSharedNetwork{Name: "shared"}}}, NetworkRoutes: 41},
+				{Name: "garden-other/other", InfraConfig: &apis.InfrastructureConfig{Networks: &apis.InfrastructureConfigNetworks{Shared: &apis.InfrastructureConfigSharedNetwork{Name: "other"}}}, NetworkRoutes: 100},
+			}
+
+			errList := ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, users, fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Detail).To(ContainSubstring("requires 101 HCloud network routes"))
+
+			users[0].NetworkRoutes = 40
+
+			Expect(ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, users, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow updating shoots exceeding the route limit without requiring more routes", func() {
+			oldWorkers := []core.Worker{{Name: "a", Maximum: 150}}
+			workers := []core.Worker{{Name: "a", Maximum: 120}}
+
+			Expect(ValidateNetworkRouteLimit(oldWorkers, workers, infraConfig, infraConfig, nil, fldPath)).To(BeEmpty())
+			Expect(ValidateNetworkRouteLimit(oldWorkers, oldWorkers, infraConfig, infraConfig, nil, fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid updates increasing the routes required beyond the route limit", func() {
+			oldWorkers := []core.Worker{{Name: "a", Maximum: 100}}
+			workers := []core.Worker{{Name: "a", Maximum: 101}}
+
+			Expect(ValidateNetworkRouteLimit(oldWorkers, workers, infraConfig, infraConfig, nil, fldPath)).To(HaveLen(1))
+		})
+	})
+
+	Describe("#ValidateWorkersUpdate", func() {
+		fldPath := field.NewPath("spec", "provider", "workers")
+
+		var (
+			oldWorkers     []core.Worker
+			oldInfraConfig *apis.InfrastructureConfig
+		)
+
+		newInfraConfig := func(workersCidr string) *apis.InfrastructureConfig {
+			return &apis.InfrastructureConfig{
+				Networks: &apis.InfrastructureConfigNetworks{
+					WorkersConfiguration: &apis.InfrastructureConfigNetwork{Cidr: workersCidr},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			oldWorkers = []core.Worker{{Name: "a", Zones: []string{"fsn1"}}}
+			oldInfraConfig = newInfraConfig("10.250.0.0/19")
+		})
+
+		It("should allow adding worker pools in the same network zone", func() {
+			workers := append(oldWorkers, core.Worker{Name: "b", Zones: []string{"hel1"}})
+
+			Expect(ValidateWorkersUpdate(oldWorkers, workers, "fsn1", oldInfraConfig, newInfraConfig("10.250.0.0/19"), fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid adding a worker pool in another network zone without expanding the workers CIDR", func() {
+			workers := append(oldWorkers, core.Worker{Name: "b", Zones: []string{"ash"}})
+
+			errList := ValidateWorkersUpdate(oldWorkers, workers, "fsn1", oldInfraConfig, newInfraConfig("10.250.0.0/19"), fldPath)
+			Expect(errList).To(HaveLen(1))
+			Expect(errList[0].Type).To(Equal(field.ErrorTypeForbidden))
+			Expect(errList[0].Field).To(Equal("spec.provider.workers[1].zones[0]"))
+		})
+
+		It("should allow adding a worker pool in another network zone together with expanding the workers CIDR", func() {
+			workers := append(oldWorkers, core.Worker{Name: "b", Zones: []string{"ash"}})
+
+			Expect(ValidateWorkersUpdate(oldWorkers, workers, "fsn1", oldInfraConfig, newInfraConfig("10.250.0.0/18"), fldPath)).To(BeEmpty())
+		})
+
+		It("should allow adding a worker pool in another network zone already split into subnets", func() {
+			oldWorkers = append(oldWorkers, core.Worker{Name: "b", Zones: []string{"ash"}})
+			workers := append(oldWorkers, core.Worker{Name: "c", Zones: []string{"hil"}})
+
+			Expect(ValidateWorkersUpdate(oldWorkers, workers, "fsn1", oldInfraConfig, newInfraConfig("10.250.0.0/19"), fldPath)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,233 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectstorage provides types and functions used for HCloud Object Storage interaction
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+)
+
+const (
+	// entryExpirationRuleIDPrefix is the ID prefix of lifecycle rules expiring the objects of deleted backup entries.
+	entryExpirationRuleIDPrefix = "gardener-backupentry-"
+	// entryExpirationDays is the number of days after which objects of deleted backup entries expire.
+	entryExpirationDays = 1
+)
+
+// EnsureBucket verifies that the bucket with the given name exists.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// name   string          Bucket name
+// region string          Bucket region
+func EnsureBucket(ctx context.Context, client *minio.Client, name, region string) error {
+	exists, err := client.BucketExists(ctx, name)
+	if nil != err {
+		return err
+	} else if exists {
+		return nil
+	}
+
+	return client.MakeBucket(ctx, name, minio.MakeBucketOptions{Region: region})
+}
+
+// EnsureBucketDeleted removes all objects of the bucket with the given name and the bucket itself.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// name   string          Bucket name
+func EnsureBucketDeleted(ctx context.Context, client *minio.Client, name string) error {
+	exists, err := client.BucketExists(ctx, name)
+	if nil != err {
+		return err
+	} else if !exists {
+		return nil
+	}
+
+	err = ensureObjectsDeleted(ctx, client, name, "")
+	if nil != err {
+		return err
+	}
+
+	return client.RemoveBucket(ctx, name)
+}
+
+// EnsureEntryExpiration verifies that the objects of a deleted backup entry expire. The lifecycle rule is removed
+// again once no object of the backup entry is left.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// bucket string          Bucket name
+// entry  string          Backup entry name used as object prefix
+func EnsureEntryExpiration(ctx context.Context, client *minio.Client, bucket, entry string) error {
+	exists, err := client.BucketExists(ctx, bucket)
+	if nil != err {
+		return err
+	} else if !exists {
+		return nil
+	}
+
+	prefix := getEntryPrefix(entry)
+
+	hasObjects, err := hasObjectsWithPrefix(ctx, client, bucket, prefix)
+	if nil != err {
+		return err
+	}
+
+	config, err := getBucketLifecycle(ctx, client, bucket)
+	if nil != err {
+		return err
+	}
+
+	ruleID := entryExpirationRuleIDPrefix + entry
+	index := slices.IndexFunc(config.Rules, func(rule lifecycle.Rule) bool { return rule.ID == ruleID })
+
+	if !hasObjects {
+		if -1 == index {
+			return nil
+		}
+
+		config.Rules = slices.Delete(config.Rules, index, index+1)
+	} else if -1 == index {
+		config.Rules = append(config.Rules, lifecycle.Rule{
+			ID:         ruleID,
+			Status:     "Enabled",
+			RuleFilter: lifecycle.Filter{Prefix: prefix},
+			Expiration: lifecycle.Expiration{Days: entryExpirationDays},
+			AbortIncompleteMultipartUpload: lifecycle.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: entryExpirationDays,
+			},
+		})
+	} else {
+		return nil
+	}
+
+	return client.SetBucketLifecycle(ctx, bucket, config)
+}
+
+// EnsureExpiredEntryRulesDeleted removes the lifecycle rules of deleted backup entries without objects left.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// bucket string          Bucket name
+func EnsureExpiredEntryRulesDeleted(ctx context.Context, client *minio.Client, bucket string) error {
+	config, err := getBucketLifecycle(ctx, client, bucket)
+	if nil != err {
+		return err
+	}
+
+	var rules []lifecycle.Rule
+
+	for _, rule := range config.Rules {
+		if strings.HasPrefix(rule.ID, entryExpirationRuleIDPrefix) {
+			hasObjects, err := hasObjectsWithPrefix(ctx, client, bucket, rule.RuleFilter.Prefix)
+			if nil != err {
+				return err
+			} else if !hasObjects {
+				continue
+			}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(rules) == len(config.Rules) {
+		return nil
+	}
+
+	config.Rules = rules
+
+	return client.SetBucketLifecycle(ctx, bucket, config)
+}
+
+// ensureObjectsDeleted removes all objects with the given prefix.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// bucket string          Bucket name
+// prefix string          Object prefix
+func ensureObjectsDeleted(ctx context.Context, client *minio.Client, bucket, prefix string) error {
+	objectsCh := client.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true})
+
+	var errs []error
+
+	for removeErr := range client.RemoveObjects(ctx, bucket, objectsCh, minio.RemoveObjectsOptions{}) {
+		errs = append(errs, removeErr.Err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// getBucketLifecycle returns the lifecycle configuration of the given bucket or an empty one if not configured.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// bucket string          Bucket name
+func getBucketLifecycle(ctx context.Context, client *minio.Client, bucket string) (*lifecycle.Configuration, error) {
+	config, err := client.GetBucketLifecycle(ctx, bucket)
+	if nil != err {
+		if minio.ToErrorResponse(err).Code == "NoSuchLifecycleConfiguration" {
+			return lifecycle.NewConfiguration(), nil
+		}
+
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// getEntryPrefix returns the object prefix of the given backup entry.
+//
+// PARAMETERS
+// entry string Backup entry name
+func getEntryPrefix(entry string) string {
+	return entry + "/"
+}
+
+// hasObjectsWithPrefix returns true if at least one object with the given prefix exists.
+//
+// PARAMETERS
+// ctx    context.Context Execution context
+// client *minio.Client   S3 client
+// bucket string          Bucket name
+// prefix string          Object prefix
+func hasObjectsWithPrefix(ctx context.Context, client *minio.Client, bucket, prefix string) (bool, error) {
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for object := range client.ListObjects(listCtx, bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true, MaxKeys: 1}) {
+		if nil != object.Err {
+			return false, object.Err
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectstorage provides types and functions used for HCloud Object Storage interaction
+package objectstorage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	// minioEndpointEnv is the environment variable of the MinIO endpoint URL the bucket tests are run against.
+	minioEndpointEnv = "MINIO_ENDPOINT"
+	// minioAccessKeyIDEnv is the environment variable of the MinIO access key ID.
+	minioAccessKeyIDEnv = "MINIO_ROOT_USER"
+	// minioSecretAccessKeyEnv is the environment variable of the MinIO secret access key.
+	minioSecretAccessKeyEnv = "MINIO_ROOT_PASSWORD"
+	// minioRegion is the default region of MinIO.
+	minioRegion = "us-east-1"
+)
+
+var _ = Describe("Bucket", func() {
+	var (
+		ctx    context.Context
+		client *minio.Client
+		bucket string
+	)
+
+	putObject := func(name string) {
+		_, err := client.PutObject(ctx, bucket, name, bytes.NewReader([]byte("test")), 4, minio.PutObjectOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	removeObject := func(name string) {
+		Expect(client.RemoveObject(ctx, bucket, name, minio.RemoveObjectOptions{})).To(Succeed())
+	}
+
+	getRuleIDs := func() []string {
+		config, err := getBucketLifecycle(ctx, client, bucket)
+		Expect(err).NotTo(HaveOccurred())
+
+		var ids []string
+
+		for _, rule := range config.Rules {
+			ids = append(ids, rule.ID)
+		}
+
+		return ids
+	}
+
+	BeforeEach(func() {
+		endpoint := os.Getenv(minioEndpointEnv)
+		if "" == endpoint {
+			Skip(fmt.Sprintf("%s is not set, start MinIO with `make test-objectstorage`", minioEndpointEnv))
+		}
+
+		var err error
+
+		ctx = context.TODO()
+		client, err = NewClient(&Credentials{
+			AccessKeyID:     os.Getenv(minioAccessKeyIDEnv),
+			SecretAccessKey: os.Getenv(minioSecretAccessKeyEnv),
+			Region:          minioRegion,
+			Endpoint:        endpoint,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		bucket = fmt.Sprintf("gardener-test-%d", time.Now().UnixNano())
+		Expect(EnsureBucket(ctx, client, bucket, minioRegion)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		if nil != client {
+			Expect(EnsureBucketDeleted(ctx, client, bucket)).To(Succeed())
+		}
+	})
+
+	Describe("#EnsureBucket", func() {
+		It("should keep an existing bucket", func() {
+			putObject("entry/full")
+
+			Expect(EnsureBucket(ctx, client, bucket, minioRegion)).To(Succeed())
+
+			hasObjects, err := hasObjectsWithPrefix(ctx, client, bucket, "entry/")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hasObjects).To(BeTrue())
+		})
+	})
+
+	Describe("#EnsureBucketDeleted", func() {
+		It("should delete the bucket with all objects", func() {
+			putObject("entry/full")
+			putObject("other/incremental/1")
+
+			Expect(EnsureBucketDeleted(ctx, client, bucket)).To(Succeed())
+
+			exists, err := client.BucketExists(ctx, bucket)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			Expect(EnsureBucketDeleted(ctx, client, bucket)).To(Succeed())
+		})
+	})
+
+	Describe("#EnsureEntryExpiration", func() {
+		It("should add an expiration rule for a backup entry with objects", func() {
+			putObject("entry/full")
+
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "entry")).To(Succeed())
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "entry")).To(Succeed())
+
+			config, err := getBucketLifecycle(ctx, client, bucket)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Rules).To(HaveLen(1))
+
+			rule := config.Rules[0]
+			Expect(rule.ID).To(Equal(entryExpirationRuleIDPrefix + "entry"))
+			Expect(rule.Status).To(Equal("Enabled"))
+			Expect(rule.RuleFilter.Prefix).To(Equal("entry/"))
+			Expect(int(rule.Expiration.Days)).To(Equal(entryExpirationDays))
+		})
+
+		It("should keep the rules of other backup entries", func() {
+			putObject("entry/full")
+			putObject("other/full")
+
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "other")).To(Succeed())
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "entry")).To(Succeed())
+			Expect(getRuleIDs()).To(ConsistOf(entryExpirationRuleIDPrefix+"other", entryExpirationRuleIDPrefix+"entry"))
+		})
+
+		It("should remove the rule once no object of the backup entry is left", func() {
+			putObject("entry/full")
+			putObject("other/full")
+
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "other")).To(Succeed())
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "entry")).To(Succeed())
+
+			removeObject("entry/full")
+
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "entry")).To(Succeed())
+			Expect(getRuleIDs()).To(ConsistOf(entryExpirationRuleIDPrefix + "other"))
+		})
+
+		It("should ignore a missing bucket", func() {
+			Expect(EnsureEntryExpiration(ctx, client, bucket+"-missing", "entry")).To(Succeed())
+		})
+	})
+
+	Describe("#EnsureExpiredEntryRulesDeleted", func() {
+		It("should only remove the rules of backup entries without objects", func() {
+			putObject("entry/full")
+			putObject("other/full")
+
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "other")).To(Succeed())
+			Expect(EnsureEntryExpiration(ctx, client, bucket, "entry")).To(Succeed())
+
+			removeObject("other/full")
+
+			Expect(EnsureExpiredEntryRulesDeleted(ctx, client, bucket)).To(Succeed())
+			Expect(getRuleIDs()).To(ConsistOf(entryExpirationRuleIDPrefix + "entry"))
+		})
+	})
+})
@@ -0,0 +1,128 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package objectstorage provides types and functions used for HCloud Object Storage interaction
+package objectstorage
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	miniocredentials "github.com/minio/minio-go/v7/pkg/credentials"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AccessKeyID is the key of the Object Storage access key ID in backup secrets.
+	AccessKeyID = "accessKeyID"
+	// SecretAccessKey is the key of the Object Storage secret access key in backup secrets.
+	SecretAccessKey = "secretAccessKey"
+	// Region is the key of the Object Storage region in backup secrets.
+	Region = "region"
+	// Endpoint is the key of the Object Storage endpoint URL in backup secrets.
+	Endpoint = "endpoint"
+	// ForcePathStyle is the key of the etcd-backup-restore setting enforcing path style bucket access.
+	ForcePathStyle = "s3ForcePathStyle"
+	// defaultEndpointTemplate is the template of the HCloud Object Storage endpoint URL for a region.
+	defaultEndpointTemplate = "https://%s.your-objectstorage.com"
+)
+
+// Credentials contains the necessary HCloud Object Storage credential information.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+	Endpoint        string
+}
+
+// ExtractCredentials generates a credentials object for a given backup secret. The endpoint defaults to the HCloud
+// Object Storage endpoint of the region and the region defaults to the one given.
+//
+// PARAMETERS
+// secret *corev1.Secret Secret to extract credentials from
+// region string         Region used if not defined in the secret
+func ExtractCredentials(secret *corev1.Secret, region string) (*Credentials, error) {
+	if secret.Data == nil {
+		return nil, fmt.Errorf("secret does not contain any data")
+	}
+
+	credentials := &Credentials{
+		AccessKeyID:     string(secret.Data[AccessKeyID]),
+		SecretAccessKey: string(secret.Data[SecretAccessKey]),
+		Region:          string(secret.Data[Region]),
+		Endpoint:        string(secret.Data[Endpoint]),
+	}
+
+	if "" == credentials.AccessKeyID {
+		return nil, fmt.Errorf("missing %q field in secret", AccessKeyID)
+	}
+
+	if "" == credentials.SecretAccessKey {
+		return nil, fmt.Errorf("missing %q field in secret", SecretAccessKey)
+	}
+
+	if "" == credentials.Region {
+		credentials.Region = region
+	}
+
+	if "" == credentials.Endpoint {
+		if "" == credentials.Region {
+			return nil, fmt.Errorf("missing %q field in secret", Region)
+		}
+
+		credentials.Endpoint = fmt.Sprintf(defaultEndpointTemplate, credentials.Region)
+	} else if !strings.Contains(credentials.Endpoint, "://") {
+		credentials.Endpoint = "https://" + credentials.Endpoint
+	}
+
+	return credentials, nil
+}
+
+// SecretData returns the secret data used by etcd-backup-restore to access the Object Storage.
+func (c *Credentials) SecretData() map[string][]byte {
+	return map[string][]byte{
+		AccessKeyID:     []byte(c.AccessKeyID),
+		SecretAccessKey: []byte(c.SecretAccessKey),
+		Region:          []byte(c.Region),
+		Endpoint:        []byte(c.Endpoint),
+		ForcePathStyle:  []byte(strconv.FormatBool(true)),
+	}
+}
+
+// NewClient returns a new S3 client for the Object Storage endpoint of the credentials given.
+//
+// PARAMETERS
+// credentials *Credentials Object Storage credentials
+func NewClient(credentials *Credentials) (*minio.Client, error) {
+	endpoint, err := url.Parse(credentials.Endpoint)
+	if nil != err {
+		return nil, err
+	} else if "" == endpoint.Host {
+		return nil, fmt.Errorf("Object Storage endpoint %q is invalid", credentials.Endpoint)
+	}
+
+	opts := &minio.Options{
+		Creds:        miniocredentials.NewStaticV4(credentials.AccessKeyID, credentials.SecretAccessKey, ""),
+		Secure:       endpoint.Scheme != "http",
+		Region:       credentials.Region,
+		BucketLookup: minio.BucketLookupPath,
+	}
+
+	return minio.New(endpoint.Host, opts)
+}
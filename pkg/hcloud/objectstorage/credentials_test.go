@@ -0,0 +1,112 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package objectstorage provides types and functions used for HCloud Object Storage interaction
+package objectstorage
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("Credentials", func() {
+	Describe("#ExtractCredentials", func() {
+		It("should default region and endpoint", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{
+				AccessKeyID:     []byte("id"),
+				SecretAccessKey: []byte("secret"),
+			}}
+
+			credentials, err := ExtractCredentials(secret, "fsn1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials).To(Equal(&Credentials{
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+				Region:          "fsn1",
+				Endpoint:        "https://fsn1.your-objectstorage.com",
+			}))
+		})
+
+		It("should prefer region and endpoint of the secret", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{
+				AccessKeyID:     []byte("id"),
+				SecretAccessKey: []byte("secret"),
+				Region:          []byte("nbg1"),
+				Endpoint:        []byte("http://localhost:9000"),
+			}}
+
+			credentials, err := ExtractCredentials(secret, "fsn1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials.Region).To(Equal("nbg1"))
+			Expect(credentials.Endpoint).To(Equal("http://localhost:9000"))
+		})
+
+		It("should default the endpoint scheme to HTTPS", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{
+				AccessKeyID:     []byte("id"),
+				SecretAccessKey: []byte("secret"),
+				Endpoint:        []byte("hel1.your-objectstorage.com"),
+			}}
+
+			credentials, err := ExtractCredentials(secret, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials.Endpoint).To(Equal("https://hel1.your-objectstorage.com"))
+		})
+
+		It("should fail without region and endpoint", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{
+				AccessKeyID:     []byte("id"),
+				SecretAccessKey: []byte("secret"),
+			}}
+
+			_, err := ExtractCredentials(secret, "")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should fail without access keys", func() {
+			secret := &corev1.Secret{Data: map[string][]byte{
+				AccessKeyID: []byte("id"),
+			}}
+
+			_, err := ExtractCredentials(secret, "fsn1")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#NewClient", func() {
+		It("should use plain HTTP for a local endpoint", func() {
+			client, err := NewClient(&Credentials{
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+				Region:          "fsn1",
+				Endpoint:        "http://localhost:9000",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.EndpointURL().String()).To(Equal("http://localhost:9000"))
+		})
+
+		It("should use HTTPS for the HCloud endpoint", func() {
+			client, err := NewClient(&Credentials{
+				AccessKeyID:     "id",
+				SecretAccessKey: "secret",
+				Region:          "fsn1",
+				Endpoint:        "https://fsn1.your-objectstorage.com",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.EndpointURL().String()).To(Equal("https://fsn1.your-objectstorage.com"))
+		})
+	})
+})
@@ -89,4 +89,7 @@ const (
 var (
 	// UsernamePrefix is a constant for the username prefix of components deployed by OpenStack.
 	UsernamePrefix = extensionsv1alpha1.SchemeGroupVersion.Group + ":" + Name + ":"
+	// ExtendedMachineClassFields enables machine class fields requiring a machine-controller-manager-provider-hcloud
+	// image newer than the one of charts/images.yaml. It is set from the controller configuration.
+	ExtendedMachineClassFields = false
 )
@@ -46,6 +46,50 @@ import (
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 )
 
+const (
+	// defaultRouteScriptPath is the path of the script configuring the default route of worker nodes without public IPs.
+	defaultRouteScriptPath = "/opt/bin/hcloud-default-route.sh"
+	// defaultRouteScript adds the default route via the gateway of the HCloud network to worker nodes without public
+	// IPs. Their egress traffic is routed to the NAT gateway by the default route of the HCloud network. The HCloud
+	// recursive DNS servers are configured as no DNS servers are announced without a public network.
+	defaultRouteScript = `#!/bin/sh
+if [ -n "$(ip -4 route show default)" ]; then
+  exit 0
+fi
+
+ROUTE="$(ip -4 route show | grep ' via ' | head -n 1)"
+
+if [ -z "${ROUTE}" ]; then
+  echo "HCloud network gateway not found" >&2
+  exit 1
+fi
+
+GATEWAY="$(echo "${ROUTE}" | awk '{ print $3 }')"
+INTERFACE="$(echo "${ROUTE}" | awk '{ print $5 }')"
+
+ip route replace default via "${GATEWAY}" dev "${INTERFACE}"
+
+if command -v resolvectl > /dev/null; then
+  resolvectl dns "${INTERFACE}" 185.12.64.1 185.12.64.2
+fi
+`
+	// defaultRouteUnitContent is the systemd unit running the default route script before the kubelet is started.
+	defaultRouteUnitContent = `[Unit]
+Description=Configure the default route via the HCloud network gateway
+Wants=network-online.target
+After=network-online.target
+Before=kubelet.service
+[Install]
+WantedBy=multi-user.target
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+Restart=on-failure
+RestartSec=5
+ExecStart=` + defaultRouteScriptPath + `
+`
+)
+
 // NewEnsurer creates a new controlplane ensurer.
 func NewEnsurer(mgr manager.Manager, logger logr.Logger) genericmutator.Ensurer {
 	return &ensurer{
@@ -228,9 +272,46 @@ func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx gcontext.Garde
 		addMergeDockerJSONFile(new, cloudProfileConfig.DockerDaemonOptions.InsecureRegistries)
 	}
 
+	natGatewayEnabled, err := isNATGatewayEnabled(ctx, gctx)
+	if err != nil {
+		return err
+	}
+
+	if natGatewayEnabled {
+		addDefaultRouteFile(new)
+	}
+
 	return nil
 }
 
+// isNATGatewayEnabled returns true if the worker nodes of the shoot egress through a NAT gateway without public IPs.
+func isNATGatewayEnabled(ctx context.Context, gctx gcontext.GardenContext) (bool, error) {
+	infraConfig, err := transcoder.DecodeInfrastructureConfigFromGardenContext(ctx, gctx)
+	if _, ok := err.(*transcoder.MissingProviderConfig); ok {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return infraConfig.NATGateway != nil, nil
+}
+
+// addDefaultRouteFile adds the script configuring the default route of worker nodes without public IPs.
+func addDefaultRouteFile(new *[]extensionsv1alpha1.File) {
+	var permissions int32 = 0755
+
+	appendUniqueFile(new, extensionsv1alpha1.File{
+		Path:        defaultRouteScriptPath,
+		Permissions: &permissions,
+		Content: extensionsv1alpha1.FileContent{
+			Inline: &extensionsv1alpha1.FileContentInline{
+				Encoding: "",
+				Data:     defaultRouteScript,
+			},
+		},
+	})
+}
+
 func addDockerHTTPProxyFile(new *[]extensionsv1alpha1.File, httpProxyConf string) {
 	var (
 		permissions int32 = 0644
@@ -307,6 +388,21 @@ ExecStart=/opt/bin/merge-docker-json.sh
 			Content: &customUnitContent,
 		})
 	}
+
+	natGatewayEnabled, err := isNATGatewayEnabled(ctx, gctx)
+	if err != nil {
+		return err
+	}
+
+	if natGatewayEnabled {
+		extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
+			Name:    "hcloud-default-route.service",
+			Enable:  &trueVar,
+			Command: &command,
+			Content: ptr.To(defaultRouteUnitContent),
+		})
+	}
+
 	return nil
 }
 
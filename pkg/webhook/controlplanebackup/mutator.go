@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplanebackup contains functions used to provide the /backup control plane webhook
+package controlplanebackup
+
+import (
+	"context"
+	"fmt"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+)
+
+const (
+	// storageProviderS3 is the etcd-druid storage provider of S3 compatible object stores.
+	storageProviderS3 druidv1alpha1.StorageProvider = "S3"
+)
+
+// mutator sets the storage provider of etcd backups stored in the HCloud Object Storage.
+type mutator struct {
+	logger logr.Logger
+}
+
+// NewMutator creates a new mutator setting the S3 storage provider for etcd backups of the HCloud provider.
+//
+// PARAMETERS
+// logger logr.Logger Logger instance
+func NewMutator(logger logr.Logger) extensionswebhook.Mutator {
+	return &mutator{logger: logger}
+}
+
+// Mutate replaces the HCloud backup provider type of Etcd resources with the S3 storage provider. etcd-druid does
+// not know the HCloud provider type while the HCloud Object Storage is S3 compatible.
+//
+// PARAMETERS
+// ctx context.Context Execution context
+// new client.Object   Object to be mutated
+// old client.Object   Object currently stored
+func (m *mutator) Mutate(ctx context.Context, new, old client.Object) error {
+	if nil != new.GetDeletionTimestamp() {
+		return nil
+	}
+
+	etcd, ok := new.(*druidv1alpha1.Etcd)
+	if !ok {
+		return fmt.Errorf("Wrong object type %T", new)
+	}
+
+	store := etcd.Spec.Backup.Store
+	if nil == store || nil == store.Provider || druidv1alpha1.StorageProvider(hcloud.Type) != *store.Provider {
+		return nil
+	}
+
+	extensionswebhook.LogMutation(m.logger, etcd.Kind, etcd.Namespace, etcd.Name)
+
+	provider := storageProviderS3
+	store.Provider = &provider
+
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplanebackup contains functions used to provide the /backup control plane webhook
+package controlplanebackup
+
+import (
+	"context"
+
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+)
+
+var _ = Describe("Mutator", func() {
+	var etcd *druidv1alpha1.Etcd
+
+	BeforeEach(func() {
+		etcd = &druidv1alpha1.Etcd{}
+		etcd.Spec.Backup.Store = &druidv1alpha1.StoreSpec{Provider: ptr.To(druidv1alpha1.StorageProvider(hcloud.Type))}
+	})
+
+	It("should set the S3 storage provider for HCloud backups", func() {
+		Expect(NewMutator(log.Log).Mutate(context.TODO(), etcd, nil)).To(Succeed())
+		Expect(*etcd.Spec.Backup.Store.Provider).To(Equal(druidv1alpha1.StorageProvider("S3")))
+	})
+
+	It("should keep other storage providers", func() {
+		etcd.Spec.Backup.Store.Provider = ptr.To(druidv1alpha1.StorageProvider("gcp"))
+
+		Expect(NewMutator(log.Log).Mutate(context.TODO(), etcd, nil)).To(Succeed())
+		Expect(*etcd.Spec.Backup.Store.Provider).To(Equal(druidv1alpha1.StorageProvider("gcp")))
+	})
+
+	It("should ignore Etcd resources without backups", func() {
+		etcd.Spec.Backup.Store = nil
+
+		Expect(NewMutator(log.Log).Mutate(context.TODO(), etcd, nil)).To(Succeed())
+		Expect(etcd.Spec.Backup.Store).To(BeNil())
+	})
+})
@@ -0,0 +1,48 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplanebackup contains functions used to provide the /backup control plane webhook
+package controlplanebackup
+
+import (
+	druidv1alpha1 "github.com/gardener/etcd-druid/api/v1alpha1"
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+)
+
+var (
+	logger = log.Log.WithName("hcloud-controlplane-backup-webhook")
+)
+
+// AddToManager creates a webhook and adds it to the manager.
+//
+// PARAMETERS
+// mgr manager.Manager Webhook control plane controller manager instance
+func AddToManager(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
+	logger.Info("Adding webhook to manager")
+	return controlplane.New(mgr, controlplane.Args{
+		Kind:     controlplane.KindBackup,
+		Provider: hcloud.Type,
+		Types: []extensionswebhook.Type{
+			{Obj: &druidv1alpha1.Etcd{}},
+		},
+		Mutator: NewMutator(logger),
+	})
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/gardener/gardener/pkg/apis/core"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 )
 
 // NewCloudProfileValidator returns a new instance of a cloud profile validator.
@@ -43,11 +45,25 @@ func (cp *cloudProfile) Validate(_ context.Context, new, _ client.Object) error
 	}
 
 	for _, region := range cloudProfile.Spec.Regions {
-		if len(region.Zones) > 1 {
-			return fmt.Errorf("This version of the hcloud extension does not support multiple zones per region. Consider implementing this feature.")
+		isNetworkZoneRegion := apis.IsNetworkZoneRegion(region.Name)
+
+		// Regions of a single zone may use any zone name as the zone is passed through as is.
+		if !isNetworkZoneRegion && 1 == len(region.Zones) {
+			continue
+		}
+
+		for _, zone := range region.Zones {
+			networkZone := apis.GetNetworkZoneFromLocation(apis.GetLocationFromZone(zone.Name))
+
+			if "" == networkZone {
+				return fmt.Errorf("Zone %q of region %q is not a known HCloud location or datacenter", zone.Name, region.Name)
+			}
+
+			if isNetworkZoneRegion && string(networkZone) != region.Name {
+				return fmt.Errorf("Zone %q of region %q is not a location or datacenter of the HCloud network zone", zone.Name, region.Name)
+			}
 		}
 	}
 
 	return nil
-
 }
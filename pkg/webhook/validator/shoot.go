@@ -25,10 +25,13 @@ import (
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	"github.com/gardener/gardener/pkg/apis/core"
 	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	securityv1alpha1 "github.com/gardener/gardener/pkg/apis/security/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/transcoder"
 	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/validation"
@@ -69,7 +72,7 @@ func (s *shoot) Validate(ctx context.Context, new, old client.Object) error {
 	return s.validateShootCreation(ctx, shoot)
 }
 
-func (s *shoot) validateShoot(_ context.Context, shoot *core.Shoot) error {
+func (s *shoot) validateShoot(ctx context.Context, oldShoot, shoot *core.Shoot) error {
 	// Network validation
 	if errList := validation.ValidateShootNetworking(*shoot.Spec.Networking); len(errList) != 0 {
 		return errList.ToAggregate()
@@ -83,25 +86,187 @@ func (s *shoot) validateShoot(_ context.Context, shoot *core.Shoot) error {
 		return field.InternalError(fldPath.Child("infrastructureConfig"), err)
 	}
 
-	if errList := validation.ValidateInfrastructureConfig(infraConfig, shoot.Spec.Networking.Nodes, shoot.Spec.Networking.Pods, shoot.Spec.Networking.Services); len(errList) != 0 {
+	if errList := validation.ValidateInfrastructureConfig(infraConfig, shoot.Spec.Networking.Nodes, shoot.Spec.Networking.Pods, shoot.Spec.Networking.Services, fldPath.Child("infrastructureConfig")); len(errList) != 0 {
 		return errList.ToAggregate()
 	}
 
-	// ControlPlaneConfig
-	if shoot.Spec.Provider.ControlPlaneConfig != nil {
-		if _, err := transcoder.DecodeControlPlaneConfigWithDecoder(s.decoder, shoot.Spec.Provider.ControlPlaneConfig); err != nil {
+	if errList := validation.ValidateInfrastructureConfigIPFamilies(infraConfig, shoot.Spec.Networking.IPFamilies, fldPath.Child("infrastructureConfig")); len(errList) != 0 {
+		return errList.ToAggregate()
+	}
+
+	var users []validation.SharedNetworkUser
+
+	if nil != infraConfig.Networks && nil != infraConfig.Networks.Shared {
+		users, err = s.getSharedNetworkUsers(ctx, shoot)
+		if err != nil {
 			return err
 		}
+
+		if errList := validation.ValidateSharedNetworkUsers(infraConfig, shoot.Spec.Networking.Pods, users, fldPath.Child("infrastructureConfig")); len(errList) != 0 {
+			return errList.ToAggregate()
+		}
+	}
+
+	// ControlPlaneConfig
+	cpConfig, err := s.decodeControlPlaneConfig(shoot)
+	if err != nil {
+		return err
 	}
 
 	// WorkerConfig and Shoot workers
-	if errList := validation.ValidateWorkers(shoot.Spec.Provider.Workers, fldPath.Child("workers")); len(errList) != 0 {
+	if errList := validation.ValidateWorkers(shoot.Spec.Provider.Workers, infraConfig, fldPath.Child("workers")); len(errList) != 0 {
 		return errList.ToAggregate()
 	}
 
+	if apis.IsNetworkRoutingEnabled(cpConfig) {
+		var (
+			oldWorkers     []core.Worker
+			oldInfraConfig *apis.InfrastructureConfig
+		)
+
+		// Routes of the old shoot only need to be compared if they have been required before.
+		if nil != oldShoot {
+			oldCpConfig, err := s.decodeControlPlaneConfig(oldShoot)
+			if err != nil {
+				return err
+			}
+
+			if apis.IsNetworkRoutingEnabled(oldCpConfig) {
+				oldInfraConfig, err = transcoder.DecodeInfrastructureConfig(oldShoot.Spec.Provider.InfrastructureConfig)
+				if err != nil {
+					return field.InternalError(fldPath.Child("infrastructureConfig"), err)
+				}
+
+				oldWorkers = oldShoot.Spec.Provider.Workers
+			}
+		}
+
+		if errList := validation.ValidateNetworkRouteLimit(oldWorkers, shoot.Spec.Provider.Workers, oldInfraConfig, infraConfig, users, fldPath.Child("workers")); len(errList) != 0 {
+			return errList.ToAggregate()
+		}
+	}
+
 	return nil
 }
 
+// decodeControlPlaneConfig returns the control plane config of the shoot or nil if not set.
+func (s *shoot) decodeControlPlaneConfig(shoot *core.Shoot) (*apis.ControlPlaneConfig, error) {
+	if shoot.Spec.Provider.ControlPlaneConfig == nil {
+		return nil, nil
+	}
+
+	return transcoder.DecodeControlPlaneConfigWithDecoder(s.decoder, shoot.Spec.Provider.ControlPlaneConfig)
+}
+
+// getSharedNetworkUsers returns all other HCloud shoots of the garden which may use a shared network. Shared networks
+// are looked up by name in the HCloud project of the shoot, so only shoots using credentials with the same token are
+// returned.
+func (s *shoot) getSharedNetworkUsers(ctx context.Context, shoot *core.Shoot) ([]validation.SharedNetworkUser, error) {
+	token, err := s.getCredentialsToken(ctx, shoot.Namespace, shoot.Spec.SecretBindingName, shoot.Spec.CredentialsBindingName)
+	if err != nil {
+		return nil, err
+	}
+
+	shootList := &gardencorev1beta1.ShootList{}
+	if err := s.client.List(ctx, shootList); err != nil {
+		return nil, err
+	}
+
+	var users []validation.SharedNetworkUser
+
+	for _, otherShoot := range shootList.Items {
+		if otherShoot.Namespace == shoot.Namespace && otherShoot.Name == shoot.Name {
+			continue
+		}
+
+		if hcloud.Type != otherShoot.Spec.Provider.Type || nil == otherShoot.Spec.Provider.InfrastructureConfig {
+			continue
+		}
+
+		// Shoots of other HCloud projects neither share the network nor its route limit.
+		otherToken, err := s.getCredentialsToken(ctx, otherShoot.Namespace, otherShoot.Spec.SecretBindingName, otherShoot.Spec.CredentialsBindingName)
+		if err != nil || otherToken != token {
+			continue
+		}
+
+		infraConfig, err := transcoder.DecodeInfrastructureConfig(otherShoot.Spec.Provider.InfrastructureConfig)
+		if err != nil {
+			continue
+		}
+
+		user := validation.SharedNetworkUser{
+			Name:        otherShoot.Namespace + "/" + otherShoot.Name,
+			InfraConfig: infraConfig,
+		}
+
+		if nil != otherShoot.Spec.Networking {
+			user.Pods = otherShoot.Spec.Networking.Pods
+		}
+
+		var cpConfig *apis.ControlPlaneConfig
+
+		if nil != otherShoot.Spec.Provider.ControlPlaneConfig {
+			cpConfig, err = transcoder.DecodeControlPlaneConfigWithDecoder(s.decoder, otherShoot.Spec.Provider.ControlPlaneConfig)
+			if err != nil {
+				continue
+			}
+		}
+
+		if apis.IsNetworkRoutingEnabled(cpConfig) {
+			for _, worker := range otherShoot.Spec.Provider.Workers {
+				user.NetworkRoutes += validation.GetWorkerNetworkRoutes(worker.Maximum, worker.MaxSurge)
+			}
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// getCredentialsToken returns the HCloud token of the secret referenced by the secret or credentials binding given.
+func (s *shoot) getCredentialsToken(ctx context.Context, namespace string, secretBindingName, credentialsBindingName *string) (string, error) {
+	var secretKey client.ObjectKey
+
+	if nil != secretBindingName {
+		secretBinding := &gardencorev1beta1.SecretBinding{}
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: *secretBindingName}, secretBinding); err != nil {
+			return "", err
+		}
+
+		secretKey = client.ObjectKey{Namespace: secretBinding.SecretRef.Namespace, Name: secretBinding.SecretRef.Name}
+	} else if nil != credentialsBindingName {
+		credentialsBinding := &securityv1alpha1.CredentialsBinding{}
+		if err := s.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: *credentialsBindingName}, credentialsBinding); err != nil {
+			return "", err
+		}
+
+		if "Secret" != credentialsBinding.CredentialsRef.Kind {
+			return "", fmt.Errorf("credentials binding %s/%s does not reference a secret", namespace, *credentialsBindingName)
+		}
+
+		secretKey = client.ObjectKey{Namespace: credentialsBinding.CredentialsRef.Namespace, Name: credentialsBinding.CredentialsRef.Name}
+	} else {
+		return "", fmt.Errorf("shoot in namespace %s does not reference any credentials", namespace)
+	}
+
+	if "" == secretKey.Namespace {
+		secretKey.Namespace = namespace
+	}
+
+	secret := &corev1.Secret{}
+	if err := s.client.Get(ctx, secretKey, secret); err != nil {
+		return "", err
+	}
+
+	credentials, err := hcloud.ExtractCredentials(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return string(credentials.CCM().Token), nil
+}
+
 func (s *shoot) validateShootUpdate(ctx context.Context, oldShoot, shoot *core.Shoot) error {
 	var (
 		fldPath            = field.NewPath("spec", "provider")
@@ -123,7 +288,7 @@ func (s *shoot) validateShootUpdate(ctx context.Context, oldShoot, shoot *core.S
 	}
 
 	if !reflect.DeepEqual(oldInfraConfig, infraConfig) {
-		if errList := validation.ValidateInfrastructureConfigUpdate(oldInfraConfig, infraConfig); len(errList) != 0 {
+		if errList := validation.ValidateInfrastructureConfigUpdate(oldInfraConfig, infraConfig, infraConfigFldPath); len(errList) != 0 {
 			return errList.ToAggregate()
 		}
 	}
@@ -132,11 +297,11 @@ func (s *shoot) validateShootUpdate(ctx context.Context, oldShoot, shoot *core.S
 		return err
 	}
 
-	if errList := validation.ValidateWorkersUpdate(oldShoot.Spec.Provider.Workers, shoot.Spec.Provider.Workers, fldPath.Child("workers")); len(errList) != 0 {
+	if errList := validation.ValidateWorkersUpdate(oldShoot.Spec.Provider.Workers, shoot.Spec.Provider.Workers, shoot.Spec.Region, oldInfraConfig, infraConfig, fldPath.Child("workers")); len(errList) != 0 {
 		return errList.ToAggregate()
 	}
 
-	return s.validateShoot(ctx, shoot)
+	return s.validateShoot(ctx, oldShoot, shoot)
 }
 
 func (s *shoot) validateShootCreation(ctx context.Context, shoot *core.Shoot) error {
@@ -151,7 +316,7 @@ func (s *shoot) validateShootCreation(ctx context.Context, shoot *core.Shoot) er
 		return err
 	}
 
-	return s.validateShoot(ctx, shoot)
+	return s.validateShoot(ctx, nil, shoot)
 }
 
 func (s *shoot) validateAgainstCloudProfile(ctx context.Context, shoot *core.Shoot, oldInfraConfig, infraConfig *apis.InfrastructureConfig, fldPath *field.Path) error {
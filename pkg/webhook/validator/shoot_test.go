@@ -0,0 +1,160 @@
+/*
+Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validator
+
+import (
+	"context"
+
+	"github.com/gardener/gardener/pkg/apis/core"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	securityv1alpha1 "github.com/gardener/gardener/pkg/apis/security/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis"
+	"github.com/23technologies/gardener-extension-provider-hcloud/pkg/hcloud/apis/validation"
+)
+
+// sharedInfraConfig is an infrastructure config joining the shared network "shared".
+const sharedInfraConfig = `{"apiVersion":"hcloud.provider.extensions.gardener.cloud/v1alpha1","kind":"InfrastructureConfig","networks":{"workers":"10.251.0.0/19","shared":{"name":"shared","cidr":"10.0.0.0/8"}}}`
+
+var _ = Describe("Shoot", func() {
+	var (
+		ctx       = context.TODO()
+		validator *shoot
+	)
+
+	newSecret := func(namespace, name, token string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{hcloud.HcloudToken: []byte(token)},
+		}
+	}
+
+	newSecretBinding := func(namespace, name, secretName string) *gardencorev1beta1.SecretBinding {
+		return &gardencorev1beta1.SecretBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			SecretRef:  corev1.SecretReference{Name: secretName},
+		}
+	}
+
+	newSharedNetworkShoot := func(namespace, name string, maximum int32) *gardencorev1beta1.Shoot {
+		return &gardencorev1beta1.Shoot{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: gardencorev1beta1.ShootSpec{
+				SecretBindingName: ptr.To("binding"),
+				Provider: gardencorev1beta1.Provider{
+					Type:                 hcloud.Type,
+					InfrastructureConfig: &runtime.RawExtension{Raw: []byte(sharedInfraConfig)},
+					Workers:              []gardencorev1beta1.Worker{{Name: "pool", Maximum: maximum}},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(gardencorev1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(securityv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		credentialsBinding := &securityv1alpha1.CredentialsBinding{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "garden-c", Name: "binding"},
+			CredentialsRef: corev1.ObjectReference{Kind: "Secret", Namespace: "garden-a", Name: "secret"},
+		}
+
+		sameCredentialsShoot := newSharedNetworkShoot("garden-c", "same-credentials", 10)
+		sameCredentialsShoot.Spec.SecretBindingName = nil
+		sameCredentialsShoot.Spec.CredentialsBindingName = ptr.To("binding")
+
+		objects := []client.Object{
+			newSecret("garden-a", "secret", "token-a"),
+			newSecret("garden-b", "secret", "token-b"),
+			newSecretBinding("garden-a", "binding", "secret"),
+			newSecretBinding("garden-b", "binding", "secret"),
+			credentialsBinding,
+			newSharedNetworkShoot("garden-a", "same-project", 40),
+			newSharedNetworkShoot("garden-b", "other-project", 60),
+			sameCredentialsShoot,
+		}
+
+		validator = &shoot{client: fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	})
+
+	Describe("#getSharedNetworkUsers", func() {
+		It("should only return shoots using credentials of the same HCloud project", func() {
+			shoot := &core.Shoot{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-a", Name: "shoot"},
+				Spec:       core.ShootSpec{SecretBindingName: ptr.To("binding")},
+			}
+
+			users, err := validator.getSharedNetworkUsers(ctx, shoot)
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+
+			Expect(names).To(ConsistOf("garden-a/same-project", "garden-c/same-credentials"))
+		})
+
+		It("should fail if the credentials of the shoot cannot be resolved", func() {
+			shoot := &core.Shoot{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-a", Name: "shoot"},
+				Spec:       core.ShootSpec{SecretBindingName: ptr.To("missing")},
+			}
+
+			_, err := validator.getSharedNetworkUsers(ctx, shoot)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#ValidateNetworkRouteLimit", func() {
+		It("should ignore the routes of shoots using the same shared network name in another HCloud project", func() {
+			shoot := &core.Shoot{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "garden-a", Name: "shoot"},
+				Spec:       core.ShootSpec{SecretBindingName: ptr.To("binding")},
+			}
+			infraConfig := &apis.InfrastructureConfig{
+				Networks: &apis.InfrastructureConfigNetworks{
+					Workers: "10.250.0.0/19",
+					Shared:  &apis.InfrastructureConfigSharedNetwork{Name: "shared", Cidr: "10.0.0.0/8"},
+				},
+			}
+			fldPath := field.NewPath("spec", "provider", "workers")
+
+			users, err := validator.getSharedNetworkUsers(ctx, shoot)
+			Expect(err).NotTo(HaveOccurred())
+
+			// 50 routes of the shoot and 50 of the other shoots of the same project exactly reach the limit.
+			workers := []core.Worker{{Name: "pool", Maximum: 50}}
+			Expect(validation.ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, users, fldPath)).To(BeEmpty())
+
+			workers[0].Maximum = 51
+			Expect(validation.ValidateNetworkRouteLimit(nil, workers, nil, infraConfig, users, fldPath)).To(HaveLen(1))
+		})
+	})
+})